@@ -0,0 +1,133 @@
+// Package alertingimport imports the alerting rules of other systems into Grafana through its HTTP API.
+package alertingimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	errMissingRuleFile   = errors.New("missing path to the rule file")
+	errMissingFolder     = errors.New("missing --folder flag")
+	errMissingDatasource = errors.New("missing --datasource-uid flag")
+)
+
+// ImportPrometheusRules imports the alerting rules of a Prometheus rule file in a folder of a Grafana server
+// through the HTTP API, and prints the changes to the rule groups.
+func ImportPrometheusRules(c utils.CommandLine) error {
+	path := c.Args().First()
+	if path == "" {
+		return errMissingRuleFile
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errMissingFolder
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errMissingDatasource
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is the argument of the command.
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the rule file: %w", err)
+	}
+
+	body, err := json.Marshal(apimodels.PrometheusRulesImport{
+		DatasourceUID: datasourceUID,
+		Groups:        string(content),
+		DryRun:        c.Bool("dry-run"),
+	})
+	if err != nil {
+		return err
+	}
+
+	u := strings.TrimSuffix(c.String("url"), "/") + "/api/ruler/grafana/api/v1/import/prometheus/" + url.PathEscape(folder)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user := c.String("user"); user != "" {
+		req.SetBasicAuth(user, c.String("password"))
+	}
+
+	res, err := services.HttpClientNoTimeout.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the rules to Grafana: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusAccepted {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(resBody, &msg); err == nil && msg.Message != "" {
+			return fmt.Errorf("failed to import the rules: %s: %s", res.Status, msg.Message)
+		}
+		return fmt.Errorf("failed to import the rules: %s", res.Status)
+	}
+
+	var result apimodels.PrometheusRulesImportResult
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return fmt.Errorf("failed to read the response of Grafana: %w", err)
+	}
+	logger.Info(formatRulesImportResult(result))
+	return nil
+}
+
+// formatRulesImportResult formats the changes of an import as a diff.
+func formatRulesImportResult(result apimodels.PrometheusRulesImportResult) string {
+	b := strings.Builder{}
+	if result.DryRun {
+		b.WriteString(color.YellowString("Dry run, no changes are applied.\n"))
+	}
+	for _, group := range result.Groups {
+		b.WriteString(fmt.Sprintf("\nRule group %s:\n", color.New(color.Bold).Sprint(group.Name)))
+		changes := len(group.Added) + len(group.Updated) + len(group.Deleted)
+		if changes == 0 {
+			b.WriteString("  no changes\n")
+		}
+		for _, title := range group.Added {
+			b.WriteString(color.GreenString("  + %s\n", title))
+		}
+		for _, rule := range group.Updated {
+			b.WriteString(color.YellowString("  ~ %s\n", rule.Title))
+			for _, diff := range rule.Diff {
+				b.WriteString("      " + strings.ReplaceAll(diff, "\n", "\n      ") + "\n")
+			}
+		}
+		for _, title := range group.Deleted {
+			b.WriteString(color.RedString("  - %s\n", title))
+		}
+		for _, name := range group.Skipped {
+			b.WriteString(fmt.Sprintf("  skipped recording rule %s\n", name))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package alertingimport
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestImportPrometheusRulesCommand(t *testing.T) {
+	ruleFile := filepath.Join(t.TempDir(), "rules.yaml")
+	rules := "groups:\n  - name: example\n    rules:\n      - alert: InstanceDown\n        expr: up == 0\n"
+	require.NoError(t, os.WriteFile(ruleFile, []byte(rules), 0600))
+
+	var received apimodels.PrometheusRulesImport
+	var receivedPath, receivedAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.EscapedPath()
+		receivedAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if received.DatasourceUID != "prometheus" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"data source not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(apimodels.PrometheusRulesImportResult{
+			DryRun: received.DryRun,
+			Groups: []apimodels.PrometheusRulesImportGroupDelta{{Name: "example", Added: []string{"InstanceDown"}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	newContext := func(t *testing.T, flags map[string]string, args ...string) *utils.ContextCommandLine {
+		flagSet := flag.NewFlagSet("Test", 0)
+		for name, value := range flags {
+			flagSet.String(name, value, "")
+		}
+		require.NoError(t, flagSet.Parse(args))
+		return &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+	}
+
+	t.Run("should send the rule file to the import API", func(t *testing.T) {
+		c := newContext(t, map[string]string{
+			"url":            server.URL,
+			"token":          "secret",
+			"folder":         "Infra alerts",
+			"datasource-uid": "prometheus",
+			"dry-run":        "true",
+		}, ruleFile)
+
+		require.NoError(t, ImportPrometheusRules(c))
+		require.Equal(t, "/api/ruler/grafana/api/v1/import/prometheus/Infra%20alerts", receivedPath)
+		require.Equal(t, "Bearer secret", receivedAuth)
+		require.Equal(t, "prometheus", received.DatasourceUID)
+		require.Equal(t, rules, received.Groups)
+		require.True(t, received.DryRun)
+	})
+
+	t.Run("should return the error message of the API", func(t *testing.T) {
+		c := newContext(t, map[string]string{
+			"url":            server.URL,
+			"folder":         "Infra alerts",
+			"datasource-uid": "unknown",
+		}, ruleFile)
+
+		err := ImportPrometheusRules(c)
+		require.ErrorContains(t, err, "data source not found")
+	})
+
+	t.Run("should fail if an argument is missing", func(t *testing.T) {
+		c := newContext(t, map[string]string{"url": server.URL, "datasource-uid": "prometheus"}, ruleFile)
+		require.ErrorIs(t, ImportPrometheusRules(c), errMissingFolder)
+
+		c = newContext(t, map[string]string{"url": server.URL, "folder": "Infra alerts"}, ruleFile)
+		require.ErrorIs(t, ImportPrometheusRules(c), errMissingDatasource)
+
+		c = newContext(t, map[string]string{"url": server.URL, "folder": "Infra alerts", "datasource-uid": "prometheus"})
+		require.ErrorIs(t, ImportPrometheusRules(c), errMissingRuleFile)
+	})
+}
+
+func TestFormatRulesImportResult(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+	result := formatRulesImportResult(apimodels.PrometheusRulesImportResult{
+		DryRun: true,
+		Groups: []apimodels.PrometheusRulesImportGroupDelta{
+			{
+				Name:    "example",
+				Added:   []string{"InstanceDown"},
+				Updated: []apimodels.PrometheusRulesImportRuleDiff{{Title: "HighErrorRate", Diff: []string{"For:\n\t-: 5m0s\n\t+: 10m0s"}}},
+				Deleted: []string{"Obsolete"},
+				Skipped: []string{"job:errors:rate5m"},
+			},
+			{Name: "unchanged"},
+		},
+	})
+	require.Equal(t, `Dry run, no changes are applied.
+
+Rule group example:
+  + InstanceDown
+  ~ HighErrorRate
+      For:
+      	-: 5m0s
+      	+: 10m0s
+  - Obsolete
+  skipped recording rule job:errors:rate5m
+
+Rule group unchanged:
+  no changes
+`, result)
+}
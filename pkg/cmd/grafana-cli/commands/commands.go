@@ -8,6 +8,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alertingimport"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -228,6 +229,51 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:      "import-prometheus-rules",
+		Usage:     "Imports the alerting rules of a Prometheus rule file as Grafana-managed rules, or shows the changes with --dry-run",
+		ArgsUsage: "<rule file>",
+		Action: func(context *cli.Context) error {
+			return alertingimport.ImportPrometheusRules(&utils.ContextCommandLine{Context: context})
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "url",
+				Usage:   "URL of the Grafana server",
+				Value:   "http://localhost:3000",
+				EnvVars: []string{"GF_URL"},
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "Service account token or API key used to authenticate",
+				EnvVars: []string{"GF_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "User used to authenticate with basic authentication, if there is no token",
+			},
+			&cli.StringFlag{
+				Name:    "password",
+				Usage:   "Password of the user",
+				EnvVars: []string{"GF_PASSWORD"},
+			},
+			&cli.StringFlag{
+				Name:  "folder",
+				Usage: "Title of the folder of the rules",
+			},
+			&cli.StringFlag{
+				Name:  "datasource-uid",
+				Usage: "UID of the Prometheus data source that the rules query",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes to the rule groups without applying them",
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -239,4 +285,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana Alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
@@ -66,8 +66,8 @@ func (gm *MathCommand) NeedsVars() []string {
 
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
-func (gm *MathCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
-	return gm.Expression.Execute(gm.refID, vars)
+func (gm *MathCommand) Execute(_ context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	return gm.Expression.Execute(gm.refID, vars, now)
 }
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
@@ -80,7 +80,7 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	_, err := mathexp.GetSeriesReduceFunc(reducer)
 	if err != nil {
 		return nil, err
 	}
@@ -279,6 +279,128 @@ func (gr *ResampleCommand) Execute(_ context.Context, now time.Time, vars mathex
 	return newRes, nil
 }
 
+// AnomalyCommand is an expression command that detects anomalies in a timeseries by
+// comparing each point with a baseline computed from previous points.
+type AnomalyCommand struct {
+	VarToCheck string
+	Options    mathexp.AnomalyOptions
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToCheck string, options mathexp.AnomalyOptions) (*AnomalyCommand, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return &AnomalyCommand{
+		VarToCheck: varToCheck,
+		Options:    options,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID to detect anomalies in. must be a reference to an existing query or expression")
+	}
+	varToCheck, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected anomaly input variable to be type string, but got type %T", rawVar)
+	}
+	varToCheck = strings.TrimPrefix(varToCheck, "$")
+
+	options := mathexp.AnomalyOptions{
+		Algorithm:  mathexp.AnomalyMeanStddev,
+		Output:     mathexp.AnomalyOutputScore,
+		Deviations: 3,
+		Seasons:    1,
+	}
+
+	rawWindow, ok := rn.Query["window"]
+	if !ok {
+		return nil, errors.New("no time duration specified for the window in anomaly command")
+	}
+	window, ok := rawWindow.(string)
+	if !ok {
+		return nil, fmt.Errorf("anomaly window is expected to be a string, got %T", rawWindow)
+	}
+	var err error
+	if options.Window, err = gtime.ParseDuration(window); err != nil {
+		return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, window, err)
+	}
+
+	if rawAlgorithm, ok := rn.Query["algorithm"]; ok {
+		if options.Algorithm, ok = rawAlgorithm.(string); !ok {
+			return nil, fmt.Errorf("expected anomaly algorithm to be a string, got type %T", rawAlgorithm)
+		}
+	}
+
+	if rawOutput, ok := rn.Query["output"]; ok {
+		if options.Output, ok = rawOutput.(string); !ok {
+			return nil, fmt.Errorf("expected anomaly output to be a string, got type %T", rawOutput)
+		}
+	}
+
+	if rawDeviations, ok := rn.Query["deviations"]; ok {
+		if options.Deviations, ok = rawDeviations.(float64); !ok {
+			return nil, fmt.Errorf("expected anomaly deviations to be a number, got type %T", rawDeviations)
+		}
+	}
+
+	if rawSeason, ok := rn.Query["season"]; ok {
+		season, ok := rawSeason.(string)
+		if !ok {
+			return nil, fmt.Errorf("anomaly season is expected to be a string, got %T", rawSeason)
+		}
+		if season != "" {
+			if options.Season, err = gtime.ParseDuration(season); err != nil {
+				return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, season, err)
+			}
+		}
+	}
+
+	if rawSeasons, ok := rn.Query["seasons"]; ok {
+		seasons, ok := rawSeasons.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected anomaly seasons to be a number, got type %T", rawSeasons)
+		}
+		options.Seasons = int(seasons)
+	}
+
+	return NewAnomalyCommand(rn.RefID, varToCheck, options)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToCheck}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToCheck].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			series, err := v.Anomaly(ac.refID, ac.Options)
+			if err != nil {
+				return newRes, err
+			}
+			for _, s := range series {
+				newRes.Values = append(newRes.Values, s)
+			}
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -295,6 +417,10 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeSQL is the CMDType for a SQL expression over the results of other queries.
+	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in a timeseries.
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -307,6 +433,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeSQL:
+		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -325,6 +457,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "sql":
+		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
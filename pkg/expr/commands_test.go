@@ -170,3 +170,110 @@ func randomReduceFunc() string {
 	res := mathexp.GetSupportedReduceFuncs()
 	return res[rand.Intn(len(res)-1)]
 }
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	var tests = []struct {
+		name            string
+		query           string
+		isError         bool
+		expectedOptions mathexp.AnomalyOptions
+	}{
+		{
+			name:  "defaults to mean and stddev score",
+			query: `{ "expression" : "$A", "window": "1h" }`,
+			expectedOptions: mathexp.AnomalyOptions{
+				Window:     time.Hour,
+				Algorithm:  mathexp.AnomalyMeanStddev,
+				Output:     mathexp.AnomalyOutputScore,
+				Deviations: 3,
+				Seasons:    1,
+			},
+		},
+		{
+			name:  "seasonal median and MAD band",
+			query: `{ "expression" : "A", "window": "30m", "algorithm": "median_mad", "output": "band", "deviations": 2, "season": "1d", "seasons": 7 }`,
+			expectedOptions: mathexp.AnomalyOptions{
+				Window:     30 * time.Minute,
+				Algorithm:  mathexp.AnomalyMedianMAD,
+				Output:     mathexp.AnomalyOutputBand,
+				Deviations: 2,
+				Season:     24 * time.Hour,
+				Seasons:    7,
+			},
+		},
+		{
+			name:    "error when window is missing",
+			query:   `{ "expression" : "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when algorithm is not known",
+			query:   `{ "expression" : "$A", "window": "1h", "algorithm": "foo" }`,
+			isError: true,
+		},
+		{
+			name:    "error when season is not a duration",
+			query:   `{ "expression" : "$A", "window": "1h", "season": "foo" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID: "B",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			require.Equal(t, test.expectedOptions, cmd.Options)
+		})
+	}
+}
+
+func TestAnomalyExecute(t *testing.T) {
+	cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyOptions{
+		Window:     time.Minute,
+		Algorithm:  mathexp.AnomalyMeanStddev,
+		Output:     mathexp.AnomalyOutputBand,
+		Deviations: 3,
+	})
+	require.NoError(t, err)
+
+	t.Run("should return a band for every series", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": mathexp.Results{
+				Values: []mathexp.Value{
+					mathexp.NewSeries("A", data.Labels{"host": "a"}, 0),
+					mathexp.NewSeries("A", data.Labels{"host": "b"}, 0),
+				},
+			},
+		}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 4)
+	})
+
+	t.Run("should pass no data through", func(t *testing.T) {
+		vars := mathexp.Vars{"A": mathexp.Results{Values: []mathexp.Value{mathexp.NoData{}.New()}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, mathexp.NoData{}.New(), res.Values[0])
+	})
+
+	t.Run("should fail for numbers", func(t *testing.T) {
+		vars := mathexp.Vars{"A": mathexp.Results{Values: []mathexp.Value{mathexp.GenerateNumber(ptr.Float64(1))}}}
+		_, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.ErrorContains(t, err, "can only detect anomalies in type series")
+	})
+}
@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DebugRefID is the RefID of the response that holds the execution trace of the pipeline
+// when a request is executed in debug mode.
+const DebugRefID = "__expr_debug__"
+
+// NodeStatus is the outcome of the execution of a node of a pipeline.
+type NodeStatus string
+
+const (
+	NodeStatusOK    NodeStatus = "ok"
+	NodeStatusError NodeStatus = "error"
+	// NodeStatusSkipped is the status of the nodes that were not executed because a node failed before them.
+	NodeStatusSkipped NodeStatus = "skipped"
+)
+
+// NodeTrace describes the execution of a node of a pipeline.
+type NodeTrace struct {
+	RefID string
+	// Type is "datasource" for data source queries, or the type of the expression command, e.g. "math".
+	Type string
+	// Order is the position of the node in the execution order of the pipeline.
+	Order int
+	// DependsOn contains the RefIDs of the nodes whose results are used by the node.
+	DependsOn []string
+	Duration  time.Duration
+	Status    NodeStatus
+	Error     error
+}
+
+func newNodeTrace(node Node, order int) NodeTrace {
+	trace := NodeTrace{
+		RefID: node.RefID(),
+		Order: order,
+	}
+	switch n := node.(type) {
+	case *CMDNode:
+		trace.Type = n.CMDType.String()
+		trace.DependsOn = append(trace.DependsOn, n.Command.NeedsVars()...)
+		sort.Strings(trace.DependsOn)
+	case *DSNode:
+		trace.Type = "datasource"
+	}
+	return trace
+}
+
+// ExecutePipelineDebug executes an expression pipeline like ExecutePipeline, and adds a response with
+// the RefID DebugRefID that contains a frame with the execution trace of every node. If a node fails,
+// its error is set in its response, the expressions that were not executed yet are skipped, and the
+// results of the other nodes are still returned along with the error.
+func (s *Service) ExecutePipelineDebug(ctx context.Context, now time.Time, pipeline DataPipeline) (*backend.QueryDataResponse, error) {
+	res := backend.NewQueryDataResponse()
+	vars, traces, err := pipeline.executeWithTraces(ctx, now, s, true)
+	for refID, val := range vars {
+		res.Responses[refID] = backend.DataResponse{
+			Frames: val.Values.AsDataFrames(refID),
+		}
+	}
+	for _, trace := range traces {
+		if trace.Error != nil {
+			res.Responses[trace.RefID] = backend.DataResponse{Error: trace.Error}
+		}
+	}
+	res.Responses[DebugRefID] = backend.DataResponse{
+		Frames: data.Frames{tracesToFrame(traces, res)},
+	}
+	return res, err
+}
+
+// tracesToFrame returns a table with a row per node of the pipeline, in execution order.
+func tracesToFrame(traces []NodeTrace, res *backend.QueryDataResponse) *data.Frame {
+	frame := data.NewFrame("Expression pipeline",
+		data.NewField("refId", nil, []string{}),
+		data.NewField("type", nil, []string{}),
+		data.NewField("order", nil, []int64{}),
+		data.NewField("dependsOn", nil, []string{}),
+		data.NewField("durationMs", nil, []float64{}),
+		data.NewField("frames", nil, []int64{}),
+		data.NewField("status", nil, []string{}),
+		data.NewField("error", nil, []string{}),
+	)
+	frame.RefID = DebugRefID
+	for _, trace := range traces {
+		errMsg := ""
+		if trace.Error != nil {
+			errMsg = trace.Error.Error()
+		}
+		frame.AppendRow(
+			trace.RefID,
+			trace.Type,
+			int64(trace.Order),
+			strings.Join(trace.DependsOn, ","),
+			float64(trace.Duration.Nanoseconds())/float64(time.Millisecond),
+			int64(len(res.Responses[trace.RefID].Frames)),
+			string(trace.Status),
+			errMsg,
+		)
+	}
+	return frame
+}
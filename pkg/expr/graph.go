@@ -3,9 +3,13 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 
 	"gonum.org/v1/gonum/graph/simple"
@@ -48,16 +52,141 @@ type DataPipeline []Node
 // execute runs all the command/datasource requests in the pipeline return a
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
+	vars, _, err := dp.executeWithTraces(c, now, s, false)
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// executeWithTraces is like execute, but if withTraces is true it also returns the execution
+// trace of every node of the pipeline. The results of the nodes that were executed before an
+// error are returned along with the error.
+//
+// Data source queries do not depend on other nodes, so they are executed first and concurrently.
+// Expression commands are then executed one by one in dependency order.
+func (dp *DataPipeline) executeWithTraces(c context.Context, now time.Time, s *Service, withTraces bool) (mathexp.Vars, []NodeTrace, error) {
 	vars := make(mathexp.Vars)
-	for _, node := range *dp {
+	var traces []NodeTrace
+	if withTraces {
+		traces = make([]NodeTrace, len(*dp))
+		for i, node := range *dp {
+			traces[i] = newNodeTrace(node, i)
+		}
+	}
+
+	execErr := dp.executeDSNodes(c, now, s, vars, traces)
+	if execErr != nil && !withTraces {
+		return vars, nil, execErr
+	}
+
+	for i, node := range *dp {
+		if node.NodeType() != TypeCMDNode {
+			continue
+		}
+		if execErr != nil {
+			traces[i].Status = NodeStatusSkipped
+			continue
+		}
+
+		start := time.Now()
 		res, err := node.Execute(c, now, vars, s)
+		if withTraces {
+			traces[i].Duration = time.Since(start)
+			traces[i].Status = NodeStatusOK
+			if err != nil {
+				traces[i].Status = NodeStatusError
+				traces[i].Error = err
+			}
+		}
 		if err != nil {
-			return nil, err
+			if !withTraces {
+				return vars, nil, err
+			}
+			execErr = err
+			continue
 		}
 
 		vars[node.RefID()] = res
 	}
-	return vars, nil
+	return vars, traces, execErr
+}
+
+// executeDSNodes executes the data source queries of the pipeline concurrently, up to the
+// limit of concurrent queries of the service, and adds their results to vars. If traces is nil,
+// the queries that are still running are canceled at the first error. Otherwise, all the
+// queries are executed, their traces are updated, and the error of the first failed query
+// in the pipeline is returned.
+func (dp *DataPipeline) executeDSNodes(c context.Context, now time.Time, s *Service, vars mathexp.Vars, traces []NodeTrace) error {
+	g, gCtx := errgroup.WithContext(c)
+	if limit := s.maxConcurrentQueries(); limit > 0 {
+		g.SetLimit(limit)
+	}
+
+	var mu sync.Mutex
+	errs := make([]error, len(*dp))
+	for i, node := range *dp {
+		if node.NodeType() != TypeDatasourceNode {
+			continue
+		}
+		i, node := i, node
+		g.Go(func() error {
+			start := time.Now()
+			var res mathexp.Results
+			// The context is canceled if another query failed while this one was waiting to be executed.
+			err := gCtx.Err()
+			if err == nil {
+				res, err = s.executeDSNode(gCtx, now, node)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if traces != nil {
+				traces[i].Duration = time.Since(start)
+				traces[i].Status = NodeStatusOK
+				if err != nil {
+					traces[i].Status = NodeStatusError
+					traces[i].Error = err
+				}
+			}
+			if err != nil {
+				errs[i] = err
+				if traces == nil {
+					return err
+				}
+				return nil
+			}
+			vars[node.RefID()] = res
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeDSNode executes a data source query with the query timeout of the service.
+func (s *Service) executeDSNode(c context.Context, now time.Time, node Node) (mathexp.Results, error) {
+	timeout := s.queryTimeout()
+	if timeout <= 0 {
+		// Data source queries do not use the results of other nodes.
+		return node.Execute(c, now, nil, s)
+	}
+
+	ctx, cancel := context.WithTimeout(c, timeout)
+	defer cancel()
+	res, err := node.Execute(ctx, now, nil, s)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return res, fmt.Errorf("query %s timed out after %v: %w", node.RefID(), timeout, err)
+	}
+	return res, err
 }
 
 // BuildPipeline builds a graph of the nodes, and returns the nodes in an
@@ -205,6 +334,14 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 				}
 			}
 
+			if dsNode, ok := neededNode.(*DSNode); ok {
+				if cmdNode.CMDType == TypeSQL {
+					dsNode.isInputToSQLExpr = true
+				} else {
+					dsNode.isInputToOtherExpr = true
+				}
+			}
+
 			edge := dp.NewEdge(neededNode, cmdNode)
 
 			dp.SetEdge(edge)
@@ -1,12 +1,22 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestServicebuildPipeLine(t *testing.T) {
@@ -253,3 +263,127 @@ func getRefIDOrder(nodes []Node) []string {
 	}
 	return ids
 }
+
+func TestDataPipelineExecuteConcurrently(t *testing.T) {
+	newRequest := func(queries int) *Request {
+		req := &Request{}
+		var refIDs []string
+		for i := 0; i < queries; i++ {
+			refID := fmt.Sprintf("Q%d", i)
+			refIDs = append(refIDs, "$"+refID)
+			req.Queries = append(req.Queries, Query{
+				RefID:      refID,
+				DataSource: &datasources.DataSource{Uid: "test", Type: "test"},
+				JSON:       json.RawMessage(`{}`),
+				TimeRange:  AbsoluteTimeRange{},
+			})
+		}
+		req.Queries = append(req.Queries, Query{
+			RefID:      "SUM",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(fmt.Sprintf(`{ "type": "math", "expression": "%s" }`, strings.Join(refIDs, " + "))),
+		})
+		return req
+	}
+	newService := func(endpoint *concurrencyEndpoint, maxConcurrentQueries int, queryTimeout time.Duration) *Service {
+		cfg := setting.NewCfg()
+		cfg.ExpressionsMaxConcurrentQueries = maxConcurrentQueries
+		cfg.ExpressionsQueryTimeout = queryTimeout
+		return &Service{
+			cfg:               cfg,
+			dataService:       endpoint,
+			dataSourceService: &datafakes.FakeDataSourceService{},
+		}
+	}
+
+	t.Run("executes the queries at the same time", func(t *testing.T) {
+		// Every query waits for all the queries to be running, so they cannot be executed one by one.
+		endpoint := newConcurrencyEndpoint(4, 10*time.Second)
+		s := newService(endpoint, 0, 0)
+		pl, err := s.BuildPipeline(newRequest(4))
+		require.NoError(t, err)
+
+		vars, err := pl.execute(context.Background(), time.Now(), s)
+		require.NoError(t, err)
+		require.Equal(t, 4, endpoint.maxRunning)
+		require.Len(t, vars, 5)
+		require.Equal(t, fp(4), vars["SUM"].Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("honors the limit of concurrent queries", func(t *testing.T) {
+		endpoint := newConcurrencyEndpoint(0, 10*time.Millisecond)
+		s := newService(endpoint, 2, 0)
+		pl, err := s.BuildPipeline(newRequest(6))
+		require.NoError(t, err)
+
+		vars, err := pl.execute(context.Background(), time.Now(), s)
+		require.NoError(t, err)
+		require.LessOrEqual(t, endpoint.maxRunning, 2)
+		require.Len(t, vars, 7)
+		require.Equal(t, fp(6), vars["SUM"].Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("fails queries that time out", func(t *testing.T) {
+		endpoint := newConcurrencyEndpoint(0, 10*time.Second)
+		s := newService(endpoint, 0, 10*time.Millisecond)
+		pl, err := s.BuildPipeline(newRequest(2))
+		require.NoError(t, err)
+
+		_, err = pl.execute(context.Background(), time.Now(), s)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "timed out after 10ms")
+	})
+}
+
+// concurrencyEndpoint returns the number 1 for every query, and records the maximum number
+// of queries that were running at the same time.
+type concurrencyEndpoint struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+
+	// waitFor is the number of running queries that releases all the queries. If it is 0,
+	// queries are released after delay.
+	waitFor int
+	release chan struct{}
+	delay   time.Duration
+}
+
+func newConcurrencyEndpoint(waitFor int, delay time.Duration) *concurrencyEndpoint {
+	return &concurrencyEndpoint{
+		waitFor: waitFor,
+		release: make(chan struct{}),
+		delay:   delay,
+	}
+}
+
+func (e *concurrencyEndpoint) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	e.mu.Lock()
+	e.running++
+	if e.running > e.maxRunning {
+		e.maxRunning = e.running
+	}
+	if e.waitFor > 0 && e.running == e.waitFor {
+		close(e.release)
+	}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.running--
+		e.mu.Unlock()
+	}()
+
+	select {
+	case <-e.release:
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resp := backend.NewQueryDataResponse()
+	refID := req.Queries[0].RefID
+	resp.Responses[refID] = backend.DataResponse{
+		Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []*float64{fp(1)}))},
+	}
+	return resp, nil
+}
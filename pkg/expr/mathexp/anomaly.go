@@ -0,0 +1,213 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// AnomalyMeanStddev uses the mean as the baseline and the standard deviation as the spread.
+	AnomalyMeanStddev = "mean_stddev"
+	// AnomalyMedianMAD uses the median as the baseline and the median absolute deviation as the spread.
+	// It is less sensitive to outliers in the baseline window than AnomalyMeanStddev.
+	AnomalyMedianMAD = "median_mad"
+
+	// AnomalyOutputScore outputs the number of deviations between each point and its baseline.
+	AnomalyOutputScore = "score"
+	// AnomalyOutputBand outputs the upper and lower bounds of the expected values.
+	AnomalyOutputBand = "band"
+
+	// AnomalyBandLabel is the label added to the series of the band to tell the upper and lower bounds apart.
+	AnomalyBandLabel = "band"
+
+	// madScale makes the median absolute deviation a consistent estimator of the standard deviation
+	// for normally distributed values.
+	madScale = 1.4826
+)
+
+// AnomalyOptions configures how Series.Anomaly computes the baseline of each point.
+type AnomalyOptions struct {
+	// Window is the duration of the baseline window.
+	Window time.Duration
+	// Algorithm is either AnomalyMeanStddev or AnomalyMedianMAD.
+	Algorithm string
+	// Output is either AnomalyOutputScore or AnomalyOutputBand.
+	Output string
+	// Deviations is the number of deviations between the baseline and the bounds of the band.
+	Deviations float64
+	// Season is the length of a season, for example a day or a week. If it is zero, the baseline
+	// of a point is the window right before it. Otherwise, the baseline is made of the windows
+	// that end at the same time as the point, one to Seasons seasons back.
+	Season time.Duration
+	// Seasons is the number of seasons to look back in seasonal mode.
+	Seasons int
+}
+
+// Validate checks that the options can be used to detect anomalies.
+func (o AnomalyOptions) Validate() error {
+	if o.Window <= 0 {
+		return fmt.Errorf("anomaly window must be greater than zero, got %v", o.Window)
+	}
+	switch o.Algorithm {
+	case AnomalyMeanStddev, AnomalyMedianMAD:
+	default:
+		return fmt.Errorf("anomaly algorithm must be one of %s, %s, got %q", AnomalyMeanStddev, AnomalyMedianMAD, o.Algorithm)
+	}
+	switch o.Output {
+	case AnomalyOutputScore:
+	case AnomalyOutputBand:
+		if o.Deviations <= 0 {
+			return fmt.Errorf("anomaly band deviations must be greater than zero, got %v", o.Deviations)
+		}
+	default:
+		return fmt.Errorf("anomaly output must be one of %s, %s, got %q", AnomalyOutputScore, AnomalyOutputBand, o.Output)
+	}
+	if o.Season < 0 {
+		return fmt.Errorf("anomaly season must not be negative, got %v", o.Season)
+	}
+	if o.Season > 0 && o.Seasons < 1 {
+		return fmt.Errorf("anomaly seasons must be at least 1, got %d", o.Seasons)
+	}
+	return nil
+}
+
+// Anomaly compares each point of the series with a baseline computed from the points in a window
+// before it, or from the same window in previous seasons. Depending on the output, it returns
+// a single series with the score of each point, or two series with the upper and lower bounds
+// of the band. Points without enough data for a baseline are null.
+func (s Series) Anomaly(refID string, opts AnomalyOptions) ([]Series, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	points := make([]anomalyPoint, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		points = append(points, anomalyPoint{t: t, f: f})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+
+	var score, upper, lower Series
+	switch opts.Output {
+	case AnomalyOutputScore:
+		score = NewSeries(refID, s.GetLabels(), len(points))
+	case AnomalyOutputBand:
+		upper = NewSeries(refID, bandLabels(s.GetLabels(), "upper"), len(points))
+		lower = NewSeries(refID, bandLabels(s.GetLabels(), "lower"), len(points))
+	}
+
+	for i, p := range points {
+		var values []float64
+		if opts.Season == 0 {
+			// The rolling window excludes the point itself so that it does not affect its own baseline.
+			values = windowValues(points, p.t.Add(-opts.Window), p.t, false, values)
+		} else {
+			for n := 1; n <= opts.Seasons; n++ {
+				end := p.t.Add(-time.Duration(n) * opts.Season)
+				values = windowValues(points, end.Add(-opts.Window), end, true, values)
+			}
+		}
+
+		center, spread, ok := baseline(opts.Algorithm, values)
+		switch opts.Output {
+		case AnomalyOutputScore:
+			if !ok || p.f == nil || math.IsNaN(*p.f) {
+				score.SetPoint(i, p.t, nil)
+				continue
+			}
+			z := zScore(*p.f, center, spread)
+			score.SetPoint(i, p.t, &z)
+		case AnomalyOutputBand:
+			if !ok {
+				upper.SetPoint(i, p.t, nil)
+				lower.SetPoint(i, p.t, nil)
+				continue
+			}
+			u := center + opts.Deviations*spread
+			l := center - opts.Deviations*spread
+			upper.SetPoint(i, p.t, &u)
+			lower.SetPoint(i, p.t, &l)
+		}
+	}
+
+	if opts.Output == AnomalyOutputBand {
+		return []Series{upper, lower}, nil
+	}
+	return []Series{score}, nil
+}
+
+type anomalyPoint struct {
+	t time.Time
+	f *float64
+}
+
+// windowValues appends the numeric values of the points with a time after from, and before to
+// (or at to if inclusive) to values. The points must be sorted by time.
+func windowValues(points []anomalyPoint, from, to time.Time, inclusive bool, values []float64) []float64 {
+	start := sort.Search(len(points), func(i int) bool {
+		return points[i].t.After(from)
+	})
+	for i := start; i < len(points); i++ {
+		t := points[i].t
+		if t.After(to) || (!inclusive && t.Equal(to)) {
+			break
+		}
+		f := points[i].f
+		if f == nil || math.IsNaN(*f) || math.IsInf(*f, 0) {
+			continue
+		}
+		values = append(values, *f)
+	}
+	return values
+}
+
+// baseline returns the center and the spread of the values for the algorithm, with the same calculations as the
+// reducers Median and Stddev. It returns false if there are not enough values to compute them. It sorts the values.
+func baseline(algorithm string, values []float64) (float64, float64, bool) {
+	if len(values) < 2 {
+		return 0, 0, false
+	}
+	switch algorithm {
+	case AnomalyMedianMAD:
+		center := percentile(values, 50)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - center)
+		}
+		return center, madScale * percentile(deviations, 50), true
+	default:
+		mean, stddev := meanStddev(values)
+		return mean, stddev, true
+	}
+}
+
+// zScore returns the number of deviations between v and the center. If there is no spread,
+// it is 0 when v equals the center and an infinity of the sign of the difference otherwise.
+func zScore(v, center, spread float64) float64 {
+	if spread == 0 {
+		switch {
+		case v > center:
+			return math.Inf(1)
+		case v < center:
+			return math.Inf(-1)
+		default:
+			return 0
+		}
+	}
+	return (v - center) / spread
+}
+
+func bandLabels(labels data.Labels, band string) data.Labels {
+	newLabels := labels.Copy()
+	if newLabels == nil {
+		newLabels = data.Labels{}
+	}
+	newLabels[AnomalyBandLabel] = band
+	return newLabels
+}
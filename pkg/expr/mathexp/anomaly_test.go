@@ -0,0 +1,101 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesAnomaly(t *testing.T) {
+	series := makeSeries("", data.Labels{"host": "a"},
+		tp{time.Unix(10, 0), float64Pointer(10)},
+		tp{time.Unix(20, 0), float64Pointer(12)},
+		tp{time.Unix(30, 0), float64Pointer(10)},
+		tp{time.Unix(40, 0), float64Pointer(12)},
+		tp{time.Unix(50, 0), float64Pointer(20)},
+	)
+
+	t.Run("mean and stddev score", func(t *testing.T) {
+		res, err := series.Anomaly("B", AnomalyOptions{
+			Window:    30 * time.Second,
+			Algorithm: AnomalyMeanStddev,
+			Output:    AnomalyOutputScore,
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, data.Labels{"host": "a"}, res[0].GetLabels())
+		require.Equal(t, 5, res[0].Len())
+		// not enough points for a baseline
+		require.Nil(t, res[0].GetValue(0))
+		require.Nil(t, res[0].GetValue(1))
+		// baseline of 10 and 12
+		require.InDelta(t, -1, *res[0].GetValue(2), 0.0001)
+		// baseline of 12 and 10, the point at 10s is out of the window
+		require.InDelta(t, 1, *res[0].GetValue(3), 0.0001)
+		// baseline of 10 and 12, the window does not include its start
+		require.InDelta(t, 9, *res[0].GetValue(4), 0.0001)
+	})
+
+	t.Run("median and MAD band", func(t *testing.T) {
+		res, err := series.Anomaly("B", AnomalyOptions{
+			Window:     50 * time.Second,
+			Algorithm:  AnomalyMedianMAD,
+			Output:     AnomalyOutputBand,
+			Deviations: 2,
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, data.Labels{"host": "a", "band": "upper"}, res[0].GetLabels())
+		require.Equal(t, data.Labels{"host": "a", "band": "lower"}, res[1].GetLabels())
+		// baseline of 10, 12, 10 and 12: median 11, MAD 1
+		require.InDelta(t, 11+2*madScale, *res[0].GetValue(4), 0.0001)
+		require.InDelta(t, 11-2*madScale, *res[1].GetValue(4), 0.0001)
+	})
+
+	t.Run("seasonal score", func(t *testing.T) {
+		seasonal := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(3)},
+			tp{time.Unix(100, 0), float64Pointer(3)},
+			tp{time.Unix(110, 0), float64Pointer(5)},
+			tp{time.Unix(200, 0), float64Pointer(2)},
+			tp{time.Unix(210, 0), float64Pointer(7)},
+		)
+		res, err := seasonal.Anomaly("B", AnomalyOptions{
+			Window:    20 * time.Second,
+			Algorithm: AnomalyMeanStddev,
+			Output:    AnomalyOutputScore,
+			Season:    100 * time.Second,
+			Seasons:   2,
+		})
+		require.NoError(t, err)
+		// the point at 210s is compared with the points at 100s, 110s, 0s and 10s
+		require.InDelta(t, (7-3)/math.Sqrt(2), *res[0].GetValue(5), 0.0001)
+		// the point at 110s only has the first season
+		require.InDelta(t, (5-2)/1.0, *res[0].GetValue(3), 0.0001)
+		require.Nil(t, res[0].GetValue(1))
+	})
+
+	t.Run("flat baseline", func(t *testing.T) {
+		flat := makeSeries("", nil,
+			tp{time.Unix(10, 0), float64Pointer(1)},
+			tp{time.Unix(20, 0), float64Pointer(1)},
+			tp{time.Unix(30, 0), float64Pointer(1)},
+			tp{time.Unix(40, 0), float64Pointer(2)},
+		)
+		res, err := flat.Anomaly("B", AnomalyOptions{Window: time.Minute, Algorithm: AnomalyMeanStddev, Output: AnomalyOutputScore})
+		require.NoError(t, err)
+		require.Equal(t, float64(0), *res[0].GetValue(2))
+		require.True(t, math.IsInf(*res[0].GetValue(3), 1))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := series.Anomaly("B", AnomalyOptions{Window: time.Minute, Algorithm: "foo", Output: AnomalyOutputScore})
+		require.ErrorContains(t, err, "anomaly algorithm must be one of")
+		_, err = series.Anomaly("B", AnomalyOptions{Window: time.Minute, Algorithm: AnomalyMeanStddev, Output: AnomalyOutputScore, Season: time.Hour})
+		require.ErrorContains(t, err, "anomaly seasons must be at least 1")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -24,6 +25,8 @@ type State struct {
 	//  - Unions (How many result A and many Result B in case A + B are joined)
 	//  - NaN/Null behavior
 	RefID string
+	// Now is the time the expression is evaluated at, used by functions such as time() and hour().
+	Now time.Time
 }
 
 // Vars holds the results of datasource queries or other expression commands.
@@ -43,11 +46,12 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 }
 
 // Execute applies a parse expression to the context and executes it
-func (e *Expr) Execute(refID string, vars Vars) (r Results, err error) {
+func (e *Expr) Execute(refID string, vars Vars, now time.Time) (r Results, err error) {
 	s := &State{
 		Expr:  e,
 		Vars:  vars,
 		RefID: refID,
+		Now:   now,
 	}
 	return e.executeState(s)
 }
@@ -247,6 +251,115 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchingSignature returns the labels of a Value that are used to match it with values
+// of the other side of a binary operation with vector matching modifiers.
+func matchingSignature(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	sig := data.Labels{}
+	if matching.On {
+		for _, name := range matching.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				sig[name] = v
+			}
+		}
+		return sig
+	}
+	for name, v := range labels {
+		sig[name] = v
+	}
+	for _, name := range matching.MatchingLabels {
+		delete(sig, name)
+	}
+	return sig
+}
+
+// resultLabels returns the labels of the result of a binary operation with vector matching modifiers,
+// where many is the labels of the item on the "many" side (or the left side for one-to-one matching).
+func resultLabels(many, one data.Labels, matching *parse.VectorMatching) data.Labels {
+	var labels data.Labels
+	if matching.Card == parse.CardOneToOne {
+		labels = matchingSignature(many, matching)
+	} else {
+		labels = many.Copy()
+		if labels == nil {
+			labels = data.Labels{}
+		}
+	}
+	for _, name := range matching.Include {
+		if v, ok := one[name]; ok {
+			labels[name] = v
+		} else {
+			delete(labels, name)
+		}
+	}
+	return labels
+}
+
+// vectorMatchUnion creates Union objects like union, but matches the values of the
+// two sides using the on/ignoring and group_left/group_right modifiers of the operation,
+// similar to Prometheus. It returns an error if the matching is ambiguous.
+func vectorMatchUnion(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	for _, v := range append(append(Values{}, aResults.Values...), bResults.Values...) {
+		switch v.Type() {
+		case parse.TypeNoData:
+			return unions, nil
+		case parse.TypeScalar:
+			// vector matching does not apply to scalars, they join to anything
+			return union(aResults, bResults), nil
+		}
+	}
+
+	// the "one" side is the side that each item of the other side must match at most once
+	oneSide, manySide := bResults.Values, aResults.Values
+	if matching.Card == parse.CardOneToMany {
+		oneSide, manySide = aResults.Values, bResults.Values
+	}
+
+	oneBySig := make(map[string]Value, len(oneSide))
+	for _, one := range oneSide {
+		sig := matchingSignature(one.GetLabels(), matching).String()
+		if _, ok := oneBySig[sig]; ok {
+			return nil, fmt.Errorf("found duplicate series for the match group %s on the %s side of the operation, many-to-many matching is not allowed", sig, oneSideName(matching))
+		}
+		oneBySig[sig] = one
+	}
+
+	matchedSigs := make(map[string]struct{}, len(manySide))
+	for _, many := range manySide {
+		sig := matchingSignature(many.GetLabels(), matching).String()
+		one, ok := oneBySig[sig]
+		if !ok {
+			continue
+		}
+		if matching.Card == parse.CardOneToOne {
+			if _, ok := matchedSigs[sig]; ok {
+				return nil, fmt.Errorf("multiple matches for labels %s, use group_left or group_right to allow many-to-one matching", sig)
+			}
+			matchedSigs[sig] = struct{}{}
+		}
+		u := &Union{
+			Labels: resultLabels(many.GetLabels(), one.GetLabels(), matching),
+			A:      many,
+			B:      one,
+		}
+		if matching.Card == parse.CardOneToMany {
+			u.A, u.B = one, many
+		}
+		unions = append(unions, u)
+	}
+	return unions, nil
+}
+
+func oneSideName(matching *parse.VectorMatching) string {
+	if matching.Card == parse.CardOneToMany {
+		return "left"
+	}
+	return "right"
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -257,7 +370,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.VectorMatching != nil {
+		unions, err = vectorMatchUnion(ar, br, node.VectorMatching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -497,6 +618,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 		switch t := a.(type) {
 		case *parse.StringNode:
 			v = t.Text
+		case *parse.DurationNode:
+			v = t.Duration
 		case *parse.VarNode:
 			v = e.Vars[t.Name]
 		case *parse.ScalarNode:
@@ -3,6 +3,7 @@ package mathexp
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -78,7 +79,7 @@ func TestScalarExpr(t *testing.T) {
 			e, err := New(tt.expr)
 			tt.newErrIs(t, err)
 			if e != nil {
-				res, err := e.Execute("", tt.vars)
+				res, err := e.Execute("", tt.vars, time.Now())
 				tt.execErrIs(t, err)
 				tt.resultIs(t, tt.Results, res)
 			}
@@ -131,7 +132,7 @@ func TestNumberExpr(t *testing.T) {
 			e, err := New(tt.expr)
 			tt.newErrIs(t, err)
 			if e != nil {
-				res, err := e.Execute("", tt.vars)
+				res, err := e.Execute("", tt.vars, time.Now())
 				tt.execErrIs(t, err)
 				tt.resultIs(t, tt.results, res)
 			}
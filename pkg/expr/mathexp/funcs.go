@@ -68,6 +68,73 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"fill": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalarOrString},
+		Return: parse.TypeSeriesSet,
+		F:      fill,
+	},
+	"predict_linear": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeNumberSet,
+		F:      predictLinear,
+	},
+	"predict_holt_winters": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration, parse.TypeScalar, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      predictHoltWinters,
+	},
+	"label_replace": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString, parse.TypeString, parse.TypeString, parse.TypeString},
+		VariantReturn: true,
+		F:             labelReplace,
+	},
+	"label_drop": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             labelDrop,
+	},
+	"time": {
+		Return: parse.TypeScalar,
+		F:      timeNow,
+	},
+	"hour": {
+		Return: parse.TypeScalar,
+		F:      hour,
+	},
+	"day_of_week": {
+		Return: parse.TypeScalar,
+		F:      dayOfWeek,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
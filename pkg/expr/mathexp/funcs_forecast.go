@@ -0,0 +1,145 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// predictLinear returns, for each series, the value predicted at horizon after the evaluation
+// time, using a least-squares linear regression over the points of the series.
+// It is the equivalent of predict_linear in PromQL, for example predict_linear($A, "4h") < 0
+// alerts when a disk will be full in 4 hours.
+func predictLinear(e *State, varSet Results, horizon time.Duration) (Results, error) {
+	return perSeriesNumber(e, "predict_linear", varSet, func(s Series) *float64 {
+		times, values := forecastPoints(s)
+		if len(values) < 2 {
+			return nil
+		}
+		target := forecastTarget(e, times, horizon)
+
+		// x is in seconds relative to the target to keep the numbers small.
+		var sumX, sumY, sumXY, sumX2 float64
+		for i, t := range times {
+			x := t.Sub(target).Seconds()
+			sumX += x
+			sumY += values[i]
+			sumXY += x * values[i]
+			sumX2 += x * x
+		}
+		n := float64(len(values))
+		covXY := sumXY - sumX*sumY/n
+		varX := sumX2 - sumX*sumX/n
+		if varX == 0 {
+			return nil
+		}
+		slope := covXY / varX
+		// The value at x = 0, which is the target.
+		intercept := sumY/n - slope*sumX/n
+		return &intercept
+	})
+}
+
+// predictHoltWinters returns, for each series, the value predicted at horizon after the evaluation
+// time, using double exponential smoothing (Holt-Winters without seasonality) over the points of the
+// series. The smoothing factor sf and the trend factor tf must be between 0 and 1. Lower factors
+// give more importance to old points.
+func predictHoltWinters(e *State, varSet Results, horizon time.Duration, sfSet Results, tfSet Results) (Results, error) {
+	sf, err := scalarArg(sfSet)
+	if err != nil {
+		return Results{}, fmt.Errorf("predict_holt_winters: smoothing factor: %w", err)
+	}
+	tf, err := scalarArg(tfSet)
+	if err != nil {
+		return Results{}, fmt.Errorf("predict_holt_winters: trend factor: %w", err)
+	}
+	if sf <= 0 || sf >= 1 {
+		return Results{}, fmt.Errorf("predict_holt_winters: smoothing factor must be between 0 and 1, got %v", sf)
+	}
+	if tf <= 0 || tf >= 1 {
+		return Results{}, fmt.Errorf("predict_holt_winters: trend factor must be between 0 and 1, got %v", tf)
+	}
+
+	return perSeriesNumber(e, "predict_holt_winters", varSet, func(s Series) *float64 {
+		times, values := forecastPoints(s)
+		if len(values) < 2 {
+			return nil
+		}
+		// The trend is per second so that series with missing points or irregular
+		// intervals are handled.
+		level := values[0]
+		var trend float64
+		if dt := times[1].Sub(times[0]).Seconds(); dt > 0 {
+			trend = (values[1] - values[0]) / dt
+		}
+		for i := 1; i < len(values); i++ {
+			dt := times[i].Sub(times[i-1]).Seconds()
+			if dt <= 0 {
+				continue
+			}
+			prevLevel := level
+			level = sf*values[i] + (1-sf)*(level+trend*dt)
+			trend = tf*(level-prevLevel)/dt + (1-tf)*trend
+		}
+
+		last := times[len(times)-1]
+		f := level + trend*forecastTarget(e, times, horizon).Sub(last).Seconds()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		return &f
+	})
+}
+
+// forecastPoints returns the times and values of the points of the series that are numbers,
+// ordered by time.
+func forecastPoints(s Series) ([]time.Time, []float64) {
+	sorted := NewSeries("", nil, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		sorted.SetPoint(i, t, f)
+	}
+	sorted.SortByTime(false)
+
+	times := make([]time.Time, 0, sorted.Len())
+	values := make([]float64, 0, sorted.Len())
+	for i := 0; i < sorted.Len(); i++ {
+		t, f := sorted.GetPoint(i)
+		if f == nil || math.IsNaN(*f) || math.IsInf(*f, 0) {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, *f)
+	}
+	return times, values
+}
+
+// forecastTarget returns the time to predict the value at, which is horizon after the evaluation
+// time, or after the last point if the evaluation time is not known.
+func forecastTarget(e *State, times []time.Time, horizon time.Duration) time.Time {
+	if e.Now.IsZero() {
+		return times[len(times)-1].Add(horizon)
+	}
+	return e.Now.Add(horizon)
+}
+
+// perSeriesNumber reduces each series of varSet to a Number with seriesF.
+// NoData is passed through and other types of values are an error.
+func perSeriesNumber(e *State, name string, varSet Results, seriesF func(s Series) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			n := NewNumber(e.RefID, v.GetLabels())
+			n.SetValue(seriesF(v))
+			newRes.Values = append(newRes.Values, n)
+		case NoData:
+			newRes.Values = append(newRes.Values, NoData{}.New())
+		default:
+			return newRes, fmt.Errorf("%s: expected %v, got %v", name, parse.TypeSeriesSet, res.Type())
+		}
+	}
+	return newRes, nil
+}
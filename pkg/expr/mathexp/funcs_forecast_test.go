@@ -0,0 +1,93 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastFuncs(t *testing.T) {
+	now := time.Unix(100, 0)
+	vars := Vars{
+		"A": Results{
+			[]Value{
+				// 2 per second
+				makeSeries("", data.Labels{"disk": "a"},
+					tp{time.Unix(70, 0), float64Pointer(40)},
+					tp{time.Unix(80, 0), float64Pointer(60)},
+					tp{time.Unix(90, 0), nil},
+					tp{time.Unix(100, 0), float64Pointer(100)},
+				),
+				makeSeries("", data.Labels{"disk": "b"},
+					tp{time.Unix(100, 0), float64Pointer(1)},
+				),
+			},
+		},
+		"B": Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "predict_linear predicts the value after the horizon",
+			expr:      `predict_linear($A, "1m")`,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"disk": "a"}, float64Pointer(220)),
+					makeNumber("", data.Labels{"disk": "b"}, nil),
+				},
+			},
+		},
+		{
+			name:      "predict_holt_winters follows a linear trend",
+			expr:      `predict_holt_winters($A, "1m", 0.5, 0.5)`,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"disk": "a"}, float64Pointer(220)),
+					makeNumber("", data.Labels{"disk": "b"}, nil),
+				},
+			},
+		},
+		{
+			name:      "predict_holt_winters with invalid factor fails",
+			expr:      `predict_holt_winters($A, "1m", 1, 0.5)`,
+			execErrIs: require.Error,
+			results:   Results{},
+		},
+		{
+			name:      "predict_linear on number fails",
+			expr:      `predict_linear($B, "1m")`,
+			execErrIs: require.Error,
+			results:   Results{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars, now)
+			tt.execErrIs(t, err)
+			if err == nil {
+				require.Len(t, res.Values, len(tt.results.Values))
+				for i, v := range res.Values {
+					expected := tt.results.Values[i].(Number)
+					actual := v.(Number)
+					require.Equal(t, expected.GetLabels(), actual.GetLabels())
+					if expected.GetFloat64Value() == nil {
+						require.Nil(t, actual.GetFloat64Value())
+						continue
+					}
+					require.InDelta(t, *expected.GetFloat64Value(), *actual.GetFloat64Value(), 0.0001)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package mathexp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// labelReplace works like label_replace in Prometheus. For each Number or Series whose
+// src label matches the regex, the dst label is set to the replacement, in which
+// $1, $2 etc. are replaced with the capture groups of the regex. If the replacement
+// is empty, the dst label is removed. Values that do not match are returned unchanged.
+func labelReplace(e *State, varSet Results, dst, replacement, src, regex string) (Results, error) {
+	re, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return Results{}, fmt.Errorf("label_replace: invalid regular expression %q: %w", regex, err)
+	}
+	if dst == "" {
+		return Results{}, fmt.Errorf("label_replace: destination label name must not be empty")
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		labels := res.GetLabels()
+		matches := re.FindStringSubmatchIndex(labels[src])
+		if matches == nil {
+			newRes.Values = append(newRes.Values, withLabels(e, res, labels))
+			continue
+		}
+		newLabels := labels.Copy()
+		if newLabels == nil {
+			newLabels = data.Labels{}
+		}
+		value := re.ExpandString(nil, replacement, labels[src], matches)
+		if len(value) == 0 {
+			delete(newLabels, dst)
+		} else {
+			newLabels[dst] = string(value)
+		}
+		newRes.Values = append(newRes.Values, withLabels(e, res, newLabels))
+	}
+	return newRes, nil
+}
+
+// labelDrop removes the labels in the comma separated list names from each Number or Series.
+func labelDrop(e *State, varSet Results, names string) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newLabels := res.GetLabels().Copy()
+		for _, name := range strings.Split(names, ",") {
+			delete(newLabels, strings.TrimSpace(name))
+		}
+		newRes.Values = append(newRes.Values, withLabels(e, res, newLabels))
+	}
+	return newRes, nil
+}
+
+// withLabels returns a copy of the Number or Series val with the given labels.
+// Other values are returned as they are since they have no labels.
+func withLabels(e *State, val Value, labels data.Labels) Value {
+	switch v := val.(type) {
+	case Number:
+		n := NewNumber(e.RefID, labels)
+		n.SetValue(v.GetFloat64Value())
+		return n
+	case Series:
+		newSeries := NewSeries(e.RefID, labels, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			t, f := v.GetPoint(i)
+			newSeries.SetPoint(i, t, f)
+		}
+		return newSeries
+	default:
+		return val
+	}
+}
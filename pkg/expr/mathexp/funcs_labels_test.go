@@ -0,0 +1,76 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelFuncs(t *testing.T) {
+	vars := Vars{
+		"A": Results{
+			[]Value{
+				makeNumber("", data.Labels{"instance": "host-1:9090", "job": "api"}, float64Pointer(1)),
+				makeSeries("", data.Labels{"instance": "other", "job": "db"}, tp{time.Unix(5, 0), float64Pointer(2)}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "label_replace sets dst from capture group of matching values",
+			expr:      `label_replace($A, "host", "$1", "instance", "(.*):.*")`,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"instance": "host-1:9090", "job": "api", "host": "host-1"}, float64Pointer(1)),
+					makeSeries("", data.Labels{"instance": "other", "job": "db"}, tp{time.Unix(5, 0), float64Pointer(2)}),
+				},
+			},
+		},
+		{
+			name:      "label_replace with empty replacement removes dst",
+			expr:      `label_replace($A, "job", "", "job", "api")`,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{"instance": "host-1:9090"}, float64Pointer(1)),
+					makeSeries("", data.Labels{"instance": "other", "job": "db"}, tp{time.Unix(5, 0), float64Pointer(2)}),
+				},
+			},
+		},
+		{
+			name:      "label_replace with invalid regex fails",
+			expr:      `label_replace($A, "host", "$1", "instance", "(")`,
+			execErrIs: require.Error,
+			results:   Results{},
+		},
+		{
+			name:      "label_drop removes all listed labels",
+			expr:      `label_drop($A, "instance, job")`,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeNumber("", data.Labels{}, float64Pointer(1)),
+					makeSeries("", data.Labels{}, tp{time.Unix(5, 0), float64Pointer(2)}),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars, time.Now())
+			tt.execErrIs(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// clampMin returns the value for each result in NumberSet, SeriesSet, or Scalar raised to min if it is lower.
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	minF, err := scalarArg(minSet)
+	if err != nil {
+		return Results{}, fmt.Errorf("clamp_min: %w", err)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(f, minF)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clampMax returns the value for each result in NumberSet, SeriesSet, or Scalar lowered to max if it is higher.
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	maxF, err := scalarArg(maxSet)
+	if err != nil {
+		return Results{}, fmt.Errorf("clamp_max: %w", err)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Min(f, maxF)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// rate returns the per-second rate of increase between consecutive points of each series.
+// A value that is lower than the previous one is considered a counter reset.
+// The first point of each series is dropped since it has no previous point.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			prevT, prevF := s.GetPoint(i - 1)
+			t, f := s.GetPoint(i)
+			seconds := t.Sub(prevT).Seconds()
+			if prevF == nil || f == nil || seconds <= 0 {
+				newSeries.AppendPoint(t, nil)
+				continue
+			}
+			nF := counterIncrease(*prevF, *f) / seconds
+			newSeries.AppendPoint(t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// delta returns the difference between consecutive points of each series, which is negative when the value decreases,
+// like delta in PromQL. Unlike the Delta reducer, it does not handle counter resets: use rate for counters.
+// The first point of each series is dropped since it has no previous point.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			_, prevF := s.GetPoint(i - 1)
+			t, f := s.GetPoint(i)
+			if prevF == nil || f == nil {
+				newSeries.AppendPoint(t, nil)
+				continue
+			}
+			nF := *f - *prevF
+			newSeries.AppendPoint(t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// movingAvg returns, for each point of each series, the average of the non-null
+// values within the window that ends at the point.
+func movingAvg(e *State, varSet Results, window time.Duration) (Results, error) {
+	if window <= 0 {
+		return Results{}, fmt.Errorf("moving_avg: window must be greater than zero, got %v", window)
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			for start < i && !s.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+			var sum float64
+			var count int
+			for j := start; j <= i; j++ {
+				f := s.GetValue(j)
+				if f == nil || math.IsNaN(*f) {
+					continue
+				}
+				sum += *f
+				count++
+			}
+			if count == 0 {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			avg := sum / float64(count)
+			newSeries.SetPoint(i, t, &avg)
+		}
+		return newSeries, nil
+	})
+}
+
+// shift moves the time of every point of each series forward by the duration,
+// so a series can be compared with itself in the past, for example $A / shift($A, "1w").
+func shift(e *State, varSet Results, d time.Duration) (Results, error) {
+	return perSeries(e, "shift", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries, nil
+	})
+}
+
+// fill replaces null and NaN values of each series either with a constant,
+// or with the previous non-null value when the argument is "previous".
+func fill(e *State, varSet Results, fillWith interface{}) (Results, error) {
+	var fillF *float64
+	switch v := fillWith.(type) {
+	case string:
+		if v != "previous" {
+			return Results{}, fmt.Errorf(`fill: expected a number or "previous", got %q`, v)
+		}
+	case Results:
+		f, err := scalarArg(v)
+		if err != nil {
+			return Results{}, fmt.Errorf("fill: %w", err)
+		}
+		fillF = &f
+	default:
+		return Results{}, fmt.Errorf(`fill: expected a number or "previous", got %T`, fillWith)
+	}
+	return perSeries(e, "fill", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var previous *float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f != nil && !math.IsNaN(*f) {
+				previous = f
+				newSeries.SetPoint(i, t, f)
+				continue
+			}
+			if fillF != nil {
+				newSeries.SetPoint(i, t, fillF)
+				continue
+			}
+			newSeries.SetPoint(i, t, previous)
+		}
+		return newSeries, nil
+	})
+}
+
+// timeNow returns the number of seconds since the epoch of the time the expression is evaluated at.
+func timeNow(e *State) Results {
+	f := float64(e.Now.UnixNano()) / float64(time.Second)
+	return NewScalarResults(e.RefID, &f)
+}
+
+// hour returns the hour of the day, from 0 to 23 in UTC, of the time the expression is evaluated at.
+func hour(e *State) Results {
+	f := float64(e.Now.UTC().Hour())
+	return NewScalarResults(e.RefID, &f)
+}
+
+// dayOfWeek returns the day of the week, from 0 (Sunday) to 6 in UTC, of the time the expression is evaluated at.
+func dayOfWeek(e *State) Results {
+	f := float64(e.Now.UTC().Weekday())
+	return NewScalarResults(e.RefID, &f)
+}
+
+// perSeries calls seriesF for each Series in varSet. NoData is passed through,
+// any other type results in an error since the function needs the time of each point.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newSeries, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, NoData{}.New())
+		default:
+			return newRes, fmt.Errorf("%s: expected %v, got %v", name, parse.TypeSeriesSet, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a function argument that must be a single non-null scalar.
+func scalarArg(res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, fmt.Errorf("expected a single scalar, got %v values", len(res.Values))
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("expected %v, got %v", parse.TypeScalar, res.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("expected a number, got null")
+	}
+	return *f, nil
+}
@@ -0,0 +1,187 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeAwareFuncs(t *testing.T) {
+	counter := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(30)},
+					tp{time.Unix(30, 0), float64Pointer(5)},
+				),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "rate handles nulls and counter resets",
+			expr:      "rate($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(0.5)},
+			)}},
+		},
+		{
+			name:      "delta returns difference between points",
+			expr:      "delta($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(-25)},
+			)}},
+		},
+		{
+			name:      "moving_avg skips nulls within the window",
+			expr:      `moving_avg($A, "20s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(10)},
+				tp{time.Unix(20, 0), float64Pointer(30)},
+				tp{time.Unix(30, 0), float64Pointer(17.5)},
+			)}},
+		},
+		{
+			name:     "moving_avg with invalid duration fails to parse",
+			expr:     `moving_avg($A, "soon")`,
+			vars:     counter,
+			newErrIs: require.Error,
+		},
+		{
+			name:      "shift moves points forward",
+			expr:      `shift($A, "1m")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(60, 0), float64Pointer(10)},
+				tp{time.Unix(70, 0), nil},
+				tp{time.Unix(80, 0), float64Pointer(30)},
+				tp{time.Unix(90, 0), float64Pointer(5)},
+			)}},
+		},
+		{
+			name:      "fill with a value",
+			expr:      "fill($A, 0)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(0)},
+				tp{time.Unix(20, 0), float64Pointer(30)},
+				tp{time.Unix(30, 0), float64Pointer(5)},
+			)}},
+		},
+		{
+			name:      "fill with previous",
+			expr:      `fill($A, "previous")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(10)},
+				tp{time.Unix(20, 0), float64Pointer(30)},
+				tp{time.Unix(30, 0), float64Pointer(5)},
+			)}},
+		},
+		{
+			name:      "fill with unknown mode",
+			expr:      `fill($A, "next")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+			results:   Results{},
+		},
+		{
+			name: "clamp_min and clamp_max on number",
+			expr: "clamp_max(clamp_min($A, 0), 10)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(-3))}},
+				"B": Results{[]Value{makeNumber("", nil, float64Pointer(42))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(0))}},
+		},
+		{
+			name:      "clamp_max on scalar",
+			expr:      "clamp_max(42, 10)",
+			vars:      Vars{},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NewScalar("", float64Pointer(10))}},
+		},
+		{
+			name: "rate of a number fails",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+			results:   Results{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, time.Now())
+				tt.execErrIs(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestTimeScalarFuncs(t *testing.T) {
+	// Saturday, 2022-10-15 14:30:00 UTC
+	now := time.Date(2022, 10, 15, 14, 30, 0, 0, time.UTC)
+
+	var tests = []struct {
+		expr   string
+		result float64
+	}{
+		{expr: "time()", result: float64(now.Unix())},
+		{expr: "hour()", result: 14},
+		{expr: "day_of_week()", result: 6},
+		{expr: "hour() >= 9 && hour() < 17 && day_of_week() > 0 && day_of_week() < 6", result: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", Vars{}, now)
+			require.NoError(t, err)
+			require.Equal(t, Results{[]Value{NewScalar("", float64Pointer(tt.result))}}, res)
+		})
+	}
+}
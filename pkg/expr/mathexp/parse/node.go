@@ -9,6 +9,8 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +64,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeDuration is a duration constant such as "5m".
+	NodeDuration
 )
 
 // String returns the string representation of the NodeType
@@ -77,6 +81,8 @@ func (t NodeType) String() string {
 		return "NodeString"
 	case NodeNumber:
 		return "NodeNumber"
+	case NodeDuration:
+		return "NodeDuration"
 	default:
 		return "NodeUnknown"
 	}
@@ -177,6 +183,10 @@ func (f *FuncNode) Check(t *Tree) error {
 			if !(argType == TypeNumberSet || argType == TypeSeriesSet || argType == TypeScalar) {
 				return fmt.Errorf("parse: expected %v or %v for argument %v, got %v", TypeNumberSet, TypeSeriesSet, i, argType)
 			}
+		} else if funcType == TypeScalarOrString {
+			if !(argType == TypeScalar || argType == TypeString) {
+				return fmt.Errorf("parse: expected %v or %v for argument %v, got %v", TypeScalar, TypeString, i, argType)
+			}
 		} else if funcType != argType {
 			return fmt.Errorf("parse: expected %v, got %v for argument %v (%v)", funcType, argType, i, arg.String())
 		}
@@ -291,6 +301,78 @@ func (s *StringNode) Return() ReturnType {
 	return TypeString
 }
 
+// DurationNode holds a duration constant, such as "5m", that is passed to a function.
+type DurationNode struct {
+	NodeType
+	Pos
+	Quoted   string        // The original text of the duration, with quotes.
+	Duration time.Duration // The parsed duration.
+}
+
+func newDuration(pos Pos, orig string, d time.Duration) *DurationNode {
+	return &DurationNode{NodeType: NodeDuration, Pos: pos, Quoted: orig, Duration: d}
+}
+
+// String returns the string representation of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) String() string {
+	return d.Quoted
+}
+
+// StringAST returns the string representation of abstract syntax tree of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) StringAST() string {
+	return d.String()
+}
+
+// Check performs parse time checking on the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Check(*Tree) error {
+	return nil
+}
+
+// Return returns the result type of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Return() ReturnType {
+	return TypeDuration
+}
+
+// VectorMatchCardinality describes how the items of the two sides of a binary operation are matched.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches each item on one side with at most one item on the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many items on the left side with one item on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item on the left side with many items on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching holds the on/ignoring and group_left/group_right modifiers of a binary operation.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true if MatchingLabels are the only labels used for matching (on),
+	// and false if they are excluded from matching (ignoring).
+	On             bool
+	MatchingLabels []string
+	// Include holds the labels of the "one" side that are copied to the result
+	// of a many-to-one or one-to-many matching.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left(" + strings.Join(m.Include, ", ") + ")"
+	case CardOneToMany:
+		s += " group_right(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
+}
+
 // BinaryNode holds two arguments and an operator.
 type BinaryNode struct {
 	NodeType
@@ -298,6 +380,8 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// VectorMatching is nil if the operation has no matching modifiers.
+	VectorMatching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,6 +390,9 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.VectorMatching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
@@ -378,7 +465,7 @@ func Walk(n Node, f func(Node)) {
 		for _, a := range n.Args {
 			Walk(a, f)
 		}
-	case *ScalarNode, *StringNode:
+	case *ScalarNode, *StringNode, *DurationNode:
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
@@ -403,6 +490,12 @@ const (
 	TypeVariantSet
 	// TypeNoData is a no data response without a known data type.
 	TypeNoData
+	// TypeTableData is a tabular data frame that is neither a number set nor a series set.
+	TypeTableData
+	// TypeDuration is a duration constant, such as the window of a function.
+	TypeDuration
+	// TypeScalarOrString is a function argument that can be either a scalar or a string constant.
+	TypeScalarOrString
 )
 
 // String returns a string representation of the ReturnType.
@@ -420,6 +513,12 @@ func (f ReturnType) String() string {
 		return "variant"
 	case TypeNoData:
 		return "noData"
+	case TypeTableData:
+		return "tableData"
+	case TypeDuration:
+		return "duration"
+	case TypeScalarOrString:
+		return "scalarOrString"
 	default:
 		return "unknown"
 	}
@@ -12,6 +12,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 )
 
 // Tree is the representation of a single parsed expression.
@@ -165,16 +167,18 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
-param -> number | "string" | queryVar
+param -> number | "string" | "duration" | queryVar
+matching -> ( "on" | "ignoring" ) labels [( "group_left" | "group_right" ) [labels]]
+labels -> "(" [label {"," label}] ")"
 */
 
 // expr:
@@ -185,7 +189,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +202,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +215,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +228,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +241,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +254,82 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary consumes the operator and the optional vector matching modifiers
+// and returns a BinaryNode with lhs and the node returned by rhs.
+func (t *Tree) binary(lhs Node, rhs func() Node) Node {
+	operator := t.next()
+	matching := t.vectorMatching()
+	b := newBinary(operator, lhs, rhs())
+	b.VectorMatching = matching
+	return b
+}
+
+// vectorMatching parses the optional on(...)/ignoring(...) and group_left(...)/group_right(...)
+// modifiers of a binary operation. It returns nil if there are none.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	matching := &VectorMatching{
+		Card:           CardOneToOne,
+		On:             token.val == "on",
+		MatchingLabels: t.labelList(token.val),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return matching
+	}
+	t.next()
+	matching.Card = CardManyToOne
+	if token.val == "group_right" {
+		matching.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		matching.Include = t.labelList(token.val)
+	}
+	return matching
+}
+
+// labelList parses a parenthesized, comma separated list of label names.
+// Label names can be written as is or quoted.
+func (t *Tree) labelList(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -328,7 +401,20 @@ func (t *Tree) Func() (f *FuncNode) {
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
+			// strings are parsed as durations when the function expects a duration in that position
+			if argIdx := len(f.Args); argIdx < len(f.F.Args) && f.F.Args[argIdx] == TypeDuration {
+				d, err := gtime.ParseDuration(s)
+				if err != nil {
+					t.errorf("invalid duration %s for argument %v of %s: %s", token.val, argIdx, f.Name, err)
+				}
+				f.append(newDuration(token.pos, token.val, d))
+				continue
+			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
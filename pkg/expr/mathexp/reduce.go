@@ -3,6 +3,8 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -10,6 +12,10 @@ import (
 
 type ReducerFunc = func(fv *Float64Field) *float64
 
+// SeriesReducerFunc is a reduction function that needs the time of each point
+// as well as the values of the series.
+type SeriesReducerFunc = func(s Series) *float64
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -81,8 +87,163 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// numericValues returns the values of the field, or false if any of them is null or NaN.
+func numericValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// Percentile returns a reduction function that calculates the p-th percentile
+// of the values using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := numericValues(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		f := percentile(values, p)
+		return &f
+	}
+}
+
+// percentile returns the p-th percentile of the values, which must not be empty. It sorts the values in place.
+func percentile(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Stddev returns the population standard deviation of the values.
+func Stddev(fv *Float64Field) *float64 {
+	values, ok := numericValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	_, f := meanStddev(values)
+	return &f
+}
+
+// meanStddev returns the mean and the population standard deviation of the values, which must not be empty.
+func meanStddev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	values, ok := numericValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := values[len(values)-1] - values[0]
+	return &f
+}
+
+// PercentDiff returns the difference between the last and the first value
+// as a percentage of the first value.
+func PercentDiff(fv *Float64Field) *float64 {
+	values, ok := numericValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := (values[len(values)-1] - values[0]) / math.Abs(values[0]) * 100
+	return &f
+}
+
+// Delta returns the increase of a counter over the series. A value that is lower
+// than the previous one is considered a counter reset.
+func Delta(fv *Float64Field) *float64 {
+	values, ok := numericValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var f float64
+	for i := 1; i < len(values); i++ {
+		f += counterIncrease(values[i-1], values[i])
+	}
+	return &f
+}
+
+// counterIncrease returns the increase of a counter between two consecutive values.
+// A value that is lower than the previous one is considered a counter reset, so the
+// increase is the value itself.
+func counterIncrease(previous, current float64) float64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// Rate returns the per-second increase of a counter over the series,
+// which is Delta divided by the seconds between the first and the last point.
+func Rate(s Series) *float64 {
+	nan := math.NaN()
+	if s.Len() < 2 {
+		return &nan
+	}
+	seconds := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if seconds <= 0 {
+		return &nan
+	}
+	ff := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+	delta := Delta(&ff)
+	f := *delta / seconds
+	return &f
+}
+
+// parsePercentile returns the percentile of a reducer with the format pNN, such as p95 or p99.9.
+func parsePercentile(rFunc string) (float64, bool) {
+	if len(rFunc) < 2 || rFunc[0] != 'p' {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(rFunc[1:], 64)
+	if err != nil || p < 0 || p > 100 || math.IsNaN(p) {
+		return 0, false
+	}
+	return p, true
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
+	rFunc = strings.ToLower(rFunc)
+	if p, ok := parsePercentile(rFunc); ok {
+		return Percentile(p), nil
+	}
+	switch rFunc {
 	case "sum":
 		return Sum, nil
 	case "mean":
@@ -95,14 +256,44 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return Stddev, nil
+	case "diff":
+		return Diff, nil
+	case "percent_diff":
+		return PercentDiff, nil
+	case "delta":
+		return Delta, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// GetSeriesReduceFunc returns the reduction function for rFunc, including the
+// functions that need the time of each point such as rate.
+func GetSeriesReduceFunc(rFunc string) (SeriesReducerFunc, error) {
+	if strings.ToLower(rFunc) == "rate" {
+		return Rate, nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Series) *float64 {
+		ff := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+		return reduceFunc(&ff)
+	}, nil
+}
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Any percentile can be used with the pNN format, the most common ones are listed.
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "median", "stddev",
+		"p50", "p90", "p95", "p99", "diff", "percent_diff", "delta", "rate"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
@@ -119,13 +310,11 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -266,6 +266,66 @@ func TestSeriesReduce(t *testing.T) {
 	}
 }
 
+func TestSeriesReduceExtendedFuncs(t *testing.T) {
+	counter := makeSeries("requests", nil,
+		tp{time.Unix(0, 0), float64Pointer(10)},
+		tp{time.Unix(10, 0), float64Pointer(20)},
+		tp{time.Unix(20, 0), float64Pointer(5)}, // counter reset
+		tp{time.Unix(30, 0), float64Pointer(15)},
+		tp{time.Unix(40, 0), float64Pointer(40)},
+	)
+
+	var tests = []struct {
+		name     string
+		red      string
+		series   Series
+		expected *float64
+	}{
+		{name: "first", red: "first", series: counter, expected: float64Pointer(10)},
+		{name: "median", red: "median", series: counter, expected: float64Pointer(15)},
+		{name: "p50 is the median", red: "p50", series: counter, expected: float64Pointer(15)},
+		{name: "p90 interpolates between ranks", red: "p90", series: counter, expected: float64Pointer(32)},
+		{name: "p100 is the maximum", red: "p100", series: counter, expected: float64Pointer(40)},
+		{name: "p0 is the minimum", red: "p0", series: counter, expected: float64Pointer(5)},
+		{name: "stddev", red: "stddev", series: counter, expected: float64Pointer(math.Sqrt(146))},
+		{name: "diff", red: "diff", series: counter, expected: float64Pointer(30)},
+		{name: "percent_diff", red: "percent_diff", series: counter, expected: float64Pointer(300)},
+		{name: "delta handles counter resets", red: "delta", series: counter, expected: float64Pointer(50)},
+		{name: "rate handles counter resets", red: "rate", series: counter, expected: float64Pointer(50.0 / 40)},
+		{name: "median with nil value", red: "median", series: seriesWithNil["A"].Values[0].(Series), expected: NaN},
+		{name: "stddev of empty series", red: "stddev", series: seriesEmpty["A"].Values[0].(Series), expected: NaN},
+		{name: "rate of a single point", red: "rate", series: makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}), expected: NaN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num, err := tt.series.Reduce("", tt.red, nil)
+			require.NoError(t, err)
+			actual := num.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(*tt.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.InDelta(t, *tt.expected, *actual, 1e-9)
+		})
+	}
+
+	t.Run("should reject invalid percentiles", func(t *testing.T) {
+		for _, red := range []string{"p101", "p-1", "pfoo", "p"} {
+			_, err := GetReduceFunc(red)
+			require.Error(t, err, red)
+		}
+	})
+
+	t.Run("should support all reported functions", func(t *testing.T) {
+		for _, red := range GetSupportedReduceFuncs() {
+			_, err := GetSeriesReduceFunc(red)
+			require.NoError(t, err, red)
+		}
+	})
+}
+
 var seriesNonNumbers = Vars{
 	"A": Results{
 		[]Value{
@@ -2,6 +2,7 @@ package mathexp
 
 import (
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
@@ -268,3 +269,113 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatching(t *testing.T) {
+	containers := Vars{
+		"A": Results{
+			Values: Values{
+				makeNumber("", data.Labels{"pod": "p1", "container": "app"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"pod": "p1", "container": "sidecar"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"pod": "p2", "container": "app"}, float64Pointer(3)),
+			},
+		},
+		"B": Results{
+			Values: Values{
+				makeNumber("", data.Labels{"pod": "p1", "team": "red"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"pod": "p2", "team": "blue"}, float64Pointer(20)),
+			},
+		},
+		"C": Results{
+			Values: Values{
+				makeNumber("", data.Labels{"pod": "p1", "container": "app"}, float64Pointer(100)),
+				makeNumber("", data.Labels{"pod": "p2", "container": "app"}, float64Pointer(200)),
+			},
+		},
+		"D": Results{
+			Values: Values{
+				makeNumber("", data.Labels{"pod": "p1"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"pod": "p2"}, float64Pointer(2)),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "on with group_left matches many containers to one pod and copies labels",
+			expr:      "$A + on(pod) group_left(team) $B",
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"pod": "p1", "container": "app", "team": "red"}, float64Pointer(11)),
+					makeNumber("", data.Labels{"pod": "p1", "container": "sidecar", "team": "red"}, float64Pointer(12)),
+					makeNumber("", data.Labels{"pod": "p2", "container": "app", "team": "blue"}, float64Pointer(23)),
+				},
+			},
+		},
+		{
+			name:      "group_right matches one pod to many containers",
+			expr:      "$B * on(pod) group_right $A",
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"pod": "p1", "container": "app"}, float64Pointer(10)),
+					makeNumber("", data.Labels{"pod": "p1", "container": "sidecar"}, float64Pointer(20)),
+					makeNumber("", data.Labels{"pod": "p2", "container": "app"}, float64Pointer(60)),
+				},
+			},
+		},
+		{
+			name:      "ignoring matches one-to-one without the ignored labels",
+			expr:      `$C - ignoring("container") $D`,
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"pod": "p1"}, float64Pointer(99)),
+					makeNumber("", data.Labels{"pod": "p2"}, float64Pointer(198)),
+				},
+			},
+		},
+		{
+			name:      "on matches one-to-one and keeps only the matching labels",
+			expr:      "$C - on(pod) $B",
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"pod": "p1"}, float64Pointer(90)),
+					makeNumber("", data.Labels{"pod": "p2"}, float64Pointer(180)),
+				},
+			},
+		},
+		{
+			name:      "one-to-one matching with multiple matches fails",
+			expr:      "$A + on(pod) $B",
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "scalars ignore matching",
+			expr:      "$B + on(pod) 1",
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"pod": "p1", "team": "red"}, float64Pointer(11)),
+					makeNumber("", data.Labels{"pod": "p2", "team": "blue"}, float64Pointer(21)),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			assert.NoError(t, err)
+			res, err := e.Execute("", containers, time.Now())
+			tt.execErrIs(t, err)
+			assert.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -121,6 +121,10 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeSQL:
+		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -148,6 +152,12 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// isInputToSQLExpr is true if a SQL expression consumes the results of the query, and isInputToOtherExpr if
+	// another expression does. If only SQL expressions consume them, the frames that are not time series are kept as
+	// tables instead of failing the query. Otherwise, the results are converted for the other expressions as usual.
+	isInputToSQLExpr   bool
+	isInputToOtherExpr bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -281,6 +291,12 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 				logger.Warn("ignoring InfluxDB data frame due to missing numeric fields", "frame", frame)
 				continue
 			}
+			// Frames that are not time series, such as tables from SQL data sources,
+			// are kept as they are when only SQL expressions use them.
+			if dn.isInputToSQLExpr && !dn.isInputToOtherExpr && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot {
+				vals = append(vals, mathexp.TableData{Frame: frame})
+				continue
+			}
 			series, err := WideToMany(frame)
 			if err != nil {
 				return mathexp.Results{}, err
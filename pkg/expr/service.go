@@ -57,6 +57,20 @@ func (s *Service) isDisabled() bool {
 	return !s.cfg.ExpressionsEnabled
 }
 
+func (s *Service) maxConcurrentQueries() int {
+	if s.cfg == nil {
+		return 0
+	}
+	return s.cfg.ExpressionsMaxConcurrentQueries
+}
+
+func (s *Service) queryTimeout() time.Duration {
+	if s.cfg == nil {
+		return 0
+	}
+	return s.cfg.ExpressionsQueryTimeout
+}
+
 // BuildPipeline builds a pipeline from a request.
 func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 	return s.buildPipeline(req)
@@ -93,6 +93,139 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceDebug(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+
+	s := Service{
+		cfg:               setting.NewCfg(),
+		dataService:       &mockEndpoint{Frames: []*data.Frame{dsDF}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+
+	dsQuery := Query{
+		RefID: "A",
+		DataSource: &datasources.DataSource{
+			OrgId: 1,
+			Uid:   "test",
+			Type:  "test",
+		},
+		JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		TimeRange: AbsoluteTimeRange{
+			From: time.Time{},
+			To:   time.Time{},
+		},
+	}
+
+	t.Run("returns the execution trace of every node", func(t *testing.T) {
+		req := &Request{Queries: []Query{
+			dsQuery,
+			{
+				RefID:      "B",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+			{
+				RefID:      "C",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "reducer": "last", "expression": "B" }`),
+			},
+		}}
+		pl, err := s.BuildPipeline(req)
+		require.NoError(t, err)
+
+		res, err := s.ExecutePipelineDebug(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+		require.Contains(t, res.Responses, "A")
+		require.Contains(t, res.Responses, "B")
+		require.Contains(t, res.Responses, "C")
+
+		require.Contains(t, res.Responses, DebugRefID)
+		frames := res.Responses[DebugRefID].Frames
+		require.Len(t, frames, 1)
+		trace := frames[0]
+		require.Equal(t, 3, trace.Rows())
+
+		field := func(name string) *data.Field {
+			f, _ := trace.FieldByName(name)
+			require.NotNil(t, f, name)
+			return f
+		}
+		for i, expected := range []struct {
+			refID, typ, dependsOn string
+		}{
+			{"A", "datasource", ""},
+			{"B", "math", "A"},
+			{"C", "reduce", "B"},
+		} {
+			require.Equal(t, expected.refID, field("refId").At(i))
+			require.Equal(t, expected.typ, field("type").At(i))
+			require.Equal(t, int64(i), field("order").At(i))
+			require.Equal(t, expected.dependsOn, field("dependsOn").At(i))
+			require.Equal(t, int64(1), field("frames").At(i))
+			require.Equal(t, string(NodeStatusOK), field("status").At(i))
+			require.Equal(t, "", field("error").At(i))
+		}
+	})
+
+	t.Run("skips the nodes after a failed node", func(t *testing.T) {
+		req := &Request{Queries: []Query{
+			dsQuery,
+			{
+				RefID:      "B",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+			{
+				RefID:      "C",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "reducer": "last", "expression": "B" }`),
+			},
+			{
+				RefID:      "D",
+				DataSource: DataSourceModel(),
+				// A Number cannot be resampled, so D fails.
+				JSON: json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "resample", "expression": "C", "window": "1s", "downsampler": "last", "upsampler": "pad" }`),
+				TimeRange: AbsoluteTimeRange{
+					From: time.Unix(0, 0),
+					To:   time.Unix(10, 0),
+				},
+			},
+			{
+				RefID:      "E",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$D + 1" }`),
+			},
+		}}
+		pl, err := s.BuildPipeline(req)
+		require.NoError(t, err)
+
+		res, err := s.ExecutePipelineDebug(context.Background(), time.Now(), pl)
+		require.Error(t, err)
+
+		require.NoError(t, res.Responses["B"].Error)
+		require.NotEmpty(t, res.Responses["B"].Frames)
+		require.Error(t, res.Responses["D"].Error)
+		require.NotContains(t, res.Responses, "E")
+
+		trace := res.Responses[DebugRefID].Frames[0]
+		status, _ := trace.FieldByName("status")
+		refIDs, _ := trace.FieldByName("refId")
+		statuses := map[string]string{}
+		for i := 0; i < trace.Rows(); i++ {
+			statuses[refIDs.At(i).(string)] = status.At(i).(string)
+		}
+		require.Equal(t, map[string]string{
+			"A": string(NodeStatusOK),
+			"B": string(NodeStatusOK),
+			"C": string(NodeStatusOK),
+			"D": string(NodeStatusError),
+			"E": string(NodeStatusSkipped),
+		}, statuses)
+	})
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
@@ -0,0 +1,556 @@
+package expr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	// sqlite3 is used as the in-memory engine that SQL expressions are executed with.
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// sqlRowLimit is the maximum number of rows a SQL expression can return.
+const sqlRowLimit = int64(100000)
+
+// SQLCommand is an expression command that runs a SQL SELECT statement over
+// the results of other queries or expressions. Every referenced refID is exposed
+// as an in-memory table with the same name.
+type SQLCommand struct {
+	RawSQL      string
+	varsToQuery []string
+	refID       string
+}
+
+// NewSQLCommand creates a new SQLCommand. It will return an error if the
+// statement is not a single SELECT statement or does not reference any table.
+func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+	tokens, err := tokenizeSQL(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("sql expression is empty")
+	}
+	for i, tok := range tokens {
+		if tok.kind == sqlTokenSymbol && tok.text == ";" && i != len(tokens)-1 {
+			return nil, errors.New("sql expression must contain a single statement")
+		}
+	}
+	switch first := strings.ToUpper(tokens[0].text); first {
+	case "SELECT", "WITH":
+	default:
+		return nil, fmt.Errorf("sql expression must be a SELECT statement, got %s", first)
+	}
+
+	tables := tablesFromSQL(tokens)
+	if len(tables) == 0 {
+		return nil, errors.New("sql expression must select from at least one query or expression")
+	}
+
+	return &SQLCommand{
+		RawSQL:      rawSQL,
+		varsToQuery: tables,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("command is missing an expression")
+	}
+	expressionRaw, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("sql expression is expected to be a string, got %T", rawExpr)
+	}
+
+	return NewSQLCommand(rn.RefID, expressionRaw)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *SQLCommand) NeedsVars() []string {
+	return gr.varsToQuery
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *SQLCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to create in-memory database: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Warn("failed to close in-memory database", "refId", gr.refID, "error", err)
+		}
+	}()
+	// Each connection to ":memory:" is a separate database, so all statements
+	// must run on the same connection.
+	db.SetMaxOpenConns(1)
+
+	for _, name := range gr.varsToQuery {
+		if err := createSQLTable(ctx, db, name, vars[name].Values); err != nil {
+			return mathexp.Results{}, fmt.Errorf("failed to load %s into sql expression: %w", name, err)
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, gr.RawSQL)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql expression: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("failed to close rows of sql expression", "refId", gr.refID, "error", err)
+		}
+	}()
+
+	// SQLite columns of computed values have no declared type, so the field
+	// types are detected from the returned values instead.
+	frame, err := sqlutil.FrameFromRows(rows, sqlRowLimit, sqlutil.Converter{Dynamic: true})
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to read sql expression result: %w", err)
+	}
+	frame.Name = gr.refID
+
+	return sqlFrameToResults(frame)
+}
+
+// sqlFrameToResults converts the frame returned by the SQL engine to the most
+// specific value type it can be represented as.
+func sqlFrameToResults(frame *data.Frame) (mathexp.Results, error) {
+	if frame.Rows() == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{Frame: frame}}}, nil
+	}
+
+	switch frame.TimeSeriesSchema().Type {
+	case data.TimeSeriesTypeLong:
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return mathexp.Results{}, fmt.Errorf("failed to convert sql expression result to series (results must be ordered by time): %w", err)
+		}
+		return seriesToResults(wide)
+	case data.TimeSeriesTypeWide:
+		return seriesToResults(frame)
+	}
+
+	if isNumberTable(frame) {
+		numberSet, err := extractNumberSet(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make([]mathexp.Value, 0, len(numberSet))
+		for _, n := range numberSet {
+			vals = append(vals, n)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+}
+
+func seriesToResults(frame *data.Frame) (mathexp.Results, error) {
+	series, err := WideToMany(frame)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	vals := make([]mathexp.Value, 0, len(series))
+	for _, s := range series {
+		vals = append(vals, s)
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+// sqlColumn is a column of a table created from the results of a query or expression.
+type sqlColumn struct {
+	name    string
+	sqlType string
+}
+
+// createSQLTable creates a table with the given name and inserts the values into it.
+// A single table frame is loaded as it is. Numbers, scalars and series are loaded
+// in long format with one column per label, a "value" column and, for series,
+// a "time" column.
+func createSQLTable(ctx context.Context, db *sql.DB, name string, values mathexp.Values) error {
+	var columns []sqlColumn
+	var rows [][]interface{}
+
+	if table, ok := singleTable(values); ok {
+		columns, rows = tableToSQLRows(table.Frame)
+	} else {
+		var err error
+		columns, rows, err = valuesToSQLRows(values)
+		if err != nil {
+			return err
+		}
+	}
+
+	defs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		defs = append(defs, quoteSQLIdentifier(c.name)+" "+c.sqlType)
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteSQLIdentifier(name), strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteSQLIdentifier(name), placeholders))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func singleTable(values mathexp.Values) (mathexp.TableData, bool) {
+	if len(values) != 1 {
+		return mathexp.TableData{}, false
+	}
+	table, ok := values[0].(mathexp.TableData)
+	return table, ok
+}
+
+func valuesToSQLRows(values mathexp.Values) ([]sqlColumn, [][]interface{}, error) {
+	hasTime := false
+	labelSet := map[string]struct{}{}
+	for _, v := range values {
+		switch v.(type) {
+		case mathexp.Series:
+			hasTime = true
+		case mathexp.Number, mathexp.Scalar, mathexp.NoData:
+		default:
+			return nil, nil, fmt.Errorf("can not use type %v in a sql expression together with other values", v.Type())
+		}
+		for k := range v.GetLabels() {
+			labelSet[k] = struct{}{}
+		}
+	}
+	labelKeys := make([]string, 0, len(labelSet))
+	for k := range labelSet {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	columns := make([]sqlColumn, 0, len(labelKeys)+2)
+	if hasTime {
+		columns = append(columns, sqlColumn{name: "time", sqlType: "TIMESTAMP"})
+	}
+	for _, k := range labelKeys {
+		columns = append(columns, sqlColumn{name: k, sqlType: "TEXT"})
+	}
+	columns = append(columns, sqlColumn{name: "value", sqlType: "REAL"})
+
+	newRow := func(labels data.Labels, t *time.Time, f *float64) []interface{} {
+		row := make([]interface{}, 0, len(columns))
+		if hasTime {
+			if t != nil {
+				row = append(row, t.UTC())
+			} else {
+				row = append(row, nil)
+			}
+		}
+		for _, k := range labelKeys {
+			if l, ok := labels[k]; ok {
+				row = append(row, l)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		if f != nil {
+			row = append(row, *f)
+		} else {
+			row = append(row, nil)
+		}
+		return row
+	}
+
+	var rows [][]interface{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case mathexp.Series:
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				rows = append(rows, newRow(v.GetLabels(), &t, f))
+			}
+		case mathexp.Number:
+			rows = append(rows, newRow(v.GetLabels(), nil, v.GetFloat64Value()))
+		case mathexp.Scalar:
+			rows = append(rows, newRow(nil, nil, v.GetFloat64Value()))
+		}
+	}
+	return columns, rows, nil
+}
+
+func tableToSQLRows(frame *data.Frame) ([]sqlColumn, [][]interface{}) {
+	columns := make([]sqlColumn, 0, len(frame.Fields))
+	for i, f := range frame.Fields {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("column%d", i+1)
+		}
+		columns = append(columns, sqlColumn{name: name, sqlType: sqlTypeForField(f.Type())})
+	}
+
+	rows := make([][]interface{}, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		row := make([]interface{}, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			v, ok := f.ConcreteAt(i)
+			if !ok {
+				row = append(row, nil)
+				continue
+			}
+			if t, ok := v.(time.Time); ok {
+				v = t.UTC()
+			}
+			row = append(row, v)
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+func sqlTypeForField(t data.FieldType) string {
+	switch {
+	case t == data.FieldTypeTime || t == data.FieldTypeNullableTime:
+		return "TIMESTAMP"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "BOOLEAN"
+	case t.Numeric() && (t == data.FieldTypeFloat32 || t == data.FieldTypeNullableFloat32 ||
+		t == data.FieldTypeFloat64 || t == data.FieldTypeNullableFloat64):
+		return "REAL"
+	case t.Numeric():
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+func quoteSQLIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+type sqlTokenKind int
+
+const (
+	sqlTokenIdent sqlTokenKind = iota
+	sqlTokenQuotedIdent
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenSymbol
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// tokenizeSQL splits a SQL statement into tokens. It only understands as much
+// of the language as is needed to find the tables a statement selects from.
+func tokenizeSQL(s string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			j := i + 2
+			for j+1 < len(r) && !(r[j] == '*' && r[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(r) {
+				return nil, errors.New("unterminated comment in sql expression")
+			}
+			i = j + 2
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(r); j++ {
+				if r[j] == closing {
+					// a doubled quote is an escaped quote
+					if closing != ']' && j+1 < len(r) && r[j+1] == closing {
+						sb.WriteRune(closing)
+						j++
+						continue
+					}
+					break
+				}
+				sb.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, errors.New("unterminated quote in sql expression")
+			}
+			kind := sqlTokenQuotedIdent
+			if c == '\'' {
+				kind = sqlTokenString
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: sb.String()})
+			i = j + 1
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenIdent, text: string(r[i:j])})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenNumber, text: string(r[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{kind: sqlTokenSymbol, text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// tablesFromSQL returns the names of the tables referenced after FROM and JOIN
+// keywords, excluding the names of common table expressions, table-valued
+// functions such as json_each(...), subqueries and the sqlite_* tables.
+func tablesFromSQL(tokens []sqlToken) []string {
+	isIdent := func(t sqlToken) bool {
+		return t.kind == sqlTokenIdent || t.kind == sqlTokenQuotedIdent
+	}
+	isKeyword := func(t sqlToken, kw string) bool {
+		return t.kind == sqlTokenIdent && strings.EqualFold(t.text, kw)
+	}
+	isSymbol := func(i int, sym string) bool {
+		return i < len(tokens) && tokens[i].kind == sqlTokenSymbol && tokens[i].text == sym
+	}
+	// skipParens returns the index of the token after the parenthesis that closes the one at i.
+	skipParens := func(i int) int {
+		depth := 0
+		for ; i < len(tokens); i++ {
+			switch {
+			case isSymbol(i, "("):
+				depth++
+			case isSymbol(i, ")"):
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	}
+
+	cteNames := map[string]struct{}{}
+	for i := 0; i+2 < len(tokens); i++ {
+		if isIdent(tokens[i]) && isKeyword(tokens[i+1], "AS") && isSymbol(i+2, "(") {
+			cteNames[tokens[i].text] = struct{}{}
+		}
+	}
+
+	seen := map[string]struct{}{}
+	var tables []string
+	addTable := func(t sqlToken) {
+		name := t.text
+		if _, ok := cteNames[t.text]; ok {
+			return
+		}
+		if strings.HasPrefix(strings.ToLower(name), "sqlite_") {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		tables = append(tables, name)
+	}
+	// tableSource adds the table of the source at i, unless it is a function or a subquery,
+	// and returns the index of the token after it.
+	tableSource := func(i int) (int, bool) {
+		switch {
+		case isSymbol(i, "("):
+			return skipParens(i), true
+		case i < len(tokens) && isIdent(tokens[i]):
+			if isSymbol(i+1, "(") {
+				return skipParens(i + 1), true
+			}
+			addTable(tokens[i])
+			return i + 1, true
+		}
+		return i, false
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch {
+		case isKeyword(tokens[i], "JOIN"):
+			tableSource(i + 1)
+		case isKeyword(tokens[i], "FROM"):
+			// FROM A, B AS b, C c, json_each(...), (SELECT ...) d
+			j := i + 1
+			for {
+				var ok bool
+				if j, ok = tableSource(j); !ok {
+					break
+				}
+				if j < len(tokens) && isKeyword(tokens[j], "AS") {
+					j++
+				}
+				if j < len(tokens) && isIdent(tokens[j]) && !isSQLClauseKeyword(tokens[j].text) {
+					j++
+				}
+				if !isSymbol(j, ",") {
+					break
+				}
+				j++
+			}
+		}
+	}
+	return tables
+}
+
+var sqlClauseKeywords = map[string]struct{}{
+	"WHERE": {}, "GROUP": {}, "ORDER": {}, "LIMIT": {}, "HAVING": {}, "JOIN": {}, "LEFT": {}, "RIGHT": {},
+	"INNER": {}, "OUTER": {}, "CROSS": {}, "FULL": {}, "NATURAL": {}, "ON": {}, "USING": {}, "UNION": {},
+	"EXCEPT": {}, "INTERSECT": {}, "WINDOW": {},
+}
+
+func isSQLClauseKeyword(s string) bool {
+	_, ok := sqlClauseKeywords[strings.ToUpper(s)]
+	return ok
+}
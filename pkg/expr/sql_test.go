@@ -0,0 +1,274 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewSQLCommand(t *testing.T) {
+	type testCase struct {
+		description    string
+		sql            string
+		expectedTables []string
+		expectedError  string
+	}
+
+	cases := []testCase{
+		{
+			description:    "single table",
+			sql:            "SELECT * FROM A",
+			expectedTables: []string{"A"},
+		},
+		{
+			description:    "join and comma separated tables",
+			sql:            `SELECT * FROM A a, "B" AS b LEFT JOIN C ON a.host = C.host WHERE a.value > 1`,
+			expectedTables: []string{"A", "B", "C"},
+		},
+		{
+			description:    "common table expressions are not dependencies",
+			sql:            "WITH x AS (SELECT host, max(value) AS value FROM A GROUP BY host) SELECT * FROM x JOIN B USING (host)",
+			expectedTables: []string{"A", "B"},
+		},
+		{
+			description:    "comments and strings are ignored",
+			sql:            "SELECT 'FROM C' AS s /* FROM D */ FROM A -- JOIN E",
+			expectedTables: []string{"A"},
+		},
+		{
+			description:    "table-valued functions are not dependencies",
+			sql:            "SELECT A.host, j.value FROM A, json_each(A.tags) AS j JOIN json_tree('{}') t",
+			expectedTables: []string{"A"},
+		},
+		{
+			description:    "sqlite tables are not dependencies",
+			sql:            "SELECT name FROM sqlite_master JOIN SQLITE_SCHEMA s USING (name) JOIN A ON A.host = name",
+			expectedTables: []string{"A"},
+		},
+		{
+			description:    "subqueries and their aliases are not dependencies",
+			sql:            "SELECT * FROM (SELECT host FROM A) AS sub, B JOIN (SELECT host FROM C) c ON c.host = sub.host",
+			expectedTables: []string{"B", "A", "C"},
+		},
+		{
+			description:   "not a select statement",
+			sql:           "DELETE FROM A",
+			expectedError: "must be a SELECT statement",
+		},
+		{
+			description:   "multiple statements",
+			sql:           "SELECT * FROM A; SELECT * FROM B",
+			expectedError: "single statement",
+		},
+		{
+			description:   "no tables",
+			sql:           "SELECT 1",
+			expectedError: "at least one query or expression",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cmd, err := NewSQLCommand("X", tc.sql)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedTables, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	now := time.Unix(1000, 0).UTC()
+
+	series := func(host string, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": host}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, now.Add(time.Duration(i)*time.Minute), ptr.Float64(v))
+		}
+		return s
+	}
+
+	inventory := mathexp.TableData{Frame: data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+	)}
+
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series("a", 1, 2, 3), series("b", 10, 20, 30)}},
+		"B": mathexp.Results{Values: mathexp.Values{inventory}},
+	}
+
+	t.Run("should return numbers when result has a single numeric column", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT B.team, max(A.value) AS value FROM A JOIN B ON A.host = B.host GROUP BY B.team ORDER BY B.team")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		blue, ok := res.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"team": "blue"}, blue.GetLabels())
+		require.Equal(t, 30.0, *blue.GetFloat64Value())
+
+		red, ok := res.Values[1].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"team": "red"}, red.GetLabels())
+		require.Equal(t, 3.0, *red.GetFloat64Value())
+	})
+
+	t.Run("should return series when result has a time column", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT time, host, value * 2 AS value FROM A WHERE host = 'b' ORDER BY time")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		s, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "b"}, s.GetLabels())
+		require.Equal(t, 3, s.Len())
+		ts, v := s.GetPoint(2)
+		require.Equal(t, now.Add(2*time.Minute), ts.UTC())
+		require.Equal(t, 60.0, *v)
+	})
+
+	t.Run("should return table data when result is not numeric", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT host, team FROM B")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.TableData{}, res.Values[0])
+		require.Equal(t, 2, res.Values[0].AsDataFrame().Rows())
+	})
+
+	t.Run("should return no data when there are no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT host, value FROM A WHERE value > 100")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("should fail when table does not exist", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT * FROM A JOIN D ON A.host = D.host")
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), now, vars)
+		require.Error(t, err)
+	})
+}
+
+func TestSQLExpressionTableInput(t *testing.T) {
+	inventory := data.NewFrame("inventory",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+	)
+	s := Service{
+		cfg:               setting.NewCfg(),
+		dataService:       &mockEndpoint{Frames: data.Frames{inventory}},
+		dataSourceService: &datafakes.FakeDataSourceService{},
+	}
+	dsQuery := Query{
+		RefID:      "A",
+		DataSource: &datasources.DataSource{OrgId: 1, Uid: "test", Type: "test"},
+		JSON:       json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		TimeRange:  AbsoluteTimeRange{},
+	}
+	execute := func(t *testing.T, expression Query) (*backend.QueryDataResponse, error) {
+		t.Helper()
+		pl, err := s.BuildPipeline(&Request{Queries: []Query{dsQuery, expression}})
+		require.NoError(t, err)
+		return s.ExecutePipeline(context.Background(), time.Now(), pl)
+	}
+
+	t.Run("should keep the tables consumed by a SQL expression", func(t *testing.T) {
+		res, err := execute(t, Query{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "sql", "expression": "SELECT team FROM A WHERE host = 'b'" }`),
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["B"].Error)
+		require.Len(t, res.Responses["B"].Frames, 1)
+		team, ok := res.Responses["B"].Frames[0].Fields[0].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, "blue", team)
+	})
+
+	t.Run("should fail on tables consumed by other expressions", func(t *testing.T) {
+		_, err := execute(t, Query{
+			RefID:      "B",
+			DataSource: DataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		})
+		require.ErrorContains(t, err, "input data must be a wide series")
+	})
+
+	t.Run("should fail on tables consumed by SQL and other expressions", func(t *testing.T) {
+		pl, err := s.BuildPipeline(&Request{Queries: []Query{dsQuery,
+			{
+				RefID:      "B",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "sql", "expression": "SELECT team FROM A" }`),
+			},
+			{
+				RefID:      "C",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+		}})
+		require.NoError(t, err)
+		_, err = s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.ErrorContains(t, err, "input data must be a wide series")
+	})
+
+	t.Run("should convert the numeric tables for the other expressions that consume them with SQL expressions", func(t *testing.T) {
+		numbers := data.NewFrame("numbers",
+			data.NewField("host", nil, []string{"a", "b"}),
+			data.NewField("value", nil, []float64{1, 2}),
+		)
+		s := s
+		s.dataService = &mockEndpoint{Frames: data.Frames{numbers}}
+		pl, err := s.BuildPipeline(&Request{Queries: []Query{dsQuery,
+			{
+				RefID:      "B",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "sql", "expression": "SELECT sum(value) AS total FROM A" }`),
+			},
+			{
+				RefID:      "C",
+				DataSource: DataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+		}})
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["B"].Error)
+		require.NoError(t, res.Responses["C"].Error)
+		require.Len(t, res.Responses["C"].Frames, 2)
+		total, ok := res.Responses["B"].Frames[0].Fields[0].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, float64(3), total)
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -15,6 +17,21 @@ type ThresholdCommand struct {
 	RefID         string
 	ThresholdFunc string
 	Conditions    []float64
+	// Recovery is an optional second threshold that is used instead of the first one for the
+	// series that are active, i.e. that have a pending or firing alert instance. This stops alerts
+	// from flapping when a value goes back and forth across the threshold.
+	Recovery *RecoveryThreshold
+}
+
+// RecoveryThreshold describes when an active series recovers. For example, with a threshold
+// "gt 80" and a recovery threshold "lt 70" an alert fires when the value goes above 80, but it
+// only resolves once the value drops below 70.
+type RecoveryThreshold struct {
+	ThresholdFunc string
+	Conditions    []float64
+	// ActiveLabels contains the labels of the series that were active at the previous evaluation.
+	// It is set by the alerting state manager, see SetThresholdActiveLabels.
+	ActiveLabels []data.Labels
 }
 
 const (
@@ -38,7 +55,8 @@ func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions [
 }
 
 type ThresholdConditionJSON struct {
-	Evaluator ConditionEvalJSON `json:"evaluator"`
+	Evaluator         ConditionEvalJSON  `json:"evaluator"`
+	RecoveryEvaluator *ConditionEvalJSON `json:"recoveryEvaluator,omitempty"`
 }
 
 type ConditionEvalJSON struct {
@@ -72,6 +90,9 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 		if !IsSupportedThresholdFunc(condition.Evaluator.Type) {
 			return nil, fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), condition.Evaluator.Type)
 		}
+		if condition.RecoveryEvaluator != nil && !IsSupportedThresholdFunc(condition.RecoveryEvaluator.Type) {
+			return nil, fmt.Errorf("expected recovery threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), condition.RecoveryEvaluator.Type)
+		}
 	}
 
 	// we only support one condition for now, we might want to turn this in to "OR" expressions later
@@ -80,7 +101,58 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 	firstCondition := conditions[0]
 
-	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	if err := validateThresholdParams(firstCondition.Evaluator); err != nil {
+		return nil, err
+	}
+
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstCondition.RecoveryEvaluator != nil {
+		if err := validateThresholdParams(*firstCondition.RecoveryEvaluator); err != nil {
+			return nil, fmt.Errorf("invalid recovery threshold: %w", err)
+		}
+		activeLabels, err := unmarshalActiveLabels(rawQuery[thresholdActiveLabelsKey])
+		if err != nil {
+			return nil, err
+		}
+		cmd.Recovery = &RecoveryThreshold{
+			ThresholdFunc: firstCondition.RecoveryEvaluator.Type,
+			Conditions:    firstCondition.RecoveryEvaluator.Params,
+			ActiveLabels:  activeLabels,
+		}
+	}
+
+	return cmd, nil
+}
+
+// validateThresholdParams checks that the evaluator has enough parameters for its function.
+func validateThresholdParams(evaluator ConditionEvalJSON) error {
+	required := 1
+	if evaluator.Type == ThresholdIsWithinRange || evaluator.Type == ThresholdIsOutsideRange {
+		required = 2
+	}
+	if len(evaluator.Params) < required {
+		return fmt.Errorf("threshold function %s requires %d parameters, got %d", evaluator.Type, required, len(evaluator.Params))
+	}
+	return nil
+}
+
+func unmarshalActiveLabels(raw interface{}) ([]data.Labels, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	jsonFromM, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal threshold active labels: %w", err)
+	}
+	var activeLabels []data.Labels
+	if err := json.Unmarshal(jsonFromM, &activeLabels); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal threshold active labels: %w", err)
+	}
+	return activeLabels, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -100,7 +172,43 @@ func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mat
 		return mathexp.Results{}, err
 	}
 
-	return mathCommand.Execute(ctx, now, vars)
+	results, err := mathCommand.Execute(ctx, now, vars)
+	if err != nil || tc.Recovery == nil || len(tc.Recovery.ActiveLabels) == 0 {
+		return results, err
+	}
+
+	// Active series keep firing until they meet the recovery condition.
+	recoveryExpression, err := createMathExpression(tc.ReferenceVar, tc.Recovery.ThresholdFunc, tc.Recovery.Conditions)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	recoveryCommand, err := NewMathCommand(tc.ReferenceVar, fmt.Sprintf("!(%s)", recoveryExpression))
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	recoveryResults, err := recoveryCommand.Execute(ctx, now, vars)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+
+	active := make(map[string]struct{}, len(tc.Recovery.ActiveLabels))
+	for _, labels := range tc.Recovery.ActiveLabels {
+		active[labels.String()] = struct{}{}
+	}
+	recoveryByLabels := make(map[string]mathexp.Value, len(recoveryResults.Values))
+	for _, val := range recoveryResults.Values {
+		recoveryByLabels[val.GetLabels().String()] = val
+	}
+	for i, val := range results.Values {
+		key := val.GetLabels().String()
+		if _, ok := active[key]; !ok {
+			continue
+		}
+		if recovery, ok := recoveryByLabels[key]; ok {
+			results.Values[i] = recovery
+		}
+	}
+	return results, nil
 }
 
 // createMathExpression converts all the info we have about a "threshold" expression in to a Math expression
@@ -119,6 +227,45 @@ func createMathExpression(referenceVar string, thresholdFunc string, args []floa
 	}
 }
 
+// thresholdActiveLabelsKey is the key of the query model that holds the labels of the active series.
+const thresholdActiveLabelsKey = "activeLabels"
+
+// IsThresholdWithRecovery returns true if the query model is a threshold expression
+// that has a recovery threshold, and therefore needs to know about the active series.
+func IsThresholdWithRecovery(model json.RawMessage) (bool, error) {
+	q := struct {
+		Type       string                   `json:"type"`
+		Conditions []ThresholdConditionJSON `json:"conditions"`
+	}{}
+	if err := json.Unmarshal(model, &q); err != nil {
+		return false, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if q.Type != TypeThreshold.String() {
+		return false, nil
+	}
+	for _, condition := range q.Conditions {
+		if condition.RecoveryEvaluator != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetThresholdActiveLabels returns a copy of the threshold query model with the labels of the
+// series that were active at the previous evaluation. Those series are evaluated against the
+// recovery threshold instead of the threshold.
+func SetThresholdActiveLabels(model json.RawMessage, activeLabels []data.Labels) (json.RawMessage, error) {
+	var q map[string]interface{}
+	if err := json.Unmarshal(model, &q); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if activeLabels == nil {
+		activeLabels = []data.Labels{}
+	}
+	q[thresholdActiveLabelsKey] = activeLabels
+	return json.Marshal(q)
+}
+
 func IsSupportedThresholdFunc(name string) bool {
 	isSupported := false
 
@@ -1,10 +1,15 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 func TestNewThresholdCommand(t *testing.T) {
@@ -71,6 +76,63 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 			shouldError:   true,
 			expectedError: "expected threshold function to be one of",
 		},
+		{
+			description: "unmarshal with recovery threshold",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [80]
+					},
+					"recoveryEvaluator": {
+						"type": "lt",
+						"params": [70]
+					}
+				}],
+				"activeLabels": [{"host": "a"}]
+			}`,
+			shouldError: false,
+		},
+		{
+			description: "unmarshal with unsupported recovery threshold function",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [80]
+					},
+					"recoveryEvaluator": {
+						"type": "foo",
+						"params": [70]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "expected recovery threshold function to be one of",
+		},
+		{
+			description: "unmarshal with missing range parameter",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [80]
+					},
+					"recoveryEvaluator": {
+						"type": "outside_range",
+						"params": [70]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "requires 2 parameters",
+		},
 		{
 			description: "unmarshal with bad expression",
 			query: `{
@@ -107,6 +169,90 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 	}
 }
 
+func TestThresholdCommandRecovery(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{
+			Values: []mathexp.Value{
+				makeNumber(data.Labels{"host": "firing"}, 75),
+				makeNumber(data.Labels{"host": "normal"}, 75),
+				makeNumber(data.Labels{"host": "recovered"}, 65),
+				makeNumber(data.Labels{"host": "above"}, 85),
+			},
+		},
+	}
+
+	cmd, err := NewThresholdCommand("B", "A", ThresholdIsAbove, []float64{80})
+	require.NoError(t, err)
+	cmd.Recovery = &RecoveryThreshold{
+		ThresholdFunc: ThresholdIsBelow,
+		Conditions:    []float64{70},
+		ActiveLabels: []data.Labels{
+			{"host": "firing"},
+			{"host": "recovered"},
+			{"host": "above"},
+		},
+	}
+
+	results, err := cmd.Execute(context.Background(), time.Now(), vars)
+	require.NoError(t, err)
+
+	actual := make(map[string]float64, len(results.Values))
+	for _, val := range results.Values {
+		n, ok := val.(mathexp.Number)
+		require.True(t, ok)
+		actual[n.GetLabels()["host"]] = *n.GetFloat64Value()
+	}
+	require.Equal(t, map[string]float64{
+		"firing":    1, // between the recovery threshold and the threshold, keeps firing
+		"normal":    0, // between the recovery threshold and the threshold, but was not active
+		"recovered": 0, // below the recovery threshold
+		"above":     1,
+	}, actual)
+
+	t.Run("should only use the threshold if there are no active series", func(t *testing.T) {
+		cmd.Recovery.ActiveLabels = nil
+		results, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, results.Values, 4)
+		require.Equal(t, float64(0), *results.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+}
+
+func TestSetThresholdActiveLabels(t *testing.T) {
+	model := json.RawMessage(`{
+		"expression" : "A",
+		"type": "threshold",
+		"conditions": [{
+			"evaluator": {"type": "gt", "params": [80]},
+			"recoveryEvaluator": {"type": "lt", "params": [70]}
+		}]
+	}`)
+
+	ok, err := IsThresholdWithRecovery(model)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = IsThresholdWithRecovery(json.RawMessage(`{"expression": "A", "type": "threshold", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}`))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	updated, err := SetThresholdActiveLabels(model, []data.Labels{{"host": "a"}})
+	require.NoError(t, err)
+
+	var qmap map[string]interface{}
+	require.NoError(t, json.Unmarshal(updated, &qmap))
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: qmap})
+	require.NoError(t, err)
+	require.NotNil(t, cmd.Recovery)
+	require.Equal(t, []data.Labels{{"host": "a"}}, cmd.Recovery.ActiveLabels)
+}
+
+func makeNumber(labels data.Labels, value float64) mathexp.Number {
+	n := mathexp.NewNumber("", labels)
+	n.SetValue(&value)
+	return n
+}
+
 func TestThresholdCommandVars(t *testing.T) {
 	cmd, err := NewThresholdCommand("B", "A", "is_above", []float64{})
 	require.Nil(t, err)
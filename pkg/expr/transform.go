@@ -106,6 +106,16 @@ func (s *Service) TransformData(ctx context.Context, now time.Time, req *Request
 		return nil, err
 	}
 
+	// In debug mode, the results of every node are returned along with the execution trace,
+	// including the hidden queries and the nodes that were executed before an error.
+	if req.Debug {
+		responses, err := s.ExecutePipelineDebug(ctx, now, pipeline)
+		if err != nil {
+			logger.Debug("Expression pipeline failed in debug mode", "error", err)
+		}
+		return responses, nil
+	}
+
 	// Execute the pipeline
 	responses, err := s.ExecutePipeline(ctx, now, pipeline)
 	if err != nil {
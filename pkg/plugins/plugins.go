@@ -147,6 +147,21 @@ type JSONData struct {
 
 	// Backend (Datasource + Renderer + SecretsManager)
 	Executable string `json:"executable,omitempty"`
+
+	// Notifier settings (backend plugins)
+	Notifier *NotifierJSONData `json:"notifier,omitempty"`
+}
+
+// NotifierJSONData describes the receiver type that a backend plugin provides to the Grafana Alertmanager.
+// The plugin receives the notifications as POST requests to its resource "notify".
+type NotifierJSONData struct {
+	// Name is the name of the receiver type in the user interface. The type of the receivers is the ID of the plugin.
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// SettingsSchema is the JSON schema of the settings of the receivers.
+	SettingsSchema json.RawMessage `json:"settingsSchema"`
+	// SecureFields are the settings that are stored encrypted.
+	SecureFields []string `json:"secureFields,omitempty"`
 }
 
 func (d JSONData) DashboardIncludes() []*Includes {
@@ -26,6 +26,18 @@ func SerializeLabelsColumn(frames ...*data.Frame) ([]byte, error) {
 
 // TimeSeriesFromFrames converts frames to slice of Prometheus TimeSeries.
 func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(makeMetricName, frames...)
+}
+
+// TimeSeriesFromFramesWithMetricName converts frames to slice of Prometheus TimeSeries
+// named metricName. The time series are told apart by the labels of the fields.
+func TimeSeriesFromFramesWithMetricName(metricName string, frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(func(*data.Frame, *data.Field) string {
+		return metricName
+	}, frames...)
+}
+
+func timeSeriesFromFrames(metricNameFunc func(*data.Frame, *data.Field) string, frames ...*data.Frame) []prompb.TimeSeries {
 	var entries = make(map[metricKey]prompb.TimeSeries)
 	var keys []metricKey // sorted keys.
 
@@ -39,7 +51,7 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 			if !field.Type().Numeric() {
 				continue
 			}
-			metricName := makeMetricName(frame, field)
+			metricName := metricNameFunc(frame, field)
 			metricName, ok := sanitizeMetricName(metricName)
 			if !ok {
 				continue
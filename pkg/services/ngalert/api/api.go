@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -51,6 +52,7 @@ type Alertmanager interface {
 	// Receivers
 	GetReceivers(ctx context.Context) []apimodels.Receiver
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error)
+	GetReceiverDeliveries(receiver string, limit int) ([]apimodels.NotificationDelivery, error)
 }
 
 type AlertingStore interface {
@@ -79,8 +81,10 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	MaintenanceWindows   *provisioning.MaintenanceWindowService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
+	Historian            Historian
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -90,6 +94,11 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		DataProxy: api.DataProxy,
 		ac:        api.AccessControl,
 	}
+	appURL, err := url.Parse(api.Cfg.AppURL)
+	if err != nil {
+		logger.Error("Failed to parse application URL. Continue without it.", "error", err)
+		appURL = nil
+	}
 
 	// Register endpoints for proxying to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
@@ -117,6 +126,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:                logger,
 			cfg:                &api.Cfg.UnifiedAlerting,
 			ac:                 api.AccessControl,
+			datasourceCache:    api.DatasourceCache,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -126,6 +136,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:             logger,
 			accessControl:   api.AccessControl,
 			evaluator:       api.EvaluatorFactory,
+			cfg:             &api.Cfg.UnifiedAlerting,
+			backtesting:     backtesting.NewEngine(appURL, api.EvaluatorFactory),
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -143,5 +155,13 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		maintenanceWindows:  api.MaintenanceWindows,
+	}), m)
+
+	api.RegisterHistoryApiEndpoints(NewHistoryApi(&HistorySrv{
+		logger:    logger,
+		historian: api.Historian,
+		store:     api.RuleStore,
+		ac:        api.AccessControl,
 	}), m)
 }
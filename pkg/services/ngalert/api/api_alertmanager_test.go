@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -290,6 +291,32 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestRouteGetReceiverDeliveries(t *testing.T) {
+	sut := createSut(t, nil)
+	rc := func(url string) *models.ReqContext {
+		return &models.ReqContext{
+			Context:      &web.Context{Req: httptest.NewRequest(http.MethodGet, url, nil)},
+			SignedInUser: &user.SignedInUser{OrgID: 1},
+		}
+	}
+
+	t.Run("should return the attempts of the receiver", func(t *testing.T) {
+		response := sut.RouteGetReceiverDeliveries(rc("/?limit=10"), "grafana-default-email")
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, "[]", string(response.Body()))
+	})
+
+	t.Run("should return 404 if the receiver does not exist", func(t *testing.T) {
+		response := sut.RouteGetReceiverDeliveries(rc("/"), "unknown")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if the limit is negative", func(t *testing.T) {
+		response := sut.RouteGetReceiverDeliveries(rc("/?limit=-1"), "grafana-default-email")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
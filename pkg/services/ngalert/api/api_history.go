@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	defaultStateHistoryLimit = 1000
+	maxStateHistoryLimit     = 10000
+	defaultStateHistoryRange = 24 * time.Hour
+)
+
+// Historian reads the state history of alert rules.
+type Historian interface {
+	QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error)
+}
+
+type HistorySrv struct {
+	logger    log.Logger
+	historian Historian
+	store     RuleStore
+	ac        accesscontrol.AccessControl
+}
+
+// RouteGetStateHistory returns the state transitions of the alert rules that the user can read.
+// The state transitions of the rules that were deleted are not returned.
+func (srv HistorySrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	ruleUIDs, err := srv.readableRuleUIDs(c)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	if query.RuleUID != "" {
+		if _, ok := ruleUIDs[query.RuleUID]; !ok {
+			return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+		}
+	} else {
+		// the historian filters the rules, so that the limit applies to the transitions that the user can read
+		query.RuleUIDs = make([]string, 0, len(ruleUIDs))
+		for uid := range ruleUIDs {
+			query.RuleUIDs = append(query.RuleUIDs, uid)
+		}
+		sort.Strings(query.RuleUIDs)
+	}
+
+	frame, err := srv.historian.QueryStates(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrStateHistoryQueryNotSupported) {
+			return ErrResp(http.StatusNotImplemented, err, "set the backend of the state history to sql or loki to query it")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to query the state history")
+	}
+	return response.JSON(http.StatusOK, apimodels.StateHistory{Results: frame})
+}
+
+// readableRuleUIDs returns the UIDs of the alert rules that the user can read.
+func (srv HistorySrv) readableRuleUIDs(c *models.ReqContext) (map[string]struct{}, error) {
+	namespaceMap, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.OrgID, c.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]struct{})
+	if len(namespaceMap) == 0 {
+		return result, nil
+	}
+
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for k := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, k)
+	}
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.OrgID,
+		NamespaceUIDs: namespaceUIDs,
+	}
+	if err := srv.store.ListAlertRules(c.Req.Context(), &q); err != nil {
+		return nil, err
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqViewer, evaluator)
+	}
+	groupedRules := make(map[ngmodels.AlertRuleGroupKey][]*ngmodels.AlertRule)
+	for _, rule := range q.Result {
+		groupedRules[rule.GetGroupKey()] = append(groupedRules[rule.GetGroupKey()], rule)
+	}
+	for _, rules := range groupedRules {
+		if !authorizeAccessToRuleGroup(rules, hasAccess) {
+			continue
+		}
+		for _, rule := range rules {
+			result[rule.UID] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+func parseHistoryQuery(c *models.ReqContext) (ngmodels.HistoryQuery, error) {
+	query := ngmodels.HistoryQuery{
+		OrgID:   c.SignedInUser.OrgID,
+		RuleUID: c.Query("ruleUID"),
+		State:   ngmodels.InstanceStateType(c.Query("state")),
+		Limit:   defaultStateHistoryLimit,
+	}
+
+	if query.State != "" && !query.State.IsValid() {
+		return ngmodels.HistoryQuery{}, fmt.Errorf("invalid state %q", query.State)
+	}
+
+	for _, label := range c.QueryStrings("label") {
+		name, value, ok := strings.Cut(label, "=")
+		if !ok || name == "" {
+			return ngmodels.HistoryQuery{}, fmt.Errorf("invalid label %q, expected the format name=value", label)
+		}
+		if query.Labels == nil {
+			query.Labels = make(map[string]string)
+		}
+		query.Labels[name] = value
+	}
+
+	query.To = timeNow()
+	if to := c.Query("to"); to != "" {
+		seconds, err := strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			return ngmodels.HistoryQuery{}, fmt.Errorf("invalid to %q: %w", to, err)
+		}
+		query.To = time.Unix(seconds, 0)
+	}
+	query.From = query.To.Add(-defaultStateHistoryRange)
+	if from := c.Query("from"); from != "" {
+		seconds, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return ngmodels.HistoryQuery{}, fmt.Errorf("invalid from %q: %w", from, err)
+		}
+		query.From = time.Unix(seconds, 0)
+	}
+	if query.From.After(query.To) {
+		return ngmodels.HistoryQuery{}, errors.New("from must not be after to")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return ngmodels.HistoryQuery{}, fmt.Errorf("invalid limit %q: %w", limit, err)
+		}
+		if l <= 0 || l > maxStateHistoryLimit {
+			return ngmodels.HistoryQuery{}, fmt.Errorf("limit must be between 1 and %d", maxStateHistoryLimit)
+		}
+		query.Limit = l
+	}
+	return query, nil
+}
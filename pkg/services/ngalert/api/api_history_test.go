@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type fakeHistorian struct {
+	frame   *data.Frame
+	err     error
+	queries []ngmodels.HistoryQuery
+}
+
+func (f *fakeHistorian) QueryStates(_ context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	f.queries = append(f.queries, query)
+	return f.frame, f.err
+}
+
+func TestRouteGetStateHistory(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 3, 10, 14, 0, 0, 0, time.UTC) }
+	orgID := int64(1)
+
+	ruleStore := fakes.NewRuleStore(t)
+	rules := ngmodels.GenerateAlertRules(2, ngmodels.AlertRuleGen(withOrgID(orgID)))
+	ruleStore.PutRule(context.Background(), rules...)
+
+	newRequest := func(query url.Values) *models.ReqContext {
+		request := createRequestContext(orgID, org.RoleViewer, nil)
+		request.Req.URL.RawQuery = query.Encode()
+		return request
+	}
+	setup := func(historian *fakeHistorian) HistorySrv {
+		return HistorySrv{
+			logger:    log.NewNopLogger(),
+			historian: historian,
+			store:     ruleStore,
+			ac:        acmock.New().WithDisabled(),
+		}
+	}
+
+	t.Run("should return the transitions of the readable rules", func(t *testing.T) {
+		frame := data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{timeNow()}),
+			data.NewField("ruleUID", nil, []string{rules[0].UID}),
+		)
+		historian := &fakeHistorian{frame: frame}
+		srv := setup(historian)
+
+		request := newRequest(url.Values{"label": {"team=a"}, "state": {"Alerting"}})
+		response := srv.RouteGetStateHistory(request)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		ruleUIDs := []string{rules[0].UID, rules[1].UID}
+		sort.Strings(ruleUIDs)
+		require.Len(t, historian.queries, 1)
+		require.Equal(t, ngmodels.HistoryQuery{
+			OrgID:    orgID,
+			RuleUIDs: ruleUIDs,
+			Labels:   map[string]string{"team": "a"},
+			State:    ngmodels.InstanceStateFiring,
+			From:     timeNow().Add(-24 * time.Hour),
+			To:       timeNow(),
+			Limit:    defaultStateHistoryLimit,
+		}, historian.queries[0])
+
+		var result struct {
+			Results *data.Frame `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, 1, result.Results.Rows())
+		require.Equal(t, rules[0].UID, result.Results.Fields[1].At(0))
+	})
+
+	t.Run("should query no rules if the user cannot read any rule", func(t *testing.T) {
+		historian := &fakeHistorian{frame: data.NewFrame("states")}
+		srv := setup(historian)
+		srv.store = fakes.NewRuleStore(t)
+
+		response := srv.RouteGetStateHistory(newRequest(url.Values{}))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Len(t, historian.queries, 1)
+		require.NotNil(t, historian.queries[0].RuleUIDs)
+		require.Empty(t, historian.queries[0].RuleUIDs)
+	})
+
+	t.Run("should return 404 if the rule does not exist", func(t *testing.T) {
+		srv := setup(&fakeHistorian{frame: data.NewFrame("states")})
+		request := newRequest(url.Values{"ruleUID": {"unknown"}})
+		require.Equal(t, http.StatusNotFound, srv.RouteGetStateHistory(request).Status())
+	})
+
+	t.Run("should return 501 if the backend cannot be queried", func(t *testing.T) {
+		srv := setup(&fakeHistorian{err: ngmodels.ErrStateHistoryQueryNotSupported})
+		request := newRequest(url.Values{"ruleUID": {rules[1].UID}})
+		require.Equal(t, http.StatusNotImplemented, srv.RouteGetStateHistory(request).Status())
+	})
+
+	t.Run("should return 400 if the query is invalid", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"state": {"Unknown"}},
+			{"label": {"team"}},
+			{"from": {"yesterday"}},
+			{"from": {"200"}, "to": {"100"}},
+			{"limit": {"0"}},
+			{"limit": {"10001"}},
+		} {
+			srv := setup(&fakeHistorian{frame: data.NewFrame("states")})
+			request := newRequest(query)
+			require.Equalf(t, http.StatusBadRequest, srv.RouteGetStateHistory(request).Status(), "query %s", query.Encode())
+		}
+	})
+}
@@ -196,6 +196,9 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 			Duration:    rule.For.Seconds(),
 			Annotations: rule.Annotations,
 		}
+		if rule.DependsOn != nil {
+			alertingRule.DependsOn = rule.DependsOn.UID
+		}
 
 		newRule := apimodels.Rule{
 			Name:           rule.Title,
@@ -204,6 +207,17 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.IsRecordingRule() {
+			// recording rules do not have a state nor alerts
+			newRule.Type = apiv1.RuleTypeRecording
+			newGroup.Rules = append(newGroup.Rules, apimodels.AlertingRule{
+				Name:  rule.Title,
+				Query: alertingRule.Query,
+				Rule:  newRule,
+			})
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			continue
+		}
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
 			activeAt := alertState.StartsAt
@@ -248,6 +262,10 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 				newRule.Health = "error"
 			}
 
+			if alertState.SuppressionReason == ngmodels.StateReasonSuppressed {
+				alertingRule.Suppressed = true
+			}
+
 			alertingRule.Alerts = append(alertingRule.Alerts, alert)
 		}
 
@@ -464,6 +464,30 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 		})
 	})
 
+	t.Run("with a rule that depends on a firing rule", func(t *testing.T) {
+		fakeStore, fakeAIM, _, api := setupAPI(t)
+		rule := ngmodels.AlertRuleGen(withOrgID(orgID), asFixture(), withClassicConditionSingleQuery())()
+		rule.DependsOn = &ngmodels.AlertRuleDependency{UID: "parent"}
+		fakeStore.PutRule(context.Background(), rule)
+		fakeAIM.GenerateAlertInstances(orgID, rule.UID, 1, func(s *state.State) *state.State {
+			s = withAlertingState()(s)
+			s.SuppressionReason = ngmodels.StateReasonSuppressed
+			return s
+		})
+
+		r := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, r.Status())
+		result := &apimodels.RuleResponse{}
+		require.NoError(t, json.Unmarshal(r.Body(), result))
+
+		require.Len(t, result.Data.RuleGroups, 1)
+		require.Len(t, result.Data.RuleGroups[0].Rules, 1)
+		actual := result.Data.RuleGroups[0].Rules[0]
+		require.Equal(t, "parent", actual.DependsOn)
+		require.True(t, actual.Suppressed)
+		require.Equal(t, "Alerting", actual.Alerts[0].State)
+	})
+
 	t.Run("when fine-grained access is enabled", func(t *testing.T) {
 		t.Run("should return only rules if the user can query all data sources", func(t *testing.T) {
 			ruleStore := fakes.NewRuleStore(t)
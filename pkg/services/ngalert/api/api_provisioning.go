@@ -24,6 +24,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	maintenanceWindows  MaintenanceWindowService
 }
 
 type ContactPointService interface {
@@ -52,6 +53,14 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type MaintenanceWindowService interface {
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]definitions.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (definitions.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, orgID int64, mw definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, orgID int64, mw definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
 type AlertRuleService interface {
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
 	CreateAlertRule(ctx context.Context, rule alerting_models.AlertRule, provenance alerting_models.Provenance, userID int64) (alerting_models.AlertRule, error)
@@ -247,6 +256,64 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext, name str
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindows(c *models.ReqContext) response.Response {
+	windows, err := srv.maintenanceWindows.GetMaintenanceWindows(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, windows)
+}
+
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindow(c *models.ReqContext, UID string) response.Response {
+	window, err := srv.maintenanceWindows.GetMaintenanceWindow(c.Req.Context(), c.OrgID, UID)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return response.Empty(http.StatusNotFound)
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, window)
+}
+
+func (srv *ProvisioningSrv) RoutePostMaintenanceWindow(c *models.ReqContext, mw definitions.MaintenanceWindow) response.Response {
+	mw.Provenance = determineProvenance(c)
+	created, err := srv.maintenanceWindows.CreateMaintenanceWindow(c.Req.Context(), c.OrgID, mw)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv *ProvisioningSrv) RoutePutMaintenanceWindow(c *models.ReqContext, mw definitions.MaintenanceWindow, UID string) response.Response {
+	mw.UID = UID
+	mw.Provenance = determineProvenance(c)
+	updated, err := srv.maintenanceWindows.UpdateMaintenanceWindow(c.Req.Context(), c.OrgID, mw)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return response.Empty(http.StatusNotFound)
+	}
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMaintenanceWindow(c *models.ReqContext, UID string) response.Response {
+	err := srv.maintenanceWindows.DeleteMaintenanceWindow(c.Req.Context(), c.OrgID, UID, determineProvenance(c))
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteRouteGetAlertRule(c *models.ReqContext, UID string) response.Response {
 	rule, provenace, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.OrgID, UID)
 	if err != nil {
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// errImportDryRun rolls back the transaction of a dry run of an import.
+var errImportDryRun = errors.New("dry run of the import")
+
+type importedRuleGroup struct {
+	key     ngmodels.AlertRuleGroupKey
+	rules   []*ngmodels.AlertRule
+	skipped []string
+}
+
+// RoutePostPrometheusRulesImport converts the alerting rules of a Prometheus rule file into Grafana-managed rules that
+// query the Prometheus data source of the request, and replaces the rule groups of the namespace with the same names.
+// The rules of the namespace previously imported from Prometheus with the same title as an imported rule are updated
+// rather than re-created, so that a rule file can be imported again after a change. All groups are updated in a single transaction.
+// A dry run applies the changes in a transaction that is rolled back, so that the changes of each group are calculated
+// with the changes of the previous groups, and the quota is checked, as in a real import.
+func (srv RulerSrv) RoutePostPrometheusRulesImport(c *models.ReqContext, body apimodels.PrometheusRulesImport, namespaceTitle string) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgID, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	if body.DatasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasource_uid is required"), "")
+	}
+	ds, err := srv.datasourceCache.GetDatasourceByUID(c.Req.Context(), body.DatasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, datasources.DS_PROMETHEUS))
+	}
+
+	groups, err := prom.ParseGroups([]byte(body.Groups))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if len(groups) == 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: no rule groups", prom.ErrInvalidRules), "")
+	}
+	converted, err := prom.ConvertGroups(groups, ds.Uid)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	imported := make([]importedRuleGroup, 0, len(converted))
+	for _, group := range converted {
+		g := importedRuleGroup{
+			key: ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.OrgID,
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Name,
+			},
+			skipped: group.Skipped,
+		}
+		// a group of recording rules only is left as is, instead of deleting the existing rules of the group
+		if len(group.Rules) > 0 {
+			g.rules, err = validateRuleGroup(&group.PostableRuleGroupConfig, c.SignedInUser.OrgID, namespace, func(condition ngmodels.Condition) error {
+				return srv.conditionValidator.Validate(eval.Context(c.Req.Context(), c.SignedInUser), condition)
+			}, srv.cfg)
+			if err != nil {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid rule group '%s': %w", group.Name, err), "")
+			}
+		}
+		imported = append(imported, g)
+	}
+
+	deltas := make([]*store.GroupDelta, len(imported))
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for i, g := range imported {
+			if len(g.rules) == 0 {
+				deltas[i] = &store.GroupDelta{GroupKey: g.key}
+				continue
+			}
+			if err := srv.matchExistingRules(tranCtx, g); err != nil {
+				return err
+			}
+			delta, err := srv.applyRuleGroupChanges(tranCtx, c, g.key, g.rules)
+			if err != nil {
+				return fmt.Errorf("failed to import rule group '%s': %w", g.key.RuleGroup, err)
+			}
+			deltas[i] = delta
+		}
+		if body.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return toRuleGroupUpdateErrorResponse(err)
+	}
+
+	result := apimodels.PrometheusRulesImportResult{
+		DryRun: body.DryRun,
+		Groups: make([]apimodels.PrometheusRulesImportGroupDelta, 0, len(imported)),
+	}
+	for i, g := range imported {
+		if !body.DryRun {
+			srv.scheduleRuleGroupChanges(c.SignedInUser.OrgID, deltas[i])
+		}
+		result.Groups = append(result.Groups, toPrometheusRulesImportGroupDelta(deltas[i], g.skipped))
+	}
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// matchExistingRules sets the UIDs of the rules of the folder previously imported from Prometheus with the same title as
+// the imported rules, which are then updated rather than re-created. The rules are matched by title because a rule file
+// does not have the UIDs of the rules, and a title is unique in a folder. A rule of the folder with the same title that
+// was not imported is not replaced, and fails the import. The rules are matched against the groups as they are updated
+// by the previous groups of the import.
+func (srv RulerSrv) matchExistingRules(ctx context.Context, g importedRuleGroup) error {
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         g.key.OrgID,
+		NamespaceUIDs: []string{g.key.NamespaceUID},
+	}
+	if err := srv.store.ListAlertRules(ctx, &q); err != nil {
+		return fmt.Errorf("failed to get the rules of the folder: %w", err)
+	}
+	existing := make(map[string]*ngmodels.AlertRule, len(q.Result))
+	for _, rule := range q.Result {
+		existing[rule.Title] = rule
+	}
+	for _, rule := range g.rules {
+		match, ok := existing[rule.Title]
+		if !ok {
+			continue
+		}
+		if match.Annotations[ngmodels.ImportSourceAnnotation] != prom.ImportSource {
+			return fmt.Errorf("%w: rule '%s' of the folder was not imported from Prometheus", ngmodels.ErrAlertRuleFailedValidation, rule.Title)
+		}
+		rule.UID = match.UID
+	}
+	return nil
+}
+
+func toPrometheusRulesImportGroupDelta(delta *store.GroupDelta, skipped []string) apimodels.PrometheusRulesImportGroupDelta {
+	result := apimodels.PrometheusRulesImportGroupDelta{
+		Name:    delta.GroupKey.RuleGroup,
+		Skipped: skipped,
+	}
+	for _, rule := range delta.New {
+		result.Added = append(result.Added, rule.Title)
+	}
+	for _, update := range delta.Update {
+		// the other updates are the versions and indexes of the rules of the affected groups
+		if len(update.Diff) == 0 || update.New.GetGroupKey() != delta.GroupKey {
+			continue
+		}
+		diff := make([]string, 0, len(update.Diff))
+		for _, d := range update.Diff {
+			diff = append(diff, strings.TrimSpace(d.String()))
+		}
+		result.Updated = append(result.Updated, apimodels.PrometheusRulesImportRuleDiff{
+			Title: update.New.Title,
+			UID:   update.New.UID,
+			Diff:  diff,
+		})
+	}
+	for _, rule := range delta.Delete {
+		result.Deleted = append(result.Deleted, rule.Title)
+	}
+	return result
+}
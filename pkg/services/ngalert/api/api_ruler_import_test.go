@@ -0,0 +1,350 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const testPrometheusRules = `
+groups:
+  - name: example
+    interval: 30s
+    rules:
+      - record: job:http_errors:rate5m
+        expr: sum by (job) (rate(http_errors_total[5m]))
+      - alert: HighErrorRate
+        expr: job:http_errors:rate5m > 0.5
+        for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: High error rate of {{ $labels.job }}
+      - alert: InstanceDown
+        expr: up == 0
+`
+
+// fakeImportTransactionManager restores the rules of the store when a transaction fails, to test the dry runs that are
+// rolled back.
+type fakeImportTransactionManager struct {
+	store     *fakes.RuleStore
+	rollbacks int
+}
+
+func (m *fakeImportTransactionManager) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[int64][]*models.AlertRule, len(m.store.Rules))
+	for orgID, rules := range m.store.Rules {
+		snapshot[orgID] = append([]*models.AlertRule(nil), rules...)
+	}
+	err := fn(ctx)
+	if err != nil {
+		m.store.Rules = snapshot
+		m.rollbacks++
+	}
+	return err
+}
+
+// applyRuleWrites makes the fake store apply the inserts and the updates of rules, which it only records otherwise.
+func applyRuleWrites(ruleStore *fakes.RuleStore) {
+	ruleStore.Hook = func(cmd interface{}) error {
+		put := func(rule models.AlertRule) {
+			rules := ruleStore.Rules[rule.OrgID]
+			for i, r := range rules {
+				if r.UID == rule.UID {
+					rules[i] = &rule
+					return
+				}
+			}
+			ruleStore.Rules[rule.OrgID] = append(rules, &rule)
+		}
+		switch cmd := cmd.(type) {
+		case []models.AlertRule:
+			for _, rule := range cmd {
+				if rule.UID == "" {
+					rule.UID = util.GenerateShortUID()
+				}
+				put(rule)
+			}
+		case []models.UpdateRule:
+			for _, update := range cmd {
+				put(update.New)
+			}
+		}
+		return nil
+	}
+}
+
+type fakeReachedQuotaService struct {
+	quotatest.FakeQuotaService
+}
+
+func (s *fakeReachedQuotaService) CheckQuotaReached(context.Context, string, *quota.ScopeParameters) (bool, error) {
+	return true, nil
+}
+
+func withImportSource(rule *models.AlertRule) {
+	if rule.Annotations == nil {
+		rule.Annotations = map[string]string{}
+	}
+	rule.Annotations[models.ImportSourceAnnotation] = prom.ImportSource
+}
+
+func TestRoutePostPrometheusRulesImport(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "example"}
+
+	setup := func(t *testing.T) (*RulerSrv, *fakes.RuleStore, *schedule.FakeScheduleService, *models.AlertRule, *models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		applyRuleWrites(ruleStore)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		existing := models.AlertRuleGen(withGroupKey(groupKey), models.WithTitle("HighErrorRate"), withImportSource)()
+		obsolete := models.AlertRuleGen(withGroupKey(groupKey), models.WithTitle("Obsolete"))()
+		ruleStore.PutRule(context.Background(), existing, obsolete)
+
+		scheduler := &schedule.FakeScheduleService{}
+		scheduler.On("UpdateAlertRule", mock.Anything, mock.Anything).Return()
+		scheduler.On("DeleteAlertRule", mock.Anything).Return()
+
+		srv := createService(acMock.New().WithDisabled(), ruleStore, scheduler)
+		srv.xactManager = &fakeImportTransactionManager{store: ruleStore}
+		srv.QuotaService = quotatest.NewQuotaServiceFake()
+		srv.conditionValidator = eval_mocks.NewEvaluatorFactory(nil)
+		srv.cfg = &setting.UnifiedAlertingSettings{
+			BaseInterval:                  10 * time.Second,
+			DefaultRuleEvaluationInterval: time.Minute,
+		}
+		srv.datasourceCache = &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{
+			{Uid: "prometheus", Type: datasources.DS_PROMETHEUS},
+			{Uid: "loki", Type: datasources.DS_LOKI},
+		}}
+		return srv, ruleStore, scheduler, existing, obsolete
+	}
+
+	getResult := func(t *testing.T, status int, body []byte) apimodels.PrometheusRulesImportResult {
+		t.Helper()
+		require.Equalf(t, http.StatusAccepted, status, "Expected 202 but got %d: %s", status, string(body))
+		var result apimodels.PrometheusRulesImportResult
+		require.NoError(t, json.Unmarshal(body, &result))
+		return result
+	}
+
+	getWrites := func(ruleStore *fakes.RuleStore) (inserted []models.AlertRule, updated []models.UpdateRule, deleted []string) {
+		for _, op := range ruleStore.RecordedOps {
+			switch cmd := op.(type) {
+			case []models.AlertRule:
+				inserted = append(inserted, cmd...)
+			case []models.UpdateRule:
+				updated = append(updated, cmd...)
+			case fakes.GenericRecordedQuery:
+				if cmd.Name == "DeleteAlertRulesByUID" {
+					deleted = append(deleted, cmd.Params[1].([]string)...)
+				}
+			}
+		}
+		return inserted, updated, deleted
+	}
+
+	getRollbacks := func(srv *RulerSrv) int {
+		return srv.xactManager.(*fakeImportTransactionManager).rollbacks
+	}
+
+	requireNoWrites := func(t *testing.T, ruleStore *fakes.RuleStore) {
+		t.Helper()
+		inserted, updated, deleted := getWrites(ruleStore)
+		require.Empty(t, inserted)
+		require.Empty(t, updated)
+		require.Empty(t, deleted)
+	}
+
+	t.Run("should return the changes without applying them in a dry run", func(t *testing.T) {
+		srv, ruleStore, scheduler, existing, obsolete := setup(t)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        testPrometheusRules,
+			DryRun:        true,
+		}, folder.Title)
+
+		result := getResult(t, response.Status(), response.Body())
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		require.Equal(t, "example", group.Name)
+		require.Equal(t, []string{"InstanceDown"}, group.Added)
+		require.Equal(t, []string{"Obsolete"}, group.Deleted)
+		require.Equal(t, []string{"job:http_errors:rate5m"}, group.Skipped)
+		require.Len(t, group.Updated, 1)
+		require.Equal(t, existing.UID, group.Updated[0].UID)
+		require.NotEmpty(t, group.Updated[0].Diff)
+
+		require.Equal(t, 1, getRollbacks(srv))
+		require.ElementsMatch(t, []*models.AlertRule{existing, obsolete}, ruleStore.Rules[orgID])
+		scheduler.AssertNotCalled(t, "UpdateAlertRule", mock.Anything, mock.Anything)
+		scheduler.AssertNotCalled(t, "DeleteAlertRule", mock.Anything)
+	})
+
+	t.Run("should return the same changes in a dry run as in an import", func(t *testing.T) {
+		// the rule HighErrorRate moves to a group that is imported before its current group
+		rules := `
+groups:
+  - name: other
+    rules:
+      - alert: HighErrorRate
+        expr: up == 0
+  - name: example
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+`
+		srv, _, _, existing, _ := setup(t)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        rules,
+			DryRun:        true,
+		}, folder.Title)
+		dryRun := getResult(t, response.Status(), response.Body())
+		require.Equal(t, 1, getRollbacks(srv))
+
+		response = srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        rules,
+		}, folder.Title)
+		result := getResult(t, response.Status(), response.Body())
+
+		require.Len(t, result.Groups, 2)
+		require.Len(t, result.Groups[0].Updated, 1)
+		require.Equal(t, existing.UID, result.Groups[0].Updated[0].UID)
+		require.Equal(t, []string{"InstanceDown"}, result.Groups[1].Added)
+		require.Equal(t, []string{"Obsolete"}, result.Groups[1].Deleted)
+		dryRun.DryRun = false
+		require.Equal(t, result, dryRun)
+	})
+
+	t.Run("should fail a dry run if the quota is reached", func(t *testing.T) {
+		srv, ruleStore, _, existing, obsolete := setup(t)
+		srv.QuotaService = &fakeReachedQuotaService{}
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        testPrometheusRules,
+			DryRun:        true,
+		}, folder.Title)
+		require.Equal(t, http.StatusForbidden, response.Status())
+		require.ElementsMatch(t, []*models.AlertRule{existing, obsolete}, ruleStore.Rules[orgID])
+	})
+
+	t.Run("should update the rules with the same title, and create and delete the others", func(t *testing.T) {
+		srv, ruleStore, scheduler, existing, obsolete := setup(t)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        testPrometheusRules,
+		}, folder.Title)
+
+		result := getResult(t, response.Status(), response.Body())
+		require.False(t, result.DryRun)
+		require.Zero(t, getRollbacks(srv))
+
+		inserted, updated, deleted := getWrites(ruleStore)
+
+		require.Len(t, inserted, 1)
+		require.Equal(t, "InstanceDown", inserted[0].Title)
+		require.Equal(t, "C", inserted[0].Condition)
+		require.Len(t, inserted[0].Data, 3)
+		require.Equal(t, "prometheus", inserted[0].Data[0].DatasourceUID)
+		require.Equal(t, int64(30), inserted[0].IntervalSeconds)
+
+		require.Len(t, updated, 1)
+		require.Equal(t, existing.UID, updated[0].New.UID)
+		require.Equal(t, 10*time.Minute, updated[0].New.For)
+		require.Equal(t, "page", updated[0].New.Labels["severity"])
+
+		require.Equal(t, []string{obsolete.UID}, deleted)
+		scheduler.AssertCalled(t, "UpdateAlertRule", models.AlertRuleKey{OrgID: orgID, UID: existing.UID}, existing.Version+1)
+		scheduler.AssertCalled(t, "DeleteAlertRule", []models.AlertRuleKey{obsolete.GetKey()})
+	})
+
+	t.Run("should fail if a rule with the same title was not imported", func(t *testing.T) {
+		srv, ruleStore, _, _, _ := setup(t)
+		other := models.AlertRuleGen(withGroupKey(models.AlertRuleGroupKey{
+			OrgID:        orgID,
+			NamespaceUID: folder.UID,
+			RuleGroup:    "other",
+		}), models.WithTitle("InstanceDown"))()
+		other.Annotations = nil
+		ruleStore.PutRule(context.Background(), other)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			Groups:        testPrometheusRules,
+		}, folder.Title)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		require.Contains(t, string(response.Body()), "InstanceDown")
+		require.Contains(t, ruleStore.Rules[orgID], other)
+		require.Len(t, ruleStore.Rules[orgID], 3)
+	})
+
+	t.Run("should fail if the data source is not a Prometheus data source", func(t *testing.T) {
+		srv, ruleStore, _, _, _ := setup(t)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "loki",
+			Groups:        testPrometheusRules,
+		}, folder.Title)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+			DatasourceUID: "unknown",
+			Groups:        testPrometheusRules,
+		}, folder.Title)
+		require.Equal(t, http.StatusNotFound, response.Status())
+		requireNoWrites(t, ruleStore)
+	})
+
+	t.Run("should fail if the rule file is not valid", func(t *testing.T) {
+		srv, ruleStore, _, _, _ := setup(t)
+		request := createRequestContext(orgID, org.RoleEditor, nil)
+
+		for _, groups := range []string{
+			"groups: [",
+			"groups:\n  - name: example\n    rules:\n      - alert: Invalid\n        expr: up ==\n",
+			"groups: []",
+		} {
+			response := srv.RoutePostPrometheusRulesImport(request, apimodels.PrometheusRulesImport{
+				DatasourceUID: "prometheus",
+				Groups:        groups,
+			}, folder.Title)
+			require.Equalf(t, http.StatusBadRequest, response.Status(), "rules: %s", groups)
+		}
+		requireNoWrites(t, ruleStore)
+	})
+}
@@ -76,9 +76,24 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	if record := ruleNode.GrafanaManagedAlert.Record; record != nil {
+		if !cfg.RecordingRules.Enabled {
+			return nil, fmt.Errorf("%w: recording rules are disabled", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		// the record of a partial update is validated once the queries are patched
+		if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
+			if err := record.Validate(ruleNode.GrafanaManagedAlert.Data); err != nil {
+				return nil, err
+			}
+		}
+		// the condition of a recording rule is the query or expression it records
+		condition = record.From
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
 		if err = conditionValidator(cond); err != nil {
@@ -89,7 +104,7 @@ func validateRuleNode(
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
+		Condition:       condition,
 		Data:            ruleNode.GrafanaManagedAlert.Data,
 		UID:             ruleNode.GrafanaManagedAlert.UID,
 		IntervalSeconds: intervalSeconds,
@@ -97,6 +112,9 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          ruleNode.GrafanaManagedAlert.Record,
+		IsPaused:        ruleNode.GrafanaManagedAlert.IsPaused,
+		DependsOn:       ruleNode.GrafanaManagedAlert.DependsOn,
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/rand"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -465,6 +467,91 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNode_Record(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+
+	recordingRule := func() *apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.GrafanaManagedAlert.UID = ""
+		r.GrafanaManagedAlert.Data = append(r.GrafanaManagedAlert.Data, models.AlertQuery{
+			RefID:         "B",
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"type": "reduce", "expression": "A", "reducer": "last"}`),
+		})
+		r.GrafanaManagedAlert.Record = &models.Record{Metric: "instance:cpu_usage:avg", From: "B"}
+		return &r
+	}
+
+	t.Run("the condition is the recorded query or expression", func(t *testing.T) {
+		r := recordingRule()
+		var validated models.Condition
+		alert, err := validateRuleNode(r, "", cfg.BaseInterval, orgId, folder, func(condition models.Condition) error {
+			validated = condition
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		require.Equal(t, "B", validated.Condition)
+		require.Equal(t, "B", alert.Condition)
+		require.Equal(t, r.GrafanaManagedAlert.Record, alert.Record)
+		require.True(t, alert.IsRecordingRule())
+	})
+
+	testCases := []struct {
+		name string
+		rule func() *apimodels.PostableExtendedRuleNode
+		cfg  func(cfg setting.UnifiedAlertingSettings) setting.UnifiedAlertingSettings
+	}{
+		{
+			name: "fail if recording rules are disabled",
+			rule: recordingRule,
+			cfg: func(cfg setting.UnifiedAlertingSettings) setting.UnifiedAlertingSettings {
+				cfg.RecordingRules.Enabled = false
+				return cfg
+			},
+		},
+		{
+			name: "fail if metric name is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.Metric = "cpu usage"
+				return r
+			},
+		},
+		{
+			name: "fail if the recorded query or expression is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.From = ""
+				return r
+			},
+		},
+		{
+			name: "fail if the recorded query or expression does not exist",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.From = "C"
+				return r
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := *cfg
+			if testCase.cfg != nil {
+				c = testCase.cfg(c)
+			}
+			_, err := validateRuleNode(testCase.rule(), "", c.BaseInterval, orgId, folder, func(condition models.Condition) error {
+				return nil
+			}, &c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		})
+	}
+}
+
 func TestValidateRuleNode_UID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
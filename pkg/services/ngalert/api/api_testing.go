@@ -1,11 +1,14 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -14,8 +17,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -25,6 +30,8 @@ type TestingApiSrv struct {
 	log             log.Logger
 	accessControl   accesscontrol.AccessControl
 	evaluator       eval.EvaluatorFactory
+	cfg             *setting.UnifiedAlertingSettings
+	backtesting     *backtesting.Engine
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -43,6 +50,7 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body a
 		Data:      body.GrafanaManagedCondition.Data,
 	}
 	ctx := eval.Context(c.Req.Context(), c.SignedInUser)
+	ctx.Debug = body.GrafanaManagedCondition.Debug
 
 	conditionEval, err := srv.evaluator.Create(ctx, evalCond)
 	if err != nil {
@@ -54,15 +62,33 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body a
 		now = timeNow()
 	}
 
-	evalResults, err := conditionEval.Evaluate(c.Req.Context(), now)
+	var evalResults eval.Results
+	var debug *backend.QueryDataResponse
+	if ctx.Debug {
+		debug, err = conditionEval.EvaluateRaw(c.Req.Context(), now)
+		switch {
+		case err == nil:
+			evalResults = eval.ResponseToResults(evalCond, debug, now)
+		case debug != nil:
+			// the response contains the results of the nodes that were executed before the error
+			evalResults = eval.Results{eval.NewResultFromError(err, now, 0)}
+			err = nil
+		}
+	} else {
+		evalResults, err = conditionEval.Evaluate(c.Req.Context(), now)
+	}
 	if err != nil {
 		return ErrResp(500, err, "Failed to evaluate the rule")
 	}
 
 	frame := evalResults.AsDataFrame()
-	return response.JSONStreaming(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"instances": []*data.Frame{&frame},
-	})
+	}
+	if debug != nil {
+		result["debug"] = debug
+	}
+	return response.JSONStreaming(http.StatusOK, result)
 }
 
 func (srv TestingApiSrv) RouteTestRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload, datasourceUID string) response.Response {
@@ -117,7 +143,9 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 	if len(cmd.Data) > 0 {
 		cond.Condition = cmd.Data[0].RefID
 	}
-	evaluator, err := srv.evaluator.Create(eval.Context(c.Req.Context(), c.SignedInUser), cond)
+	ctx := eval.Context(c.Req.Context(), c.SignedInUser)
+	ctx.Debug = cmd.Debug
+	evaluator, err := srv.evaluator.Create(ctx, cond)
 
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
@@ -130,9 +158,64 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	evalResults, err := evaluator.EvaluateRaw(c.Req.Context(), now)
 
-	if err != nil {
+	// in debug mode, the error is set in the response of the node that failed
+	if err != nil && !(cmd.Debug && evalResults != nil) {
 		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
 	}
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) BacktestAlertRule(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	if !authorizeDatasourceAccessForRule(&ngmodels.AlertRule{Data: cmd.Data}, func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.accessControl, c)(accesscontrol.ReqSignedIn, evaluator)
+	}) {
+		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
+	}
+
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = srv.cfg.DefaultRuleEvaluationInterval
+	}
+	if interval < 0 || interval%time.Second != 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("interval must be a positive number of seconds"), "")
+	}
+	noDataState := ngmodels.NoData
+	if cmd.NoDataState != "" {
+		var err error
+		if noDataState, err = ngmodels.NoDataStateFromString(string(cmd.NoDataState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	execErrState := ngmodels.ErrorErrState
+	if cmd.ExecErrState != "" {
+		var err error
+		if execErrState, err = ngmodels.ErrStateFromString(string(cmd.ExecErrState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.OrgID,
+		Title:           cmd.Title,
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             time.Duration(cmd.For),
+		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
+		Labels:          cmd.Labels,
+		Annotations:     cmd.Annotations,
+		// the rule does not exist, a UID is required to build the state of its alert instances
+		UID: "backtesting",
+	}
+
+	frame, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to backtest the alert rule")
+	}
+	return response.JSON(http.StatusOK, frame)
+}
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -15,10 +18,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -277,6 +282,98 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestBacktestAlertRule(t *testing.T) {
+	from := time.Date(2022, 3, 10, 14, 0, 0, 0, time.UTC)
+	data1 := models.GenerateAlertQuery()
+	rc := &models2.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	createSrv := func(ac *acMock.Mock, evaluator *eval_mocks.ConditionEvaluatorMock) *TestingApiSrv {
+		evalFactory := eval_mocks.NewEvaluatorFactory(evaluator)
+		srv := createTestingApiSrv(nil, ac, evalFactory)
+		srv.cfg = &setting.UnifiedAlertingSettings{DefaultRuleEvaluationInterval: time.Minute}
+		srv.backtesting = backtesting.NewEngine(nil, evalFactory)
+		return srv
+	}
+
+	t.Run("should return 401 if user cannot query a data source", func(t *testing.T) {
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		srv := createSrv(acMock.New(), evaluator)
+
+		response := srv.BacktestAlertRule(rc, definitions.BacktestConfig{
+			From:      from,
+			To:        from.Add(time.Hour),
+			Condition: data1.RefID,
+			Data:      []models.AlertQuery{data1},
+		})
+
+		require.Equal(t, http.StatusUnauthorized, response.Status())
+		evaluator.AssertNotCalled(t, "Evaluate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 400 if the config is not valid", func(t *testing.T) {
+		ac := acMock.New().WithPermissions([]accesscontrol.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
+		})
+		for _, cfg := range []definitions.BacktestConfig{
+			{From: from, To: from.Add(time.Hour), Interval: prometheusModel.Duration(time.Millisecond)},
+			{From: from, To: from.Add(time.Hour), NoDataState: "Unknown"},
+			{From: from, To: from.Add(time.Hour), ExecErrState: "Unknown"},
+			{From: from.Add(time.Hour), To: from},
+			{From: from, To: from.Add(backtesting.MaxEvaluations * time.Minute)},
+		} {
+			evaluator := &eval_mocks.ConditionEvaluatorMock{}
+			srv := createSrv(ac, evaluator)
+			cfg.Condition = data1.RefID
+			cfg.Data = []models.AlertQuery{data1}
+
+			response := srv.BacktestAlertRule(rc, cfg)
+
+			require.Equal(t, http.StatusBadRequest, response.Status())
+			evaluator.AssertNotCalled(t, "Evaluate", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should evaluate the rule at every interval of the time range", func(t *testing.T) {
+		ac := acMock.New().WithPermissions([]accesscontrol.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
+		})
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		times := []time.Time{from, from.Add(5 * time.Minute), from.Add(10 * time.Minute)}
+		for _, now := range times {
+			evaluator.EXPECT().Evaluate(mock.Anything, now).Return(eval.Results{{State: eval.Alerting, EvaluatedAt: now}}, nil)
+		}
+		srv := createSrv(ac, evaluator)
+
+		response := srv.BacktestAlertRule(rc, definitions.BacktestConfig{
+			From:      from,
+			To:        from.Add(10 * time.Minute),
+			Interval:  prometheusModel.Duration(5 * time.Minute),
+			Condition: data1.RefID,
+			Data:      []models.AlertQuery{data1},
+			Title:     "test",
+		})
+
+		require.Equal(t, http.StatusOK, response.Status())
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 3)
+		for _, now := range times {
+			evaluator.AssertCalled(t, "Evaluate", mock.Anything, now)
+		}
+
+		frame := data.Frame{}
+		require.NoError(t, json.Unmarshal(response.Body(), &frame))
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, data.Labels{"alertname": "test"}, frame.Fields[1].Labels)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "Alerting", *frame.Fields[1].At(2).(*string))
+	})
+}
+
 func createTestingApiSrv(ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New().WithDisabled()
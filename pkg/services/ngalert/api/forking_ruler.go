@@ -93,6 +93,10 @@ func (f *RulerApiHandler) handleRouteGetGrafanaRulesConfig(ctx *models.ReqContex
 	return f.GrafanaRuler.RouteGetRulesConfig(ctx)
 }
 
+func (f *RulerApiHandler) handleRoutePostGrafanaPrometheusRulesImport(ctx *models.ReqContext, conf apimodels.PrometheusRulesImport, namespace string) response.Response {
+	return f.GrafanaRuler.RoutePostPrometheusRulesImport(ctx, conf, namespace)
+}
+
 func (f *RulerApiHandler) handleRoutePostNameGrafanaRulesConfig(ctx *models.ReqContext, conf apimodels.PostableRuleGroupConfig, namespace string) response.Response {
 	payloadType := conf.Type()
 	if payloadType != apimodels.GrafanaBackend {
@@ -56,6 +56,26 @@ func (f *ProvisioningApiHandler) handleRouteDeleteTemplate(ctx *models.ReqContex
 	return f.svc.RouteDeleteTemplate(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetMaintenanceWindows(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMaintenanceWindows(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetMaintenanceWindow(ctx *models.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetMaintenanceWindow(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostMaintenanceWindow(ctx *models.ReqContext, mw apimodels.MaintenanceWindow) response.Response {
+	return f.svc.RoutePostMaintenanceWindow(ctx, mw)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutMaintenanceWindow(ctx *models.ReqContext, mw apimodels.MaintenanceWindow, UID string) response.Response {
+	return f.svc.RoutePutMaintenanceWindow(ctx, mw, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteMaintenanceWindow(ctx *models.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteMaintenanceWindow(ctx, UID)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetMuteTiming(ctx *models.ReqContext, name string) response.Response {
 	return f.svc.RouteGetMuteTiming(ctx, name)
 }
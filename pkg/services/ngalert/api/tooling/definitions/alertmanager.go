@@ -155,6 +155,15 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route GET /api/alertmanager/grafana/api/v2/receivers/{Receiver}/deliveries alertmanager RouteGetGrafanaReceiverDeliveries
+//
+// Get the latest attempts to send notifications to the integrations of a receiver, most recent first.
+//
+//     Responses:
+//       200: NotificationDeliveries
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -403,6 +412,43 @@ type ReceiversResponse struct {
 // swagger:model integration
 type Integration = amv2.Integration
 
+// swagger:parameters RouteGetGrafanaReceiverDeliveries
+type ReceiverDeliveriesParams struct {
+	// in:path
+	Receiver string
+	// The maximum number of attempts to return. Defaults to all the attempts that are kept.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+const (
+	NotificationDeliverySuccess = "success"
+	NotificationDeliveryFailure = "failure"
+)
+
+// swagger:model
+type NotificationDeliveries []NotificationDelivery
+
+// NotificationDelivery is an attempt to send a notification to an integration of a receiver.
+// swagger:model
+type NotificationDelivery struct {
+	Receiver       string `json:"receiver"`
+	Integration    string `json:"integration"`
+	IntegrationUID string `json:"integrationUid,omitempty"`
+	IntegrationIdx int    `json:"integrationIndex"`
+	GroupKey       string `json:"groupKey"`
+	Alerts         int    `json:"alerts"`
+	// Status is either "success" or "failure".
+	Status string `json:"status"`
+	// StatusCode is the status code of the response of the integration, if it is known.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration"`
+	// Retry is the number of failed attempts to send the same notification before this one.
+	Retry     int       `json:"retry"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // swagger:parameters RouteGetAMAlerts RouteGetAMAlertGroups RouteGetGrafanaAMAlerts RouteGetGrafanaAMAlertGroups
 type AlertsParams struct {
 
@@ -128,6 +128,21 @@ import (
 //       202: Ack
 //       404: NotFound
 
+// swagger:route POST /api/ruler/grafana/api/v1/import/prometheus/{Namespace} ruler RoutePostGrafanaPrometheusRulesImport
+//
+// Imports the alerting rules of a Prometheus rule file as Grafana-managed rule groups. The groups of the file replace the groups with the same name in the folder.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: PrometheusRulesImportResult
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -136,6 +151,52 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostGrafanaPrometheusRulesImport
+type PrometheusRulesImportParams struct {
+	// in:path
+	Namespace string
+	// in:body
+	Body PrometheusRulesImport
+}
+
+// swagger:model
+type PrometheusRulesImport struct {
+	// UID of the Prometheus data source that the imported rules query.
+	// required: true
+	DatasourceUID string `json:"datasource_uid"`
+	// Rule groups in the YAML format of the rule files of Prometheus.
+	// required: true
+	Groups string `json:"groups"`
+	// If true, the changes are calculated and returned but not applied.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// swagger:model
+type PrometheusRulesImportResult struct {
+	DryRun bool                              `json:"dry_run"`
+	Groups []PrometheusRulesImportGroupDelta `json:"groups"`
+}
+
+// PrometheusRulesImportGroupDelta describes the changes of a rule group made by an import.
+type PrometheusRulesImportGroupDelta struct {
+	Name string `json:"name"`
+	// Titles of the rules that are created.
+	Added []string `json:"added,omitempty"`
+	// Rules that are updated, with the differences.
+	Updated []PrometheusRulesImportRuleDiff `json:"updated,omitempty"`
+	// Titles of the rules of the group that are not in the rule file, and are deleted.
+	Deleted []string `json:"deleted,omitempty"`
+	// Names of the recording rules, which are not imported.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// PrometheusRulesImportRuleDiff describes the differences between an existing rule and the imported one.
+type PrometheusRulesImportRuleDiff struct {
+	Title string   `json:"title"`
+	UID   string   `json:"uid"`
+	Diff  []string `json:"diff"`
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// in: path
@@ -374,23 +435,33 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record makes the rule a recording rule. Its condition is ignored, and the result of the
+	// query or expression From is written as the metric Metric instead.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"`
+	// IsPaused stops the evaluation of the rule and clears its state.
+	IsPaused bool `json:"is_paused" yaml:"is_paused"`
+	// DependsOn suppresses the notifications of the rule while an alert of the rule it depends on is firing.
+	DependsOn *models.AlertRuleDependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
-	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	ID              int64                       `json:"id" yaml:"id"`
+	OrgID           int64                       `json:"orgId" yaml:"orgId"`
+	Title           string                      `json:"title" yaml:"title"`
+	Condition       string                      `json:"condition" yaml:"condition"`
+	Data            []models.AlertQuery         `json:"data" yaml:"data"`
+	Updated         time.Time                   `json:"updated" yaml:"updated"`
+	IntervalSeconds int64                       `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version         int64                       `json:"version" yaml:"version"`
+	UID             string                      `json:"uid" yaml:"uid"`
+	NamespaceUID    string                      `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID     int64                       `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup       string                      `json:"rule_group" yaml:"rule_group"`
+	NoDataState     NoDataState                 `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState    ExecutionErrorState         `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance      models.Provenance           `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *models.Record              `json:"record,omitempty" yaml:"record,omitempty"`
+	IsPaused        bool                        `json:"is_paused" yaml:"is_paused"`
+	DependsOn       *models.AlertRuleDependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
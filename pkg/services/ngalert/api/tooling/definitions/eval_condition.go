@@ -13,6 +13,8 @@ type EvalAlertConditionCommand struct {
 	Condition string              `json:"condition"`
 	Data      []models.AlertQuery `json:"data"` // TODO yuri. Create API model for AlertQuery
 	Now       time.Time           `json:"now"`
+	// Debug returns the results of every query and expression, and the execution trace of the expressions.
+	Debug bool `json:"debug,omitempty"`
 }
 
 func (cmd *EvalAlertConditionCommand) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,59 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// swagger:route GET /api/v1/rules/history history RouteGetStateHistory
+//
+// Query the state history of alert rules. The most recent state transitions are returned first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistory
+//       400: ValidationError
+//       404: NotFound
+//       501: Failure
+
+// swagger:parameters RouteGetStateHistory
+type GetStateHistoryParams struct {
+	// Filter the state transitions to those of the alert rule with the UID.
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// Filter the state transitions to those of the alert instances with the label, in the format name=value.
+	// It can be repeated to filter by several labels.
+	// in: query
+	// required: false
+	Label []string `json:"label"`
+
+	// Filter the state transitions to those to the state: Normal, Alerting, Pending, NoData or Error.
+	// in: query
+	// required: false
+	State string `json:"state"`
+
+	// The start of the time range, in seconds since the Unix epoch. Defaults to a day before the end of the time range.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// The end of the time range, in seconds since the Unix epoch. Defaults to now.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of state transitions to return.
+	// in: query
+	// required: false
+	// default: 1000
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistory struct {
+	// A frame with a row per state transition, and the fields time, ruleUID, labels, previous, current, values and error.
+	Results *data.Frame `json:"results"`
+}
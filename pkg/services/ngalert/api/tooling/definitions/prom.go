@@ -107,6 +107,10 @@ type AlertingRule struct {
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
 	Alerts []*Alert `json:"alerts,omitempty"`
+	// DependsOn is the UID of the rule that the rule depends on, if any.
+	DependsOn string `json:"dependsOn,omitempty"`
+	// Suppressed is true if the alerts of the rule are suppressed because the rule that it depends on is firing.
+	Suppressed bool `json:"suppressed,omitempty"`
 	Rule
 }
 
@@ -101,6 +101,16 @@ type ProvisionedAlertRule struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
+	// Record makes the rule a recording rule. Its condition is ignored, and the result of the
+	// query or expression From is written as the metric Metric instead.
+	// example: {"metric": "instance:cpu_usage:avg", "from": "B"}
+	Record *models.Record `json:"record,omitempty"`
+	// IsPaused stops the evaluation of the rule and clears its state.
+	// example: false
+	IsPaused bool `json:"isPaused"`
+	// DependsOn suppresses the notifications of the rule while an alert of the rule it depends on is firing.
+	// example: {"uid": "database_down", "matchers": ["cluster=\"eu-west\""]}
+	DependsOn *models.AlertRuleDependency `json:"dependsOn,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
@@ -108,6 +118,10 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	condition := a.Condition
+	if a.Record != nil {
+		condition = a.Record.From
+	}
 	return models.AlertRule{
 		ID:           a.ID,
 		UID:          a.UID,
@@ -115,7 +129,7 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 		NamespaceUID: a.FolderUID,
 		RuleGroup:    a.RuleGroup,
 		Title:        a.Title,
-		Condition:    a.Condition,
+		Condition:    condition,
 		Data:         a.Data,
 		Updated:      a.Updated,
 		NoDataState:  a.NoDataState,
@@ -123,6 +137,9 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 		For:          forDur,
 		Annotations:  a.Annotations,
 		Labels:       a.Labels,
+		Record:       a.Record,
+		IsPaused:     a.IsPaused,
+		DependsOn:    a.DependsOn,
 	}, nil
 }
 
@@ -143,6 +160,9 @@ func NewAlertRule(rule models.AlertRule, provenance models.Provenance) Provision
 		Annotations:  rule.Annotations,
 		Labels:       rule.Labels,
 		Provenance:   provenance,
+		Record:       rule.Record,
+		IsPaused:     rule.IsPaused,
+		DependsOn:    rule.DependsOn,
 	}
 }
 
@@ -0,0 +1,150 @@
+package definitions
+
+import (
+	"time"
+
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows provisioning stable RouteGetMaintenanceWindows
+//
+// Get all the maintenance windows.
+//
+//     Responses:
+//       200: MaintenanceWindows
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteGetMaintenanceWindow
+//
+// Get a maintenance window.
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/maintenance-windows provisioning stable RoutePostMaintenanceWindow
+//
+// Create a new maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MaintenanceWindow
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RoutePutMaintenanceWindow
+//
+// Replace an existing maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteDeleteMaintenanceWindow
+//
+// Delete a maintenance window.
+//
+//     Responses:
+//       204: description: The maintenance window was deleted successfully.
+
+// swagger:parameters RouteGetMaintenanceWindow RoutePutMaintenanceWindow RouteDeleteMaintenanceWindow
+type MaintenanceWindowUIDReference struct {
+	// Maintenance window UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostMaintenanceWindow RoutePutMaintenanceWindow
+type MaintenanceWindowPayload struct {
+	// in:body
+	Body MaintenanceWindow
+}
+
+// swagger:model
+type MaintenanceWindows []MaintenanceWindow
+
+// swagger:model
+type MaintenanceWindow struct {
+	UID string `json:"uid" yaml:"uid"`
+	// required: true
+	// minLength: 1
+	// maxLength: 190
+	// example: Weekly deployment
+	Title string `json:"title" yaml:"title"`
+	// Mode is what happens to the rules in the scope of the window when it is active. SkipEvaluation skips their
+	// evaluation. Maintenance evaluates them, but their alerts get the state reason Maintenance and are not notified.
+	// required: true
+	// enum: SkipEvaluation,Maintenance
+	Mode models.MaintenanceMode `json:"mode" yaml:"mode"`
+	// StartsAt and EndsAt are the period of a one-off window. They are optional for a recurring window.
+	// example: 2022-10-13T20:00:00Z
+	StartsAt *time.Time `json:"startsAt,omitempty" yaml:"startsAt,omitempty"`
+	// example: 2022-10-13T22:00:00Z
+	EndsAt *time.Time `json:"endsAt,omitempty" yaml:"endsAt,omitempty"`
+	// TimeIntervals are the recurrences of a recurring window, with the same syntax as those of mute timings.
+	TimeIntervals []timeinterval.TimeInterval `json:"timeIntervals,omitempty" yaml:"timeIntervals,omitempty"`
+	// FolderUID restricts the window to the rules of the folder.
+	// example: project_x
+	FolderUID string `json:"folderUID,omitempty" yaml:"folderUID,omitempty"`
+	// RuleGroup restricts the window to the rules of the group. It requires the folder.
+	// example: eval_group_1
+	RuleGroup string `json:"ruleGroup,omitempty" yaml:"ruleGroup,omitempty"`
+	// Matchers restrict the window to the rules whose labels match, in SkipEvaluation mode, or to the alerts whose
+	// labels match, in Maintenance mode.
+	Matchers ObjectMatchers `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	// readonly: true
+	Updated time.Time `json:"updated,omitempty" yaml:"-"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"-"`
+}
+
+// UpstreamModel returns the maintenance window of the organization.
+func (w *MaintenanceWindow) UpstreamModel(orgID int64) models.MaintenanceWindow {
+	result := models.MaintenanceWindow{
+		OrgID:         orgID,
+		UID:           w.UID,
+		Title:         w.Title,
+		Mode:          w.Mode,
+		TimeIntervals: w.TimeIntervals,
+		FolderUID:     w.FolderUID,
+		RuleGroup:     w.RuleGroup,
+		Matchers:      amlabels.Matchers(w.Matchers),
+	}
+	if w.StartsAt != nil {
+		result.StartsAt = *w.StartsAt
+	}
+	if w.EndsAt != nil {
+		result.EndsAt = *w.EndsAt
+	}
+	return result
+}
+
+func NewMaintenanceWindow(window models.MaintenanceWindow, provenance models.Provenance) MaintenanceWindow {
+	result := MaintenanceWindow{
+		UID:           window.UID,
+		Title:         window.Title,
+		Mode:          window.Mode,
+		TimeIntervals: window.TimeIntervals,
+		FolderUID:     window.FolderUID,
+		RuleGroup:     window.RuleGroup,
+		Matchers:      ObjectMatchers(window.Matchers),
+		Updated:       window.Updated,
+		Provenance:    provenance,
+	}
+	if !window.StartsAt.IsZero() {
+		startsAt := window.StartsAt
+		result.StartsAt = &startsAt
+	}
+	if !window.EndsAt.IsZero() {
+		endsAt := window.EndsAt
+		result.EndsAt = &endsAt
+	}
+	return result
+}
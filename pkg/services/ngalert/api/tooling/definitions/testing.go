@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -53,6 +55,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing BacktestConfig
+//
+// Test rule against historical data. The rule is evaluated at every interval of the time range, and the states of its alert instances are returned. Nothing is persisted.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -83,8 +99,46 @@ type EvalQueriesRequest struct {
 type EvalQueriesPayload struct {
 	Data []models.AlertQuery `json:"data"`
 	Now  time.Time           `json:"now"`
+	// Debug returns the execution trace of the expressions along with the results, and the results
+	// of the queries and expressions that were executed before an error.
+	Debug bool `json:"debug,omitempty"`
 }
 
+// swagger:parameters BacktestConfig
+type BacktestConfigRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// The start of the time range of the backtest.
+	From time.Time `json:"from"`
+	// The end of the time range of the backtest.
+	To time.Time `json:"to"`
+	// The interval between two evaluations, e.g. 1m. Defaults to the default evaluation interval of alert rules.
+	Interval model.Duration `json:"interval,omitempty"`
+
+	Condition string              `json:"condition"`
+	Data      []models.AlertQuery `json:"data"`
+
+	Title       string            `json:"title"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	For model.Duration `json:"for,omitempty"`
+	// The state of the alert instances when the queries return no data. Defaults to NoData.
+	NoDataState NoDataState `json:"no_data_state,omitempty"`
+	// The state of the alert instances when the evaluation fails. Defaults to Error.
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
+}
+
+// BacktestResult is a frame with the field "time", which contains the times of the evaluations, and a field per alert
+// instance, which has the labels of the instance and contains its state at every evaluation.
+//
+// swagger:model
+type BacktestResult = data.Frame
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
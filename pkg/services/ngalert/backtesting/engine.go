@@ -0,0 +1,136 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// MaxEvaluations is the maximum number of evaluations of a backtest, which is two weeks at an interval of a minute.
+const MaxEvaluations = 20160
+
+var ErrInvalidInputData = errors.New("invalid input data")
+
+type stateManager interface {
+	ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *models.AlertRule, results eval.Results, extraLabels data.Labels) []*state.State
+}
+
+// Engine evaluates an alert rule at every interval of a time range in the past, and returns the states of the alert
+// instances. Nothing is persisted: the states are processed by a state manager that is created for every backtest.
+type Engine struct {
+	evalFactory        eval.EvaluatorFactory
+	createStateManager func() stateManager
+}
+
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
+	return &Engine{
+		evalFactory: evalFactory,
+		createStateManager: func() stateManager {
+			return state.NewManager(nil, appUrl, nil, &state.NotAvailableImageService{}, clock.New(), nil)
+		},
+	}
+}
+
+// Test evaluates the rule at every interval from the start to the end of the time range, both included.
+// It returns a frame with the field "time", which contains the times of the evaluations, and a field per alert
+// instance, which contains its state at every evaluation, or null if the instance did not exist at that time.
+func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	if rule.IntervalSeconds <= 0 {
+		return nil, fmt.Errorf("%w: interval must be greater than 0", ErrInvalidInputData)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: the start of the time range must be before its end", ErrInvalidInputData)
+	}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	length := int(to.Sub(from)/interval) + 1
+	if length > MaxEvaluations {
+		return nil, fmt.Errorf("%w: the time range requires %d evaluations, but at most %d are allowed, use a shorter time range or a longer interval", ErrInvalidInputData, length, MaxEvaluations)
+	}
+
+	evaluator, err := e.evalFactory.Create(eval.Context(ctx, user), rule.GetEvalCondition())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInputData, err)
+	}
+
+	// the same labels as the scheduler, except the title of the folder, as the rule is not in a folder
+	extraLabels := data.Labels{
+		models.NamespaceUIDLabel:       rule.NamespaceUID,
+		prometheusModel.AlertNameLabel: rule.Title,
+		models.RuleUIDLabel:            rule.UID,
+	}
+
+	manager := e.createStateManager()
+	times := make([]time.Time, 0, length)
+	series := make(map[string]*instanceStates)
+	for i := 0; i < length; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		now := from.Add(time.Duration(i) * interval)
+		start := time.Now()
+		results, err := evaluator.Evaluate(ctx, now)
+		if err != nil {
+			results = eval.Results{eval.NewResultFromError(err, now, time.Since(start))}
+		}
+		times = append(times, now)
+
+		for _, s := range manager.ProcessEvalResults(ctx, now, rule, results, extraLabels) {
+			states, ok := series[s.CacheID]
+			if !ok {
+				states = &instanceStates{labels: publicLabels(s.Labels), values: make([]*string, length)}
+				series[s.CacheID] = states
+			}
+			v := state.FormatStateAndReason(s.State, s.StateReason)
+			states.values[i] = &v
+		}
+	}
+	return newTimelineFrame(times, series), nil
+}
+
+type instanceStates struct {
+	labels data.Labels
+	values []*string
+}
+
+func newTimelineFrame(times []time.Time, series map[string]*instanceStates) *data.Frame {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	// the fields are sorted to return the same frame for the same results
+	sort.Strings(keys)
+
+	fields := make([]*data.Field, 0, len(series)+1)
+	fields = append(fields, data.NewField("time", nil, times))
+	for _, key := range keys {
+		states := series[key]
+		fields = append(fields, data.NewField("state", states.labels, states.values))
+	}
+	return data.NewFrame("backtesting", fields...)
+}
+
+// publicLabels returns the labels without the private labels, such as the UID of the rule, which is meaningless in
+// a backtest.
+func publicLabels(labels data.Labels) data.Labels {
+	result := make(data.Labels, len(labels))
+	for k, v := range labels {
+		if strings.HasPrefix(k, "__") && strings.HasSuffix(k, "__") {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// fakeConditionEvaluator returns the results of the evaluation time.
+type fakeConditionEvaluator struct {
+	results   func(now time.Time) (eval.Results, error)
+	evaluated []time.Time
+}
+
+func (f *fakeConditionEvaluator) EvaluateRaw(_ context.Context, _ time.Time) (*backend.QueryDataResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeConditionEvaluator) Evaluate(_ context.Context, now time.Time) (eval.Results, error) {
+	f.evaluated = append(f.evaluated, now)
+	return f.results(now)
+}
+
+func TestEngine_Test(t *testing.T) {
+	from := time.Date(2022, 3, 10, 14, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "backtesting",
+		Title:           "test",
+		Condition:       "A",
+		IntervalSeconds: 60,
+		For:             time.Minute,
+		Labels:          map[string]string{"team": "a"},
+		NoDataState:     models.NoData,
+		ExecErrState:    models.ErrorErrState,
+	}
+	result := func(now time.Time, state eval.State, labels data.Labels) eval.Result {
+		return eval.Result{Instance: labels, State: state, EvaluatedAt: now}
+	}
+
+	t.Run("should return the states of every instance at every evaluation", func(t *testing.T) {
+		evaluator := &fakeConditionEvaluator{results: func(now time.Time) (eval.Results, error) {
+			step := int(now.Sub(from) / time.Minute)
+			switch step {
+			case 0:
+				return eval.Results{result(now, eval.Normal, data.Labels{"host": "a"})}, nil
+			case 1, 2:
+				return eval.Results{
+					result(now, eval.Alerting, data.Labels{"host": "a"}),
+					result(now, eval.Normal, data.Labels{"host": "b"}),
+				}, nil
+			default:
+				return nil, errors.New("failed to query data")
+			}
+		}}
+		engine := NewEngine(nil, eval_mocks.NewEvaluatorFactory(evaluator))
+
+		frame, err := engine.Test(context.Background(), &user.SignedInUser{OrgID: 1}, rule, from, from.Add(3*time.Minute))
+		require.NoError(t, err)
+
+		require.Equal(t, []time.Time{from, from.Add(time.Minute), from.Add(2 * time.Minute), from.Add(3 * time.Minute)}, evaluator.evaluated)
+		require.Equal(t, 4, frame.Rows())
+		require.Equal(t, "time", frame.Fields[0].Name)
+
+		states := make(map[string][]*string)
+		for _, field := range frame.Fields[1:] {
+			require.Equal(t, "state", field.Name)
+			require.Equal(t, "a", field.Labels["team"])
+			require.Equal(t, "test", field.Labels["alertname"])
+			require.NotContains(t, field.Labels, models.RuleUIDLabel)
+			values := make([]*string, 0, field.Len())
+			for i := 0; i < field.Len(); i++ {
+				values = append(values, field.At(i).(*string))
+			}
+			states[field.Labels["host"]] = values
+		}
+		require.Len(t, states, 3)
+		require.Equal(t, []*string{ptr("Normal"), ptr("Pending"), ptr("Alerting"), nil}, states["a"])
+		require.Equal(t, []*string{nil, ptr("Normal"), ptr("Normal"), nil}, states["b"])
+		require.Equal(t, []*string{nil, nil, nil, ptr("Error")}, states[""])
+	})
+
+	t.Run("should fail if the time range is not valid", func(t *testing.T) {
+		engine := NewEngine(nil, eval_mocks.NewEvaluatorFactory(&fakeConditionEvaluator{}))
+
+		_, err := engine.Test(context.Background(), &user.SignedInUser{OrgID: 1}, rule, from, from)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+
+		_, err = engine.Test(context.Background(), &user.SignedInUser{OrgID: 1}, rule, from, from.Add(MaxEvaluations*time.Minute))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should fail if the condition is not valid", func(t *testing.T) {
+		engine := NewEngine(nil, eval_mocks.NewFailingEvaluatorFactory(nil))
+
+		_, err := engine.Test(context.Background(), &user.SignedInUser{OrgID: 1}, rule, from, from.Add(time.Minute))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+func ptr(s string) *string {
+	return &s
+}
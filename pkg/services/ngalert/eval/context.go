@@ -3,6 +3,8 @@ package eval
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -10,6 +12,27 @@ import (
 type EvaluationContext struct {
 	Ctx  context.Context
 	User *user.SignedInUser
+
+	// ActiveResults provides the labels of the results that were pending or alerting at the
+	// previous evaluation of the rule. It is optional, and is used by threshold expressions
+	// that have a recovery threshold.
+	ActiveResults ActiveResultsReader
+
+	// Debug makes the evaluator return the results of every query and expression of the condition
+	// along with the execution trace of the expression pipeline. See expr.Service.ExecutePipelineDebug.
+	Debug bool
+}
+
+// ActiveResultsReader reads the labels of the results that are currently pending or alerting.
+type ActiveResultsReader interface {
+	Read() []data.Labels
+}
+
+// ActiveResultsReaderFunc is an adapter to use a function as an ActiveResultsReader.
+type ActiveResultsReaderFunc func() []data.Labels
+
+func (f ActiveResultsReaderFunc) Read() []data.Labels {
+	return f()
 }
 
 func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
@@ -18,3 +41,13 @@ func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
 		User: user,
 	}
 }
+
+// ContextWithActiveResults creates an EvaluationContext that provides the results that
+// were active at the previous evaluation to the expressions.
+func ContextWithActiveResults(ctx context.Context, user *user.SignedInUser, reader ActiveResultsReader) EvaluationContext {
+	return EvaluationContext{
+		Ctx:           ctx,
+		User:          user,
+		ActiveResults: reader,
+	}
+}
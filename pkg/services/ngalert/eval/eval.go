@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -45,6 +46,7 @@ type conditionEvaluator struct {
 	expressionService *expr.Service
 	condition         models.Condition
 	evalTimeout       time.Duration
+	debug             bool
 }
 
 func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (resp *backend.QueryDataResponse, err error) {
@@ -66,6 +68,9 @@ func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (re
 		defer cancel()
 		execCtx = timeoutCtx
 	}
+	if r.debug {
+		return r.expressionService.ExecutePipelineDebug(execCtx, now, r.pipeline)
+	}
 	return r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
 }
 
@@ -75,8 +80,13 @@ func (r *conditionEvaluator) Evaluate(ctx context.Context, now time.Time) (Resul
 	if err != nil {
 		return nil, err
 	}
-	execResults := queryDataResponseToExecutionResults(r.condition, response)
-	return evaluateExecutionResult(execResults, now), nil
+	return ResponseToResults(r.condition, response, now), nil
+}
+
+// ResponseToResults converts the response of the evaluation of the condition to Results.
+func ResponseToResults(condition models.Condition, response *backend.QueryDataResponse, now time.Time) Results {
+	execResults := queryDataResponseToExecutionResults(condition, response)
+	return evaluateExecutionResult(execResults, now)
 }
 
 type evaluatorImpl struct {
@@ -232,19 +242,26 @@ func buildDatasourceHeaders(ctx EvaluationContext) map[string]string {
 }
 
 // getExprRequest validates the condition, gets the datasource information and creates an expr.Request from it.
-func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheService datasources.CacheService) (*expr.Request, error) {
+func getExprRequest(ctx EvaluationContext, queries []models.AlertQuery, dsCacheService datasources.CacheService) (*expr.Request, error) {
 	req := &expr.Request{
 		OrgId:   ctx.User.OrgID,
 		Headers: buildDatasourceHeaders(ctx),
 	}
 
-	datasources := make(map[string]*datasources.DataSource, len(data))
+	datasources := make(map[string]*datasources.DataSource, len(queries))
+	var activeResults []data.Labels
 
-	for _, q := range data {
+	for _, q := range queries {
 		model, err := q.GetModel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
 		}
+		if ctx.ActiveResults != nil && expr.IsDataSource(q.DatasourceUID) {
+			model, err = setActiveResults(model, ctx.ActiveResults, &activeResults)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set active results to '%s': %w", q.RefID, err)
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from '%s': %w", q.RefID, err)
@@ -281,6 +298,22 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 	return req, nil
 }
 
+// setActiveResults adds the labels of the active results to the model if it is a threshold
+// expression with a recovery threshold. The results are read from the reader only once.
+func setActiveResults(model json.RawMessage, reader ActiveResultsReader, activeResults *[]data.Labels) (json.RawMessage, error) {
+	ok, err := expr.IsThresholdWithRecovery(model)
+	if err != nil || !ok {
+		return model, err
+	}
+	if *activeResults == nil {
+		*activeResults = reader.Read()
+		if *activeResults == nil {
+			*activeResults = []data.Labels{}
+		}
+	}
+	return expr.SetThresholdActiveLabels(model, *activeResults)
+}
+
 type NumberValueCapture struct {
 	Var    string // RefID
 	Labels data.Labels
@@ -617,6 +650,7 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 				expressionService: e.expressionService,
 				condition:         condition,
 				evalTimeout:       e.evaluationTimeout,
+				debug:             ctx.Debug,
 			}, nil
 		}
 		conditions = append(conditions, node.RefID())
@@ -2,6 +2,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -455,3 +456,43 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExprRequestActiveResults(t *testing.T) {
+	threshold := models.AlertQuery{
+		RefID:         "B",
+		DatasourceUID: expr.DatasourceUID,
+		Model: []byte(`{
+			"expression": "A",
+			"type": "threshold",
+			"conditions": [{
+				"evaluator": {"type": "gt", "params": [80]},
+				"recoveryEvaluator": {"type": "lt", "params": [70]}
+			}]
+		}`),
+	}
+
+	reads := 0
+	reader := ActiveResultsReaderFunc(func() []data.Labels {
+		reads++
+		return []data.Labels{{"host": "a"}}
+	})
+	ctx := ContextWithActiveResults(context.Background(), &user.SignedInUser{}, reader)
+
+	req, err := getExprRequest(ctx, []models.AlertQuery{threshold, threshold}, &fakes.FakeCacheService{})
+	require.NoError(t, err)
+	require.Len(t, req.Queries, 2)
+	require.Equal(t, 1, reads)
+	for _, q := range req.Queries {
+		model := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(q.JSON, &model))
+		require.Equal(t, []interface{}{map[string]interface{}{"host": "a"}}, model["activeLabels"])
+	}
+
+	t.Run("should not change the model without a reader", func(t *testing.T) {
+		req, err := getExprRequest(Context(context.Background(), &user.SignedInUser{}), []models.AlertQuery{threshold}, &fakes.FakeCacheService{})
+		require.NoError(t, err)
+		model := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(req.Queries[0].JSON, &model))
+		require.NotContains(t, model, "activeLabels")
+	})
+}
@@ -63,9 +63,18 @@ type MultiOrgAlertmanager struct {
 	Registerer               prometheus.Registerer
 	ActiveConfigurations     prometheus.Gauge
 	DiscoveredConfigurations prometheus.Gauge
+	Integration              *Integration
 	registries               *OrgRegistries
 }
 
+// Integration has the metrics of the notification attempts of the integrations of the Alertmanagers of all
+// organizations.
+type Integration struct {
+	Attempts *prometheus.CounterVec
+	Retries  *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+}
+
 type API struct {
 	RequestDuration *prometheus.HistogramVec
 }
@@ -73,6 +82,8 @@ type API struct {
 type Alertmanager struct {
 	Registerer prometheus.Registerer
 	*metrics.Alerts
+	// Integration is shared by the Alertmanagers of all organizations, it is nil if the metrics are not collected.
+	Integration *Integration
 }
 
 type State struct {
@@ -115,9 +126,14 @@ func NewAlertmanagerMetrics(r prometheus.Registerer) *Alertmanager {
 	}
 }
 
-// RemoveOrgRegistry removes the *prometheus.Registry for the specified org. It is safe to call concurrently.
+// RemoveOrgRegistry removes the *prometheus.Registry for the specified org, and the metrics of the integrations of
+// the org. It is safe to call concurrently.
 func (moa *MultiOrgAlertmanager) RemoveOrgRegistry(id int64) {
 	moa.registries.RemoveOrgRegistry(id)
+	org := prometheus.Labels{"org": fmt.Sprint(id)}
+	moa.Integration.Attempts.DeletePartialMatch(org)
+	moa.Integration.Retries.DeletePartialMatch(org)
+	moa.Integration.Duration.DeletePartialMatch(org)
 }
 
 // GetOrCreateOrgRegistry gets or creates a *prometheus.Registry for the specified org. It is safe to call concurrently.
@@ -250,6 +266,40 @@ func newMultiOrgAlertmanagerMetrics(r prometheus.Registerer) *MultiOrgAlertmanag
 			Name:      "active_configurations",
 			Help:      "The number of active Alertmanager configurations.",
 		}),
+		Integration: newIntegrationMetrics(r),
+	}
+}
+
+func newIntegrationMetrics(r prometheus.Registerer) *Integration {
+	return &Integration{
+		Attempts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "notification_attempts_total",
+				Help:      "The total number of attempts to send notifications to integrations, retries included.",
+			},
+			[]string{"org", "integration", "result"},
+		),
+		Retries: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "notification_retries_total",
+				Help:      "The total number of attempts to send notifications to integrations after a failed attempt.",
+			},
+			[]string{"org", "integration"},
+		),
+		Duration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "notification_attempt_duration_seconds",
+				Help:      "The duration of the attempts to send notifications to integrations.",
+				Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"org", "integration"},
+		),
 	}
 }
 
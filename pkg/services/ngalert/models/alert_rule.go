@@ -11,6 +11,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/util/cmputil"
 )
@@ -113,10 +115,17 @@ const (
 
 	ValuesAnnotation      = "__values__"
 	ValueStringAnnotation = "__value_string__"
+
+	// ImportSourceAnnotation is the annotation of the rules imported from the rule files of another system, such as
+	// Prometheus. Its value is the system. An import only updates the rules that were imported from the same system.
+	ImportSourceAnnotation = "__importSource__"
 )
 
 var (
 	StateReasonMissingSeries = "MissingSeries"
+	// StateReasonSuppressed is the suppression reason of the alert instances of a rule whose parent rule is firing.
+	// See AlertRuleDependency.
+	StateReasonSuppressed = "Suppressed"
 )
 
 var (
@@ -129,6 +138,7 @@ var (
 		DashboardUIDAnnotation: {},
 		PanelIDAnnotation:      {},
 		ImageTokenAnnotation:   {},
+		ImportSourceAnnotation: {},
 	}
 )
 
@@ -164,6 +174,106 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is set if the rule is a recording rule. See Record.
+	Record *Record `xorm:"jsonb record"`
+	// IsPaused is set if the rule must not be evaluated. The state of a paused rule is cleared.
+	IsPaused bool `xorm:"is_paused"`
+	// DependsOn is set if the rule depends on another rule. See AlertRuleDependency.
+	DependsOn *AlertRuleDependency `xorm:"jsonb depends_on"`
+}
+
+// AlertRuleDependency is the dependency of a rule on a parent rule of the same organization. When an alert instance
+// of the parent rule that matches the matchers is firing, the alert instances of the rule get the suppression reason
+// Suppressed and are not notified.
+type AlertRuleDependency struct {
+	// UID is the UID of the parent rule.
+	UID string `json:"uid" yaml:"uid"`
+	// Matchers select the alert instances of the parent rule, with the syntax of the matchers of the Alertmanager,
+	// for example instance="db-1". Any firing alert instance of the parent rule is selected if there are none.
+	Matchers []string `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+}
+
+// Validate checks that the parent rule is not the rule itself and that the matchers can be parsed.
+func (d *AlertRuleDependency) Validate(ruleUID string) error {
+	if d.UID == "" {
+		return fmt.Errorf("%w: the UID of the rule that the rule depends on is not specified", ErrAlertRuleFailedValidation)
+	}
+	if d.UID == ruleUID {
+		return fmt.Errorf("%w: a rule cannot depend on itself", ErrAlertRuleFailedValidation)
+	}
+	if _, err := d.ParseMatchers(); err != nil {
+		return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
+
+// ValidateRuleDependencies checks that the rules depend on rules that exist and that the dependencies do not form a
+// cycle. rulesByUID contains all the rules of the organization, including the rules.
+func ValidateRuleDependencies(rules []*AlertRule, rulesByUID map[string]*AlertRule) error {
+	for _, rule := range rules {
+		if rule.DependsOn == nil {
+			continue
+		}
+		parent, ok := rulesByUID[rule.DependsOn.UID]
+		if !ok {
+			return fmt.Errorf("%w: rule %q depends on rule %s that does not exist", ErrAlertRuleFailedValidation, rule.Title, rule.DependsOn.UID)
+		}
+		visited := map[string]struct{}{rule.UID: {}}
+		for parent.DependsOn != nil {
+			if _, ok := visited[parent.UID]; ok {
+				return fmt.Errorf("%w: the dependencies of rule %q form a cycle", ErrAlertRuleFailedValidation, rule.Title)
+			}
+			visited[parent.UID] = struct{}{}
+			if parent, ok = rulesByUID[parent.DependsOn.UID]; !ok {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ParseMatchers returns the parsed matchers.
+func (d *AlertRuleDependency) ParseMatchers() (amlabels.Matchers, error) {
+	result := make(amlabels.Matchers, 0, len(d.Matchers))
+	for _, s := range d.Matchers {
+		m, err := amlabels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q of the rule dependency: %w", s, err)
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// Record describes a recording rule. The result of the query or expression From is written
+// as the metric Metric at every evaluation, and the rule does not produce alerts.
+type Record struct {
+	// Metric is the name of the metric the result is written to.
+	Metric string `json:"metric" yaml:"metric"`
+	// From is the RefID of the query or expression whose result is written.
+	From string `json:"from" yaml:"from"`
+}
+
+// IsRecordingRule returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != nil
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name, and that From
+// is the RefID of one of the queries or expressions of data.
+func (r *Record) Validate(data []AlertQuery) error {
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: invalid metric name %q of recording rule", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: the query or expression to record is not specified", ErrAlertRuleFailedValidation)
+	}
+	for _, q := range data {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: recording rule query or expression %s is not found", ErrAlertRuleFailedValidation, r.From)
 }
 
 // GetDashboardUID returns the DashboardUID or "".
@@ -205,7 +315,15 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// GetEvalCondition returns the condition of the rule. The condition of a recording rule
+// is the query or expression of its record.
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.IsRecordingRule() {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
@@ -344,6 +462,9 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	Record      *Record              `xorm:"jsonb record"`
+	IsPaused    bool                 `xorm:"is_paused"`
+	DependsOn   *AlertRuleDependency `xorm:"jsonb depends_on"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -437,7 +558,7 @@ func (c Condition) IsValid() bool {
 
 // PatchPartialAlertRule patches `ruleToPatch` by `existingRule` following the rule that if a field of `ruleToPatch` is empty or has the default value, it is populated by the value of the corresponding field from `existingRule`.
 // There are several exceptions:
-// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
+// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations, AlertRule.Labels, AlertRule.Record, AlertRule.IsPaused and AlertRule.DependsOn
 // 2. There are fields that are patched together:
 //   - AlertRule.Condition and AlertRule.Data
 //
@@ -597,3 +597,61 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestAlertRuleDependencyValidate(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		dependency AlertRuleDependency
+		valid      bool
+	}{
+		{desc: "without matchers", dependency: AlertRuleDependency{UID: "parent"}, valid: true},
+		{desc: "with matchers", dependency: AlertRuleDependency{UID: "parent", Matchers: []string{`cluster="eu"`, `instance=~"db-.+"`}}, valid: true},
+		{desc: "without UID", dependency: AlertRuleDependency{}, valid: false},
+		{desc: "on the rule itself", dependency: AlertRuleDependency{UID: "rule"}, valid: false},
+		{desc: "with an invalid matcher", dependency: AlertRuleDependency{UID: "parent", Matchers: []string{`cluster=~"("`}}, valid: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.dependency.Validate("rule")
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			}
+		})
+	}
+}
+
+func TestValidateRuleDependencies(t *testing.T) {
+	newRule := func(uid, parent string) *AlertRule {
+		rule := &AlertRule{UID: uid, Title: uid}
+		if parent != "" {
+			rule.DependsOn = &AlertRuleDependency{UID: parent}
+		}
+		return rule
+	}
+	byUID := func(rules ...*AlertRule) map[string]*AlertRule {
+		result := make(map[string]*AlertRule, len(rules))
+		for _, rule := range rules {
+			result[rule.UID] = rule
+		}
+		return result
+	}
+
+	t.Run("should accept a chain of dependencies", func(t *testing.T) {
+		a, b, c := newRule("a", ""), newRule("b", "a"), newRule("c", "b")
+		require.NoError(t, ValidateRuleDependencies([]*AlertRule{b, c}, byUID(a, b, c)))
+	})
+
+	t.Run("should reject a dependency on a rule that does not exist", func(t *testing.T) {
+		b := newRule("b", "a")
+		require.ErrorIs(t, ValidateRuleDependencies([]*AlertRule{b}, byUID(b)), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should reject a cycle", func(t *testing.T) {
+		a, b, c := newRule("a", "c"), newRule("b", "a"), newRule("c", "b")
+		err := ValidateRuleDependencies([]*AlertRule{a}, byUID(a, b, c))
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cycle")
+	})
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStateHistoryQueryNotSupported is returned by the state history backends that cannot be queried.
+var ErrStateHistoryQueryNotSupported = errors.New("the state history backend does not support queries")
+
+// HistoryQuery is the query of the state transitions of the alert instances of an organization.
+type HistoryQuery struct {
+	OrgID int64
+	// RuleUID filters the transitions to the alert instances of a rule. It is optional.
+	RuleUID string
+	// RuleUIDs filters the transitions to the alert instances of the rules, if it is not nil. If it is empty, there are
+	// no transitions.
+	RuleUIDs []string
+	// Labels filters the transitions to the alert instances that have all the labels with the same values.
+	Labels map[string]string
+	// State filters the transitions to the ones to the state. It is optional.
+	State InstanceStateType
+	// From and To are the inclusive time range of the transitions.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of transitions to return. The most recent transitions are returned first.
+	Limit int
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	// ErrMaintenanceWindowNotFound is returned when the maintenance window does not exist.
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	// ErrMaintenanceWindowFailedValidation is returned when the maintenance window is not valid.
+	ErrMaintenanceWindowFailedValidation = errors.New("invalid maintenance window")
+)
+
+// MaintenanceMode is what happens to the alert rules in the scope of an active maintenance window.
+type MaintenanceMode string
+
+const (
+	// MaintenanceModeSkipEvaluation skips the evaluation of the rules. The states of their alert instances do not change.
+	MaintenanceModeSkipEvaluation MaintenanceMode = "SkipEvaluation"
+	// MaintenanceModeMaintenance evaluates the rules, but the alert instances get the state reason "Maintenance".
+	// Their transitions are not recorded in the state history and they are not sent to the Alertmanager.
+	MaintenanceModeMaintenance MaintenanceMode = "Maintenance"
+)
+
+// MaintenanceWindowMaxTitleLength is the maximum length of the title of a maintenance window.
+const MaintenanceWindowMaxTitleLength = 190
+
+// StateReasonMaintenance is the state reason of the alert instances in the scope of a maintenance window.
+const StateReasonMaintenance = "Maintenance"
+
+// MaintenanceWindow is a one-off or recurring period of time during which the alert rules in its scope are not
+// evaluated, or their alert instances are not notified.
+type MaintenanceWindow struct {
+	ID    int64           `xorm:"pk autoincr 'id'"`
+	OrgID int64           `xorm:"org_id"`
+	UID   string          `xorm:"uid"`
+	Title string          `xorm:"title"`
+	Mode  MaintenanceMode `xorm:"mode"`
+	// StartsAt and EndsAt are the period of a one-off window. For a recurring window, they are optional and bound the
+	// period during which the time intervals are active.
+	StartsAt time.Time `xorm:"starts_at"`
+	EndsAt   time.Time `xorm:"ends_at"`
+	// TimeIntervals are the recurrences of a recurring window. They have the same syntax as the time intervals of mute
+	// timings.
+	TimeIntervals []timeinterval.TimeInterval `xorm:"time_intervals"`
+	// FolderUID, RuleGroup and Matchers are the scope of the window. The scope of a window without them is every rule
+	// of the organization.
+	FolderUID string            `xorm:"folder_uid"`
+	RuleGroup string            `xorm:"rule_group"`
+	Matchers  amlabels.Matchers `xorm:"matchers"`
+	Updated   time.Time         `xorm:"updated"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (w *MaintenanceWindow) TableName() string {
+	return "alert_maintenance_window"
+}
+
+func (w *MaintenanceWindow) ResourceType() string {
+	return "maintenanceWindow"
+}
+
+func (w *MaintenanceWindow) ResourceID() string {
+	return w.UID
+}
+
+// Validate returns ErrMaintenanceWindowFailedValidation if the window is not valid.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrMaintenanceWindowFailedValidation)
+	}
+	if len(w.Title) > MaintenanceWindowMaxTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", ErrMaintenanceWindowFailedValidation, MaintenanceWindowMaxTitleLength)
+	}
+	switch w.Mode {
+	case MaintenanceModeSkipEvaluation, MaintenanceModeMaintenance:
+	default:
+		return fmt.Errorf("%w: unknown mode '%s', must be '%s' or '%s'", ErrMaintenanceWindowFailedValidation, w.Mode, MaintenanceModeSkipEvaluation, MaintenanceModeMaintenance)
+	}
+	if len(w.TimeIntervals) == 0 && (w.StartsAt.IsZero() || w.EndsAt.IsZero()) {
+		return fmt.Errorf("%w: a one-off window requires a start and an end, a recurring window requires time intervals", ErrMaintenanceWindowFailedValidation)
+	}
+	if !w.StartsAt.IsZero() && !w.EndsAt.IsZero() && !w.StartsAt.Before(w.EndsAt) {
+		return fmt.Errorf("%w: the start must be before the end", ErrMaintenanceWindowFailedValidation)
+	}
+	if w.RuleGroup != "" && w.FolderUID == "" {
+		return fmt.Errorf("%w: a rule group requires a folder", ErrMaintenanceWindowFailedValidation)
+	}
+	return nil
+}
+
+// IsActive returns true if the window is active at the time.
+func (w *MaintenanceWindow) IsActive(t time.Time) bool {
+	if !w.StartsAt.IsZero() && t.Before(w.StartsAt) {
+		return false
+	}
+	if !w.EndsAt.IsZero() && !t.Before(w.EndsAt) {
+		return false
+	}
+	if len(w.TimeIntervals) == 0 {
+		return true
+	}
+	for _, interval := range w.TimeIntervals {
+		// the time intervals are in UTC unless they have a location, as those of mute timings
+		if interval.ContainsTime(t.UTC()) {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesTo returns true if the folder and the rule group of the rule are in the scope of the window.
+// The matchers are not considered, see MatchesLabels.
+func (w *MaintenanceWindow) AppliesTo(rule *AlertRule) bool {
+	if w.OrgID != rule.OrgID {
+		return false
+	}
+	if w.FolderUID != "" && w.FolderUID != rule.NamespaceUID {
+		return false
+	}
+	return w.RuleGroup == "" || w.RuleGroup == rule.RuleGroup
+}
+
+// MatchesLabels returns true if the labels match all the matchers of the window.
+func (w *MaintenanceWindow) MatchesLabels(lbs map[string]string) bool {
+	if len(w.Matchers) == 0 {
+		return true
+	}
+	set := make(model.LabelSet, len(lbs))
+	for k, v := range lbs {
+		set[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return w.Matchers.Matches(set)
+}
@@ -158,6 +158,12 @@ func WithTitle(title string) AlertRuleMutator {
 	}
 }
 
+func WithIsPaused(paused bool) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.IsPaused = paused
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -250,6 +256,7 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		IsPaused:        r.IsPaused,
 	}
 
 	if r.DashboardUID != nil {
@@ -287,6 +294,17 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	if r.DependsOn != nil {
+		dependsOn := *r.DependsOn
+		dependsOn.Matchers = append([]string(nil), r.DependsOn.Matchers...)
+		result.DependsOn = &dependsOn
+	}
+
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
 	return &result
 }
 
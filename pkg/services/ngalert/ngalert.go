@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -34,6 +36,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -62,6 +65,9 @@ func ProvideService(
 	bus bus.Bus,
 	accesscontrolService accesscontrol.Service,
 	annotationsRepo annotations.Repository,
+	pluginStore plugins.Store,
+	pluginClient plugins.Client,
+	pluginContextProvider *plugincontext.Provider,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -85,6 +91,9 @@ func ProvideService(
 		bus:                  bus,
 		accesscontrolService: accesscontrolService,
 		annotationsRepo:      annotationsRepo,
+		pluginStore:          pluginStore,
+		pluginClient:         pluginClient,
+		pluginContext:        pluginContextProvider,
 	}
 
 	if ng.IsDisabled() {
@@ -129,6 +138,11 @@ type AlertNG struct {
 	annotationsRepo      annotations.Repository
 	store                *store.DBstore
 
+	// Plugins that provide receiver types
+	pluginStore   plugins.Store
+	pluginClient  plugins.Client
+	pluginContext *plugincontext.Provider
+
 	bus bus.Bus
 }
 
@@ -147,6 +161,11 @@ func (ng *AlertNG) init() error {
 	ng.store = store
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
+	// the receiver types of the plugins must be registered before the configurations of the Alertmanagers are applied
+	if ng.pluginStore != nil {
+		notifier.RegisterPluginNotifiers(context.Background(), ng.pluginStore, notifier.NewPluginNotifyClient(ng.pluginClient, ng.pluginContext), ng.Log)
+	}
+
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
 	if err != nil {
@@ -190,10 +209,27 @@ func (ng *AlertNG) init() error {
 		RuleStore:        store,
 		Metrics:          ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:      alertsRouter,
+		MaintenanceStore: store,
+	}
+	if ng.Cfg.UnifiedAlerting.EvaluationSharding {
+		if err := ng.MultiOrgAlertmanager.CheckClusterMembership(); err != nil {
+			return fmt.Errorf("failed to enable evaluation sharding: %w", err)
+		}
+		schedCfg.Membership = ng.MultiOrgAlertmanager
+	}
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		recordingWriter, err := writer.NewPrometheusWriter(ng.Cfg.UnifiedAlerting.RecordingRules, log.New("ngalert.writer"))
+		if err != nil {
+			return fmt.Errorf("failed to initialize recording rules writer: %w", err)
+		}
+		schedCfg.RecordingWriter = recordingWriter
 	}
 
-	historian := historian.NewAnnotationHistorian(ng.annotationsRepo, ng.dashboardService)
-	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, historian)
+	history, err := configureHistorianBackend(ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.SQLStore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the state history: %w", err)
+	}
+	stateManager := state.NewManager(ng.Metrics.GetStateMetrics(), appUrl, store, ng.imageService, clk, history)
 	scheduler := schedule.NewScheduler(schedCfg, appUrl, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -212,6 +248,8 @@ func (ng *AlertNG) init() error {
 	alertRuleService := provisioning.NewAlertRuleService(store, store, ng.QuotaService, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
+	maintenanceWindowService := provisioning.NewMaintenanceWindowService(store, store, store, ng.Log)
+	maintenanceWindowService.OnChange(scheduler.MaintenanceWindowsChanged)
 
 	api := api.API{
 		Cfg:                  ng.Cfg,
@@ -234,8 +272,10 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		MaintenanceWindows:   maintenanceWindowService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
+		Historian:            history,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -250,6 +290,20 @@ func (ng *AlertNG) init() error {
 	return DeclareFixedRoles(ng.accesscontrolService)
 }
 
+func configureHistorianBackend(cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, sqlStore db.DB) (state.Historian, error) {
+	if !cfg.Enabled {
+		return historian.NewNopHistorian(), nil
+	}
+	switch cfg.Backend {
+	case setting.StateHistoryBackendSQL:
+		return historian.NewSQLHistorian(sqlStore, cfg.SQLRetention), nil
+	case setting.StateHistoryBackendLoki:
+		return historian.NewLokiHistorian(cfg)
+	default:
+		return historian.NewAnnotationHistorian(ar, ds), nil
+	}
+}
+
 func subscribeToFolderChanges(logger log.Logger, bus bus.Bus, dbStore api.RuleStore, scheduler schedule.ScheduleService) {
 	// if folder title is changed, we update all alert rules in that folder to make sure that all peers (in HA mode) will update folder title and
 	// clean up the current state
@@ -101,6 +101,7 @@ type Alertmanager struct {
 	NotificationService notifications.Service
 
 	notificationLog *nflog.Log
+	deliveries      *deliveryLog
 	marker          types.Marker
 	alerts          *mem.Alerts
 	route           *dispatch.Route
@@ -141,6 +142,7 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		Settings:            cfg,
 		stopc:               make(chan struct{}),
 		logger:              log.New("alertmanager", "org", orgID),
+		deliveries:          newDeliveryLog(deliveryLogCapacity),
 		marker:              types.NewMarker(m.Registerer),
 		stageMetrics:        notify.NewMetrics(m.Registerer),
 		dispatcherMetrics:   dispatch.NewDispatcherMetrics(false, m.Registerer),
@@ -483,7 +485,17 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
-		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
+		recorder := &deliveryRecorder{
+			NotificationChannel: n,
+			orgID:               strconv.FormatInt(am.orgID, 10),
+			receiver:            receiver.Name,
+			integration:         r.Type,
+			uid:                 r.UID,
+			index:               i,
+			deliveries:          am.deliveries,
+			metrics:             am.Metrics.Integration,
+		}
+		integrations = append(integrations, notify.NewIntegration(recorder, recorder, r.Type, i))
 	}
 	return integrations, nil
 }
@@ -688,6 +700,7 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []*notify.
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(integration, notificationLog, recv))
+		s = append(s, withDeliveryAttempts())
 		s = append(s, notify.NewRetryStage(integration, name, am.stageMetrics))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 
 	"github.com/prometheus/alertmanager/template"
 
@@ -55,22 +56,52 @@ var receiverFactories = map[string]func(FactoryConfig) (NotificationChannel, err
 	"googlechat":              GoogleChatFactory,
 	"kafka":                   KafkaFactory,
 	"line":                    LineFactory,
+	"matrix":                  MatrixFactory,
+	"mattermost":              MattermostFactory,
 	"opsgenie":                OpsgenieFactory,
 	"pagerduty":               PagerdutyFactory,
 	"pushover":                PushoverFactory,
 	"sensugo":                 SensuGoFactory,
 	"slack":                   SlackFactory,
+	"sns":                     SNSFactory,
 	"teams":                   TeamsFactory,
+	"teamsworkflows":          TeamsWorkflowsFactory,
 	"telegram":                TelegramFactory,
 	"threema":                 ThreemaFactory,
 	"victorops":               VictorOpsFactory,
 	"webhook":                 WebHookFactory,
 	"wecom":                   WeComFactory,
 	"webex":                   WebexFactory,
+	"zulip":                   ZulipFactory,
+}
+
+var (
+	pluginFactoriesMtx sync.RWMutex
+	pluginFactories    = map[string]func(FactoryConfig) (NotificationChannel, error){}
+)
+
+// RegisterPluginFactory registers the factory of a receiver type that is provided by a plugin. It replaces the factory
+// of a receiver type with the same name that was registered before, but it returns false and does not register the
+// factory if the name is the name of a built-in receiver type.
+func RegisterPluginFactory(receiverType string, factory func(FactoryConfig) (NotificationChannel, error)) bool {
+	receiverType = strings.ToLower(receiverType)
+	if _, exists := receiverFactories[receiverType]; exists {
+		return false
+	}
+	pluginFactoriesMtx.Lock()
+	defer pluginFactoriesMtx.Unlock()
+	pluginFactories[receiverType] = factory
+	return true
 }
 
 func Factory(receiverType string) (func(FactoryConfig) (NotificationChannel, error), bool) {
 	receiverType = strings.ToLower(receiverType)
 	factory, exists := receiverFactories[receiverType]
+	if exists {
+		return factory, true
+	}
+	pluginFactoriesMtx.RLock()
+	defer pluginFactoriesMtx.RUnlock()
+	factory, exists = pluginFactories[receiverType]
 	return factory, exists
 }
@@ -0,0 +1,165 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MatrixNotifier is responsible for sending alert notifications to a room of Matrix.
+type MatrixNotifier struct {
+	*Base
+	log      log.Logger
+	ns       notifications.WebhookSender
+	images   ImageStore
+	tmpl     *template.Template
+	settings *matrixSettings
+}
+
+type matrixSettings struct {
+	HomeserverURL string `json:"homeserver_url,omitempty" yaml:"homeserver_url,omitempty"`
+	RoomID        string `json:"room_id,omitempty" yaml:"room_id,omitempty"`
+	AccessToken   string `json:"access_token,omitempty" yaml:"access_token,omitempty"`
+	Title         string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message       string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func buildMatrixSettings(fc FactoryConfig) (*matrixSettings, error) {
+	settings := &matrixSettings{}
+	err := fc.Config.unmarshalSettings(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.HomeserverURL == "" {
+		return nil, errors.New("could not find homeserver url property in settings")
+	}
+	u, err := url.Parse(settings.HomeserverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q", settings.HomeserverURL)
+	}
+	settings.HomeserverURL = strings.TrimSuffix(u.String(), "/")
+	if settings.RoomID == "" {
+		return nil, errors.New("could not find room ID property in settings")
+	}
+	settings.AccessToken = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "access_token", settings.AccessToken)
+	if settings.AccessToken == "" {
+		return nil, errors.New("could not find access token property in settings")
+	}
+	if settings.Title == "" {
+		settings.Title = DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = DefaultMessageEmbed
+	}
+	return settings, nil
+}
+
+func MatrixFactory(fc FactoryConfig) (NotificationChannel, error) {
+	notifier, err := buildMatrixNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+// buildMatrixNotifier is the constructor for the Matrix notifier.
+func buildMatrixNotifier(fc FactoryConfig) (*MatrixNotifier, error) {
+	settings, err := buildMatrixSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	return &MatrixNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:      log.New("alerting.notifier.matrix"),
+		ns:       fc.NotificationService,
+		images:   fc.ImageStore,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// MatrixMessage is the content of an event of type m.room.message.
+type MatrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Notify implements the Notifier interface.
+func (mn *MatrixNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	title := tmpl(mn.settings.Title)
+	message := tmpl(mn.settings.Message)
+	if tmplErr != nil {
+		mn.log.Warn("failed to template Matrix message", "error", tmplErr.Error())
+	}
+
+	body := title + "\n\n" + message
+	formatted := "<strong>" + html.EscapeString(title) + "</strong><br/>" + strings.ReplaceAll(html.EscapeString(message), "\n", "<br/>")
+	_ = withStoredImages(ctx, mn.log, mn.images, func(index int, image ngmodels.Image) error {
+		if image.HasURL() {
+			name := as[index].Name()
+			body += fmt.Sprintf("\n%s: %s", name, image.URL)
+			formatted += fmt.Sprintf(`<br/><a href="%s">%s</a>`, html.EscapeString(image.URL), html.EscapeString(name))
+		}
+		return nil
+	}, as...)
+
+	b, err := json.Marshal(MatrixMessage{
+		MsgType:       "m.text",
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal Matrix message: %w", err)
+	}
+
+	// the transaction ID makes the request idempotent, it must be unique for every message
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		mn.settings.HomeserverURL, url.PathEscape(mn.settings.RoomID), util.GenerateShortUID())
+	cmd := &models.SendWebhookSync{
+		Url:        u,
+		Body:       string(b),
+		HttpMethod: http.MethodPut,
+		HttpHeader: map[string]string{
+			"Authorization": "Bearer " + mn.settings.AccessToken,
+		},
+		ContentType: "application/json",
+	}
+	if err := mn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		mn.log.Error("failed to send notification to Matrix", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (mn *MatrixNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,121 @@
+package channels
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestMatrixNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		accessToken  string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError string
+	}{
+		{
+			name: "Custom title and message with an image",
+			settings: `{
+				"room_id": "!room:example.com",
+				"title": "{{ .CommonLabels.alertname }} <firing>",
+				"message": "{{ len .Alerts.Firing }} firing\nnow"
+			}`,
+			accessToken: "token",
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expMsg: `{
+				"msgtype": "m.text",
+				"body": "alert1 <firing>\n\n1 firing\nnow\nalert1: https://www.example.com/test-image-1.jpg",
+				"format": "org.matrix.custom.html",
+				"formatted_body": "<strong>alert1 &lt;firing&gt;</strong><br/>1 firing<br/>now<br/><a href=\"https://www.example.com/test-image-1.jpg\">alert1</a>"
+			}`,
+		},
+		{
+			name:         "Error if the room ID is missing",
+			settings:     `{}`,
+			accessToken:  "token",
+			expInitError: "could not find room ID property in settings",
+		},
+		{
+			name:         "Error if the access token is missing",
+			settings:     `{"room_id": "!room:example.com"}`,
+			expInitError: "could not find access token property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPut, r.Method)
+				require.Regexp(t, `^/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/[\w-]+$`, r.URL.EscapedPath())
+				require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				var err error
+				body, err = io.ReadAll(r.Body)
+				require.NoError(t, err)
+				_, _ = w.Write([]byte(`{"event_id": "$event"}`))
+			}))
+			defer server.Close()
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			settingsJSON.Set("homeserver_url", server.URL)
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			secureSettings := map[string][]byte{}
+			if c.accessToken != "" {
+				secureSettings["access_token"] = encryptForTests(t, secretsService, c.accessToken)
+			}
+
+			fc := FactoryConfig{
+				Config: &NotificationChannelConfig{
+					Name:           "matrix_testing",
+					Type:           "matrix",
+					Settings:       settingsJSON,
+					SecureSettings: secureSettings,
+				},
+				ImageStore:          newFakeImageStore(1),
+				NotificationService: CreateNotificationService(t),
+				DecryptFunc:         secretsService.GetDecryptedValue,
+				Template:            tmpl,
+			}
+			n, err := buildMatrixNotifier(fc)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.JSONEq(t, c.expMsg, string(body))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// mattermostMaxTextLength is the maximum length of the text of an attachment that Mattermost accepts.
+const mattermostMaxTextLength = 16383
+
+// MattermostNotifier is responsible for sending alert notifications to Mattermost incoming webhooks.
+type MattermostNotifier struct {
+	*Base
+	log      log.Logger
+	ns       notifications.WebhookSender
+	images   ImageStore
+	tmpl     *template.Template
+	settings *mattermostSettings
+}
+
+type mattermostSettings struct {
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	Channel  string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	IconURL  string `json:"icon_url,omitempty" yaml:"icon_url,omitempty"`
+	Title    string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func buildMattermostSettings(fc FactoryConfig) (*mattermostSettings, error) {
+	settings := &mattermostSettings{}
+	err := fc.Config.unmarshalSettings(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	settings.URL = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "url", settings.URL)
+	if settings.URL == "" {
+		return nil, errors.New("could not find webhook url property in settings")
+	}
+	if settings.Title == "" {
+		settings.Title = DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = DefaultMessageEmbed
+	}
+	return settings, nil
+}
+
+func MattermostFactory(fc FactoryConfig) (NotificationChannel, error) {
+	notifier, err := buildMattermostNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+// buildMattermostNotifier is the constructor for the Mattermost notifier.
+func buildMattermostNotifier(fc FactoryConfig) (*MattermostNotifier, error) {
+	settings, err := buildMattermostSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	return &MattermostNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:      log.New("alerting.notifier.mattermost"),
+		ns:       fc.NotificationService,
+		images:   fc.ImageStore,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// MattermostMessage defines the JSON object to send to Mattermost incoming webhooks.
+type MattermostMessage struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	Attachments []MattermostAttachment `json:"attachments"`
+}
+
+// MattermostAttachment is a message attachment, which has the same fields as a Slack attachment.
+type MattermostAttachment struct {
+	Fallback   string `json:"fallback"`
+	Color      string `json:"color"`
+	Title      string `json:"title"`
+	TitleLink  string `json:"title_link"`
+	Text       string `json:"text"`
+	ImageURL   string `json:"image_url,omitempty"`
+	Footer     string `json:"footer"`
+	FooterIcon string `json:"footer_icon"`
+}
+
+// Notify implements the Notifier interface.
+func (mn *MattermostNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	title := tmpl(mn.settings.Title)
+	text, truncated := TruncateInBytes(tmpl(mn.settings.Message), mattermostMaxTextLength)
+	if truncated {
+		mn.log.Warn("Mattermost message too long, truncating message", "OriginalMessage", mn.settings.Message)
+	}
+	if tmplErr != nil {
+		mn.log.Warn("failed to template Mattermost message", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+
+	attachment := MattermostAttachment{
+		Fallback:   title,
+		Color:      getAlertStatusColor(types.Alerts(as...).Status()),
+		Title:      title,
+		TitleLink:  joinUrlPath(mn.tmpl.ExternalURL.String(), "/alerting/list", mn.log),
+		Text:       text,
+		Footer:     "Grafana v" + setting.BuildVersion,
+		FooterIcon: FooterIconURL,
+	}
+	// Mattermost shows a single image per attachment
+	_ = withStoredImages(ctx, mn.log, mn.images, func(_ int, image ngmodels.Image) error {
+		if image.HasURL() {
+			attachment.ImageURL = image.URL
+			return ErrImagesDone
+		}
+		return nil
+	}, as...)
+
+	msg := &MattermostMessage{
+		Channel:     tmpl(mn.settings.Channel),
+		Username:    tmpl(mn.settings.Username),
+		IconURL:     tmpl(mn.settings.IconURL),
+		Attachments: []MattermostAttachment{attachment},
+	}
+	if tmplErr != nil {
+		mn.log.Warn("failed to template Mattermost message settings", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	u := tmpl(mn.settings.URL)
+	if tmplErr != nil {
+		mn.log.Warn("failed to template Mattermost URL", "error", tmplErr.Error(), "fallback", mn.settings.URL)
+		u = mn.settings.URL
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        u,
+		Body:       string(body),
+		HttpMethod: http.MethodPost,
+	}
+	if err := mn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		mn.log.Error("failed to send notification to Mattermost", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (mn *MattermostNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
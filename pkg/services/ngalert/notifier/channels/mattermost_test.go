@@ -0,0 +1,144 @@
+package channels
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestMattermostNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1", "__alertImageToken__": "test-image-1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError string
+	}{
+		{
+			name: "Custom title, message and overrides",
+			settings: `{
+				"channel": "alerts",
+				"username": "grafana",
+				"title": "{{ .CommonLabels.alertname }} is {{ .Status }}",
+				"message": "{{ len .Alerts.Firing }} firing"
+			}`,
+			alerts: alerts,
+			expMsg: `{
+				"channel": "alerts",
+				"username": "grafana",
+				"attachments": [{
+					"fallback": "alert1 is firing",
+					"color": "#D63232",
+					"title": "alert1 is firing",
+					"title_link": "http://localhost/alerting/list",
+					"text": "1 firing",
+					"image_url": "https://www.example.com/test-image-1.jpg",
+					"footer": "Grafana v",
+					"footer_icon": "https://grafana.com/assets/img/fav32.png"
+				}]
+			}`,
+		},
+		{
+			name:     "Truncate long message",
+			settings: `{"message": "{{ .CommonLabels.alertname }}", "title": "title"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": model.LabelValue(strings.Repeat("1", mattermostMaxTextLength+1))},
+					},
+				},
+			},
+			expMsg: `{
+				"attachments": [{
+					"fallback": "title",
+					"color": "#D63232",
+					"title": "title",
+					"title_link": "http://localhost/alerting/list",
+					"text": "` + strings.Repeat("1", mattermostMaxTextLength-3) + `…",
+					"footer": "Grafana v",
+					"footer_icon": "https://grafana.com/assets/img/fav32.png"
+				}]
+			}`,
+		},
+		{
+			name:         "Error if the URL is missing",
+			settings:     `{}`,
+			expInitError: "could not find webhook url property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "/hooks/abc", r.URL.Path)
+				var err error
+				body, err = io.ReadAll(r.Body)
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			secureSettings := map[string][]byte{}
+			if c.expInitError == "" {
+				secureSettings["url"] = encryptForTests(t, secretsService, server.URL+"/hooks/abc")
+			}
+
+			fc := FactoryConfig{
+				Config: &NotificationChannelConfig{
+					Name:           "mattermost_testing",
+					Type:           "mattermost",
+					Settings:       settingsJSON,
+					SecureSettings: secureSettings,
+				},
+				ImageStore:          newFakeImageStore(1),
+				NotificationService: CreateNotificationService(t),
+				DecryptFunc:         secretsService.GetDecryptedValue,
+				Template:            tmpl,
+			}
+			n, err := buildMattermostNotifier(fc)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.JSONEq(t, c.expMsg, string(body))
+		})
+	}
+}
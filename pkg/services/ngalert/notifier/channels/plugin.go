@@ -0,0 +1,180 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// PluginNotifyClient sends the notifications to the backend plugins that provide a receiver type.
+type PluginNotifyClient interface {
+	// Notify sends the body to the plugin. It returns an error if the plugin did not accept the notification.
+	Notify(ctx context.Context, orgID int64, pluginID string, body []byte) error
+}
+
+// PluginNotifierConfig describes a receiver type that is provided by a backend plugin.
+type PluginNotifierConfig struct {
+	PluginID string
+	// SecureFields are the settings that are stored encrypted.
+	SecureFields []string
+	// Validate validates the settings of a receiver, in which the secure fields are decrypted.
+	Validate func(settings map[string]interface{}) error
+	Client   PluginNotifyClient
+}
+
+// PluginNotifier sends the notifications to a backend plugin.
+type PluginNotifier struct {
+	*Base
+	log      log.Logger
+	images   ImageStore
+	tmpl     *template.Template
+	orgID    int64
+	pluginID string
+	client   PluginNotifyClient
+	settings map[string]interface{}
+	title    string
+	message  string
+}
+
+// PluginMessage defines the JSON object sent to the plugin.
+type PluginMessage struct {
+	*ExtendedData
+
+	// The protocol version.
+	Version  string `json:"version"`
+	GroupKey string `json:"groupKey"`
+	OrgID    int64  `json:"orgId"`
+	Title    string `json:"title"`
+	State    string `json:"state"`
+	Message  string `json:"message"`
+	// Settings are the settings of the receiver, including the decrypted secure settings.
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// PluginNotifierFactory returns the factory of the receiver type of the plugin.
+func PluginNotifierFactory(cfg PluginNotifierConfig) func(FactoryConfig) (NotificationChannel, error) {
+	return func(fc FactoryConfig) (NotificationChannel, error) {
+		notifier, err := buildPluginNotifier(cfg, fc)
+		if err != nil {
+			return nil, receiverInitError{
+				Reason: err.Error(),
+				Cfg:    *fc.Config,
+			}
+		}
+		return notifier, nil
+	}
+}
+
+func buildPluginNotifier(cfg PluginNotifierConfig, fc FactoryConfig) (*PluginNotifier, error) {
+	raw, err := fc.Config.Settings.Map()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	// the settings are copied as the decrypted secure settings must not be added to the configuration of the receiver
+	settings := make(map[string]interface{}, len(raw)+len(cfg.SecureFields))
+	for k, v := range raw {
+		settings[k] = v
+	}
+	for _, field := range cfg.SecureFields {
+		fallback, _ := settings[field].(string)
+		if value := fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, field, fallback); value != "" {
+			settings[field] = value
+		}
+	}
+	if cfg.Validate != nil {
+		if err := cfg.Validate(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	title, ok := settings["title"].(string)
+	if !ok || title == "" {
+		title = DefaultMessageTitleEmbed
+	}
+	message, ok := settings["message"].(string)
+	if !ok || message == "" {
+		message = DefaultMessageEmbed
+	}
+
+	return &PluginNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:      log.New("alerting.notifier.plugin", "pluginId", cfg.PluginID),
+		images:   fc.ImageStore,
+		tmpl:     fc.Template,
+		orgID:    fc.Config.OrgID,
+		pluginID: cfg.PluginID,
+		client:   cfg.Client,
+		settings: settings,
+		title:    title,
+		message:  message,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (pn *PluginNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, data := TmplText(ctx, pn.tmpl, as, pn.log, &tmplErr)
+
+	// Augment our Alert data with ImageURLs if available.
+	_ = withStoredImages(ctx, pn.log, pn.images,
+		func(index int, image ngmodels.Image) error {
+			if len(image.URL) != 0 {
+				data.Alerts[index].ImageURL = image.URL
+			}
+			return nil
+		},
+		as...)
+
+	msg := &PluginMessage{
+		Version:      "1",
+		ExtendedData: data,
+		GroupKey:     groupKey.String(),
+		OrgID:        pn.orgID,
+		Title:        tmpl(pn.title),
+		Message:      tmpl(pn.message),
+		Settings:     pn.settings,
+	}
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		msg.State = string(models.AlertStateAlerting)
+	} else {
+		msg.State = string(models.AlertStateOK)
+	}
+
+	if tmplErr != nil {
+		pn.log.Warn("failed to template plugin message", "error", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	if err := pn.client.Notify(ctx, pn.orgID, pn.pluginID, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (pn *PluginNotifier) SendResolved() bool {
+	return !pn.GetDisableResolveMessage()
+}
@@ -0,0 +1,136 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+type fakePluginNotifyClient struct {
+	orgID    int64
+	pluginID string
+	body     []byte
+	err      error
+}
+
+func (c *fakePluginNotifyClient) Notify(_ context.Context, orgID int64, pluginID string, body []byte) error {
+	c.orgID = orgID
+	c.pluginID = pluginID
+	c.body = body
+	return c.err
+}
+
+func TestPluginNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+
+	alerts := []*types.Alert{{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			Annotations: model.LabelSet{"ann1": "annv1"},
+		},
+	}}
+
+	build := func(t *testing.T, settingsJSON *simplejson.Json, secureSettings map[string][]byte, cfg PluginNotifierConfig) (*PluginNotifier, error) {
+		t.Helper()
+		return buildPluginNotifier(cfg, FactoryConfig{
+			Config: &NotificationChannelConfig{
+				OrgID:          1,
+				Name:           "ticketing",
+				Type:           "acme-ticketing-app",
+				Settings:       settingsJSON,
+				SecureSettings: secureSettings,
+			},
+			DecryptFunc: secretsService.GetDecryptedValue,
+			ImageStore:  &UnavailableImageStore{},
+			Template:    tmpl,
+		})
+	}
+
+	t.Run("should send the alerts and the settings to the plugin", func(t *testing.T) {
+		encrypted, err := secretsService.Encrypt(context.Background(), []byte("secret"), secrets.WithoutScope())
+		require.NoError(t, err)
+		client := &fakePluginNotifyClient{}
+		var validated map[string]interface{}
+		settings, err := simplejson.NewJson([]byte(`{"project": "OPS", "priority": 2}`))
+		require.NoError(t, err)
+		n, err := build(t, settings, map[string][]byte{"token": encrypted}, PluginNotifierConfig{
+			PluginID:     "acme-ticketing-app",
+			SecureFields: []string{"token"},
+			Validate: func(settings map[string]interface{}) error {
+				validated = settings
+				return nil
+			},
+			Client: client,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", validated["token"])
+		require.NotContains(t, settings.MustMap(), "token")
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ctx = notify.WithReceiverName(ctx, "ticketing")
+		ok, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, int64(1), client.orgID)
+		require.Equal(t, "acme-ticketing-app", client.pluginID)
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(client.body, &msg))
+		require.Equal(t, "1", msg["version"])
+		require.Equal(t, "ticketing", msg["receiver"])
+		require.Equal(t, "firing", msg["status"])
+		require.Equal(t, "alerting", msg["state"])
+		require.Equal(t, "[FIRING:1]  (val1)", msg["title"])
+		require.Equal(t, map[string]interface{}{"project": "OPS", "priority": float64(2), "token": "secret"}, msg["settings"])
+		require.Len(t, msg["alerts"], 1)
+	})
+
+	t.Run("should fail if the settings are not valid", func(t *testing.T) {
+		_, err := build(t, simplejson.New(), nil, PluginNotifierConfig{
+			PluginID: "acme-ticketing-app",
+			Validate: func(map[string]interface{}) error {
+				return errors.New("project is required")
+			},
+			Client: &fakePluginNotifyClient{},
+		})
+		require.EqualError(t, err, "project is required")
+	})
+
+	t.Run("should fail if the plugin does not accept the notification", func(t *testing.T) {
+		n, err := build(t, simplejson.New(), nil, PluginNotifierConfig{
+			PluginID: "acme-ticketing-app",
+			Client:   &fakePluginNotifyClient{err: errors.New("plugin replied with status 500")},
+		})
+		require.NoError(t, err)
+		ok, err := n.Notify(notify.WithGroupKey(context.Background(), "alertname"), alerts...)
+		require.False(t, ok)
+		require.Error(t, err)
+	})
+}
+
+func TestRegisterPluginFactory(t *testing.T) {
+	factory := PluginNotifierFactory(PluginNotifierConfig{PluginID: "acme-ticketing-app"})
+	require.False(t, RegisterPluginFactory("webhook", factory))
+	require.True(t, RegisterPluginFactory("acme-ticketing-app", factory))
+	_, exists := Factory("acme-ticketing-app")
+	require.True(t, exists)
+	_, exists = Factory("webhook")
+	require.True(t, exists)
+}
@@ -0,0 +1,266 @@
+package channels
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// snsMaxSubjectLength is the maximum number of characters of the subject of an email sent by SNS.
+	snsMaxSubjectLength = 100
+	// snsMaxMessageSize is the maximum number of bytes of a message published to a topic.
+	snsMaxMessageSize = 256 * 1024
+	// snsMaxSMSLength is the maximum number of characters of a message sent by SMS.
+	snsMaxSMSLength = 1600
+)
+
+// SNSNotifier is responsible for publishing alert notifications to AWS SNS topics and phone numbers.
+type SNSNotifier struct {
+	*Base
+	log      log.Logger
+	images   ImageStore
+	tmpl     *template.Template
+	settings *snsSettings
+	// authSettings are the [aws] settings of Grafana, that the AWS datasources follow too.
+	authSettings *awsds.AuthSettings
+}
+
+// PLEASE do not touch these settings without taking a look at what we support as part of
+// https://github.com/prometheus/alertmanager/blob/main/notify/sns/sns.go
+// Currently, the Alerting team is unifying channels and (upstream) receivers - any discrepancy is detrimental to that.
+type snsSettings struct {
+	APIUrl        string            `json:"api_url,omitempty" yaml:"api_url,omitempty"`
+	Region        string            `json:"region,omitempty" yaml:"region,omitempty"`
+	AccessKey     string            `json:"access_key,omitempty" yaml:"access_key,omitempty"`
+	SecretKey     string            `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+	AssumeRoleARN string            `json:"assume_role_arn,omitempty" yaml:"assume_role_arn,omitempty"`
+	TopicARN      string            `json:"topic_arn,omitempty" yaml:"topic_arn,omitempty"`
+	TargetARN     string            `json:"target_arn,omitempty" yaml:"target_arn,omitempty"`
+	PhoneNumber   string            `json:"phone_number,omitempty" yaml:"phone_number,omitempty"`
+	Subject       string            `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Message       string            `json:"message,omitempty" yaml:"message,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+}
+
+func buildSNSSettings(fc FactoryConfig) (*snsSettings, error) {
+	settings := &snsSettings{}
+	err := fc.Config.unmarshalSettings(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	destinations := 0
+	for _, d := range []string{settings.TopicARN, settings.TargetARN, settings.PhoneNumber} {
+		if d != "" {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		return nil, errors.New("must specify exactly one of topic ARN, target ARN or phone number")
+	}
+	if settings.Region == "" {
+		// the region of a topic or a target is part of its ARN
+		for _, a := range []string{settings.TopicARN, settings.TargetARN} {
+			if a == "" {
+				continue
+			}
+			parsed, err := arn.Parse(a)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ARN %q: %w", a, err)
+			}
+			settings.Region = parsed.Region
+		}
+	}
+	if settings.Region == "" {
+		return nil, errors.New("could not find region property in settings")
+	}
+
+	settings.AccessKey = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "access_key", settings.AccessKey)
+	settings.SecretKey = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "secret_key", settings.SecretKey)
+	if (settings.AccessKey == "") != (settings.SecretKey == "") {
+		return nil, errors.New("must specify both access key and secret key, or none to use the default credentials")
+	}
+
+	if settings.Subject == "" {
+		settings.Subject = DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = DefaultMessageEmbed
+	}
+	return settings, nil
+}
+
+func SNSFactory(fc FactoryConfig) (NotificationChannel, error) {
+	notifier, err := buildSNSNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+// buildSNSNotifier is the constructor for the AWS SNS notifier.
+func buildSNSNotifier(fc FactoryConfig) (*SNSNotifier, error) {
+	settings, err := buildSNSSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	return &SNSNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:          log.New("alerting.notifier.sns"),
+		images:       fc.ImageStore,
+		tmpl:         fc.Template,
+		settings:     settings,
+		authSettings: awsds.ReadAuthSettingsFromEnvironmentVariables(),
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (sn *SNSNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+
+	input := &sns.PublishInput{}
+	switch {
+	case sn.settings.TopicARN != "":
+		input.TopicArn = aws.String(tmpl(sn.settings.TopicARN))
+	case sn.settings.TargetARN != "":
+		input.TargetArn = aws.String(tmpl(sn.settings.TargetARN))
+	default:
+		input.PhoneNumber = aws.String(tmpl(sn.settings.PhoneNumber))
+	}
+
+	message := tmpl(sn.settings.Message)
+	_ = withStoredImages(ctx, sn.log, sn.images, func(index int, image ngmodels.Image) error {
+		if image.HasURL() {
+			message += fmt.Sprintf("\n%s: %s", as[index].Name(), image.URL)
+		}
+		return nil
+	}, as...)
+	var truncated bool
+	if input.PhoneNumber != nil {
+		message, truncated = truncateSMS(message)
+	} else {
+		message, truncated = TruncateInBytes(message, snsMaxMessageSize)
+	}
+	if truncated {
+		sn.log.Warn("SNS message too long, truncating message", "OriginalMessage", sn.settings.Message)
+	}
+	input.Message = aws.String(message)
+
+	// the subject is only used by the email subscriptions of a topic
+	if input.PhoneNumber == nil {
+		input.Subject = aws.String(truncateInRunes(tmpl(sn.settings.Subject), snsMaxSubjectLength))
+	}
+
+	if len(sn.settings.Attributes) > 0 {
+		input.MessageAttributes = make(map[string]*sns.MessageAttributeValue, len(sn.settings.Attributes))
+		for k, v := range sn.settings.Attributes {
+			input.MessageAttributes[tmpl(k)] = &sns.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(tmpl(v))}
+		}
+	}
+
+	if tmplErr != nil {
+		sn.log.Warn("failed to template SNS message", "error", tmplErr.Error())
+	}
+
+	// FIFO topics require a group and a deduplication ID
+	if input.TopicArn != nil && strings.HasSuffix(*input.TopicArn, ".fifo") {
+		key, err := notify.ExtractGroupKey(ctx)
+		if err != nil {
+			return false, err
+		}
+		input.MessageGroupId = aws.String(key.Hash())
+		input.MessageDeduplicationId = aws.String(fmt.Sprintf("%x", sha256.Sum256([]byte(message))))
+	}
+
+	client, err := sn.createSNSClient()
+	if err != nil {
+		return false, err
+	}
+	if _, err := client.PublishWithContext(ctx, input); err != nil {
+		sn.log.Error("failed to publish notification to SNS", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (sn *SNSNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// createSNSClient returns a client with the static credentials of the settings, or the default credentials of the
+// environment if there are none. If there is a role, the credentials are used to assume it. Like the AWS datasources,
+// it fails if the [aws] settings of Grafana do not allow the type of credentials or assuming a role.
+func (sn *SNSNotifier) createSNSClient() (*sns.SNS, error) {
+	authType := awsds.AuthTypeDefault
+	if sn.settings.AccessKey != "" {
+		authType = awsds.AuthTypeKeys
+	}
+	if !sn.isAuthTypeAllowed(authType) {
+		return nil, fmt.Errorf("attempting to use an auth type that is not allowed: %q", authType.String())
+	}
+	if sn.settings.AssumeRoleARN != "" && !sn.authSettings.AssumeRoleEnabled {
+		return nil, errors.New("attempting to use assume role (ARN) which is disabled in grafana.ini")
+	}
+
+	cfg := aws.NewConfig().WithRegion(sn.settings.Region)
+	if sn.settings.APIUrl != "" {
+		cfg = cfg.WithEndpoint(sn.settings.APIUrl)
+	}
+	if sn.settings.AccessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(sn.settings.AccessKey, sn.settings.SecretKey, ""))
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{Config: *cfg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	if sn.settings.AssumeRoleARN != "" {
+		return sns.New(sess, &aws.Config{Credentials: stscreds.NewCredentials(sess, sn.settings.AssumeRoleARN)}), nil
+	}
+	return sns.New(sess), nil
+}
+
+func (sn *SNSNotifier) isAuthTypeAllowed(authType awsds.AuthType) bool {
+	for _, provider := range sn.authSettings.AllowedAuthProviders {
+		if provider == authType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateSMS truncates the message to the maximum number of characters of an SMS.
+func truncateSMS(message string) (string, bool) {
+	if utf8.RuneCountInString(message) <= snsMaxSMSLength {
+		return message, false
+	}
+	return truncateInRunes(message, snsMaxSMSLength), true
+}
@@ -0,0 +1,261 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+const snsPublishResponse = `<PublishResponse xmlns="https://sns.amazonaws.com/doc/2010-03-31/">
+  <PublishResult><MessageId>567910cd-659e-55d4-8ccb-5aaf14679dc0</MessageId></PublishResult>
+  <ResponseMetadata><RequestId>d74b8436-ae13-5ab4-a9ff-ce54dfea72a0</RequestId></ResponseMetadata>
+</PublishResponse>`
+
+func TestSNSNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expForm      map[string]string
+		expInitError string
+	}{
+		{
+			name: "Publish to a topic",
+			settings: `{
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+				"subject": "{{ .CommonLabels.alertname }}",
+				"message": "{{ len .Alerts.Firing }} firing",
+				"attributes": {"team": "{{ .CommonLabels.lbl1 }}"}
+			}`,
+			alerts: alerts,
+			expForm: map[string]string{
+				"Action":                         "Publish",
+				"TopicArn":                       "arn:aws:sns:us-east-1:123456789012:alerts",
+				"Subject":                        "alert1",
+				"Message":                        "1 firing\nalert1: https://www.example.com/test-image-1.jpg",
+				"MessageAttributes.entry.1.Name": "team",
+				"MessageAttributes.entry.1.Value.DataType":    "String",
+				"MessageAttributes.entry.1.Value.StringValue": "val1",
+			},
+		},
+		{
+			name: "Send an SMS without a subject",
+			settings: `{
+				"phone_number": "+15555550100",
+				"region": "eu-west-1",
+				"message": "{{ .CommonLabels.alertname }}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": model.LabelValue(strings.Repeat("1", snsMaxSMSLength+1))},
+					},
+				},
+			},
+			expForm: map[string]string{
+				"Action":      "Publish",
+				"PhoneNumber": "+15555550100",
+				"Message":     strings.Repeat("1", snsMaxSMSLength-1) + "…",
+			},
+		},
+		{
+			name: "Publish to a FIFO topic",
+			settings: `{
+				"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts.fifo",
+				"subject": "subject",
+				"message": "message"
+			}`,
+			alerts: alerts,
+			expForm: map[string]string{
+				"Action":                 "Publish",
+				"TopicArn":               "arn:aws:sns:us-east-1:123456789012:alerts.fifo",
+				"Subject":                "subject",
+				"Message":                "message\nalert1: https://www.example.com/test-image-1.jpg",
+				"MessageGroupId":         notify.Key("alertname").Hash(),
+				"MessageDeduplicationId": "",
+			},
+		},
+		{
+			name:         "Error if there is no destination",
+			settings:     `{"region": "us-east-1"}`,
+			expInitError: "must specify exactly one of topic ARN, target ARN or phone number",
+		},
+		{
+			name:         "Error if there are several destinations",
+			settings:     `{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts", "phone_number": "+15555550100"}`,
+			expInitError: "must specify exactly one of topic ARN, target ARN or phone number",
+		},
+		{
+			name:         "Error if the region is missing",
+			settings:     `{"phone_number": "+15555550100"}`,
+			expInitError: "could not find region property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var form url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Contains(t, r.Header.Get("Authorization"), "Credential=access/")
+				require.NoError(t, r.ParseForm())
+				form = r.PostForm
+				w.Header().Set("Content-Type", "text/xml")
+				_, _ = w.Write([]byte(snsPublishResponse))
+			}))
+			defer server.Close()
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			settingsJSON.Set("api_url", server.URL)
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+
+			fc := FactoryConfig{
+				Config: &NotificationChannelConfig{
+					Name:     "sns_testing",
+					Type:     "sns",
+					Settings: settingsJSON,
+					SecureSettings: map[string][]byte{
+						"access_key": encryptForTests(t, secretsService, "access"),
+						"secret_key": encryptForTests(t, secretsService, "secret"),
+					},
+				},
+				ImageStore:  newFakeImageStore(1),
+				DecryptFunc: secretsService.GetDecryptedValue,
+				Template:    tmpl,
+			}
+			n, err := buildSNSNotifier(fc)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			for k, v := range c.expForm {
+				if v == "" {
+					require.NotEmpty(t, form.Get(k), k)
+					continue
+				}
+				require.Equal(t, v, form.Get(k), k)
+			}
+		})
+	}
+
+	t.Run("should return an error if SNS fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>InvalidParameter</Code><Message>Invalid parameter: TopicArn</Message></Error></ErrorResponse>`))
+		}))
+		defer server.Close()
+
+		settingsJSON := simplejson.NewFromAny(map[string]interface{}{
+			"api_url":   server.URL,
+			"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts",
+		})
+		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+		n, err := buildSNSNotifier(FactoryConfig{
+			Config: &NotificationChannelConfig{
+				Name:     "sns_testing",
+				Type:     "sns",
+				Settings: settingsJSON,
+				SecureSettings: map[string][]byte{
+					"access_key": encryptForTests(t, secretsService, "access"),
+					"secret_key": encryptForTests(t, secretsService, "secret"),
+				},
+			},
+			ImageStore:  &UnavailableImageStore{},
+			DecryptFunc: secretsService.GetDecryptedValue,
+			Template:    tmpl,
+		})
+		require.NoError(t, err)
+
+		ok, err := n.Notify(notify.WithGroupKey(context.Background(), "alertname"), alerts...)
+		require.False(t, ok)
+		require.ErrorContains(t, err, "InvalidParameter")
+	})
+
+	t.Run("should follow the [aws] settings", func(t *testing.T) {
+		authCases := []struct {
+			name              string
+			settings          map[string]interface{}
+			allowedProviders  string
+			assumeRoleEnabled string
+			expError          string
+		}{
+			{
+				name:             "keys are not allowed",
+				settings:         map[string]interface{}{},
+				allowedProviders: "default",
+				expError:         `attempting to use an auth type that is not allowed: "keys"`,
+			},
+			{
+				name:              "assume role is disabled",
+				settings:          map[string]interface{}{"assume_role_arn": "arn:aws:iam::123456789012:role/alerting"},
+				allowedProviders:  "keys",
+				assumeRoleEnabled: "false",
+				expError:          "attempting to use assume role (ARN) which is disabled in grafana.ini",
+			},
+		}
+		for _, c := range authCases {
+			t.Run(c.name, func(t *testing.T) {
+				t.Setenv(awsds.AllowedAuthProvidersEnvVarKeyName, c.allowedProviders)
+				t.Setenv(awsds.AssumeRoleEnabledEnvVarKeyName, c.assumeRoleEnabled)
+
+				c.settings["topic_arn"] = "arn:aws:sns:us-east-1:123456789012:alerts"
+				secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+				n, err := buildSNSNotifier(FactoryConfig{
+					Config: &NotificationChannelConfig{
+						Name:     "sns_testing",
+						Type:     "sns",
+						Settings: simplejson.NewFromAny(c.settings),
+						SecureSettings: map[string][]byte{
+							"access_key": encryptForTests(t, secretsService, "access"),
+							"secret_key": encryptForTests(t, secretsService, "secret"),
+						},
+					},
+					ImageStore:  &UnavailableImageStore{},
+					DecryptFunc: secretsService.GetDecryptedValue,
+					Template:    tmpl,
+				})
+				require.NoError(t, err)
+
+				ok, err := n.Notify(notify.WithGroupKey(context.Background(), "alertname"), alerts...)
+				require.False(t, ok)
+				require.EqualError(t, err, c.expError)
+			})
+		}
+	})
+}
@@ -294,45 +294,7 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	var tmplErr error
 	tmpl, _ := TmplText(ctx, tn.tmpl, as, tn.log, &tmplErr)
 
-	card := NewAdaptiveCard()
-	card.AppendItem(AdaptiveCardTextBlockItem{
-		Color:  getTeamsTextColor(types.Alerts(as...)),
-		Text:   tmpl(tn.Title),
-		Size:   TextSizeLarge,
-		Weight: TextWeightBolder,
-		Wrap:   true,
-	})
-	card.AppendItem(AdaptiveCardTextBlockItem{
-		Text: tmpl(tn.Message),
-		Wrap: true,
-	})
-
-	var s AdaptiveCardImageSetItem
-	_ = withStoredImages(ctx, tn.log, tn.images,
-		func(_ int, image ngmodels.Image) error {
-			if image.URL != "" {
-				s.AppendImage(AdaptiveCardImageItem{URL: image.URL})
-			}
-			return nil
-		},
-		as...)
-
-	if len(s.Images) > 2 {
-		s.Size = ImageSizeMedium
-		card.AppendItem(s)
-	} else if len(s.Images) > 0 {
-		s.Size = ImageSizeLarge
-		card.AppendItem(s)
-	}
-
-	card.AppendItem(AdaptiveCardActionSetItem{
-		Actions: []AdaptiveCardActionItem{
-			AdaptiveCardOpenURLActionItem{
-				Title: "View URL",
-				URL:   joinUrlPath(tn.tmpl.ExternalURL.String(), "/alerting/list", tn.log),
-			},
-		},
-	})
+	card := newTeamsAdaptiveCard(ctx, tn.log, tn.images, tn.tmpl, tmpl(tn.Title), tmpl(tn.Message), as)
 
 	msg := NewAdaptiveCardsMessage(card)
 	msg.Summary = tmpl(tn.Title)
@@ -377,6 +339,51 @@ func (tn *TeamsNotifier) SendResolved() bool {
 	return !tn.GetDisableResolveMessage()
 }
 
+// newTeamsAdaptiveCard returns the Adaptive Card of the notification with the templated title and message, the images
+// of the alerts and a link to the alert list.
+func newTeamsAdaptiveCard(ctx context.Context, l log.Logger, images ImageStore, t *template.Template, title, message string, as []*types.Alert) AdaptiveCard {
+	card := NewAdaptiveCard()
+	card.AppendItem(AdaptiveCardTextBlockItem{
+		Color:  getTeamsTextColor(types.Alerts(as...)),
+		Text:   title,
+		Size:   TextSizeLarge,
+		Weight: TextWeightBolder,
+		Wrap:   true,
+	})
+	card.AppendItem(AdaptiveCardTextBlockItem{
+		Text: message,
+		Wrap: true,
+	})
+
+	var s AdaptiveCardImageSetItem
+	_ = withStoredImages(ctx, l, images,
+		func(_ int, image ngmodels.Image) error {
+			if image.URL != "" {
+				s.AppendImage(AdaptiveCardImageItem{URL: image.URL})
+			}
+			return nil
+		},
+		as...)
+
+	if len(s.Images) > 2 {
+		s.Size = ImageSizeMedium
+		card.AppendItem(s)
+	} else if len(s.Images) > 0 {
+		s.Size = ImageSizeLarge
+		card.AppendItem(s)
+	}
+
+	card.AppendItem(AdaptiveCardActionSetItem{
+		Actions: []AdaptiveCardActionItem{
+			AdaptiveCardOpenURLActionItem{
+				Title: "View URL",
+				URL:   joinUrlPath(t.ExternalURL.String(), "/alerting/list", l),
+			},
+		},
+	})
+	return card
+}
+
 // getTeamsTextColor returns the text color for the message title.
 func getTeamsTextColor(alerts model.Alerts) string {
 	if getAlertStatusColor(alerts.Status()) == ColorAlertFiring {
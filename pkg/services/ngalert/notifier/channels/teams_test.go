@@ -282,9 +282,9 @@ func TestTeamsNotifier(t *testing.T) {
 
 			webhookSender := CreateNotificationService(t)
 
-			originalClient := notifications.NetClient
+			originalClient := *notifications.NetClient
 			defer func() {
-				notifications.SetWebhookClient(*originalClient)
+				notifications.SetWebhookClient(originalClient)
 			}()
 			clientStub := newMockClient(c.response)
 			notifications.SetWebhookClient(clientStub)
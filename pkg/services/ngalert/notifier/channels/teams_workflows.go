@@ -0,0 +1,116 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// TeamsWorkflowsNotifier is responsible for sending alert notifications as Adaptive Cards to the webhook of a
+// Microsoft Teams workflow, which replaces the incoming webhooks of the Office 365 connectors.
+type TeamsWorkflowsNotifier struct {
+	*Base
+	log      log.Logger
+	ns       notifications.WebhookSender
+	images   ImageStore
+	tmpl     *template.Template
+	settings *teamsWorkflowsSettings
+}
+
+type teamsWorkflowsSettings struct {
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func buildTeamsWorkflowsSettings(fc FactoryConfig) (*teamsWorkflowsSettings, error) {
+	settings := &teamsWorkflowsSettings{}
+	err := fc.Config.unmarshalSettings(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		return nil, errors.New("could not find url property in settings")
+	}
+	if _, err := url.Parse(settings.URL); err != nil {
+		return nil, fmt.Errorf("invalid URL %q", settings.URL)
+	}
+	if settings.Title == "" {
+		settings.Title = DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = `{{ template "teams.default.message" .}}`
+	}
+	return settings, nil
+}
+
+func TeamsWorkflowsFactory(fc FactoryConfig) (NotificationChannel, error) {
+	notifier, err := buildTeamsWorkflowsNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+// buildTeamsWorkflowsNotifier is the constructor for the Microsoft Teams Workflows notifier.
+func buildTeamsWorkflowsNotifier(fc FactoryConfig) (*TeamsWorkflowsNotifier, error) {
+	settings, err := buildTeamsWorkflowsSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamsWorkflowsNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:      log.New("alerting.notifier.teamsworkflows"),
+		ns:       fc.NotificationService,
+		images:   fc.ImageStore,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (tn *TeamsWorkflowsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, tn.tmpl, as, tn.log, &tmplErr)
+
+	card := newTeamsAdaptiveCard(ctx, tn.log, tn.images, tn.tmpl, tmpl(tn.settings.Title), tmpl(tn.settings.Message), as)
+	if tmplErr != nil {
+		tn.log.Warn("failed to template Teams Workflows message", "error", tmplErr.Error())
+	}
+
+	b, err := json.Marshal(NewAdaptiveCardsMessage(card))
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Unlike the connectors, the workflows do not respond with "1": they accept the request with 202 and an empty body,
+	// and report the errors with the status code, which is checked by the webhook sender.
+	cmd := &models.SendWebhookSync{Url: tn.settings.URL, Body: string(b)}
+	if err := tn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		tn.log.Error("failed to send notification to Teams Workflows", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (tn *TeamsWorkflowsNotifier) SendResolved() bool {
+	return !tn.GetDisableResolveMessage()
+}
@@ -0,0 +1,158 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestTeamsWorkflowsNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		status       int
+		expMsg       map[string]interface{}
+		expInitError string
+		expMsgError  string
+	}{
+		{
+			name:     "Custom title and message with an image",
+			settings: `{"title": "{{ .CommonLabels.alertname }}", "message": "{{ len .Alerts.Firing }} firing"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			status: http.StatusAccepted,
+			expMsg: map[string]interface{}{
+				"attachments": []map[string]interface{}{{
+					"content": map[string]interface{}{
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"body": []map[string]interface{}{{
+							"color":  "attention",
+							"size":   "large",
+							"text":   "alert1",
+							"type":   "TextBlock",
+							"weight": "bolder",
+							"wrap":   true,
+						}, {
+							"text": "1 firing",
+							"type": "TextBlock",
+							"wrap": true,
+						}, {
+							"images": []map[string]interface{}{{
+								"type":    "Image",
+								"url":     "https://www.example.com/test-image-1.jpg",
+								"msTeams": map[string]interface{}{"allowExpand": true},
+							}},
+							"imageSize": "large",
+							"type":      "ImageSet",
+						}, {
+							"actions": []map[string]interface{}{{
+								"title": "View URL",
+								"type":  "Action.OpenUrl",
+								"url":   "http://localhost/alerting/list",
+							}},
+							"type": "ActionSet",
+						}},
+						"type":    "AdaptiveCard",
+						"version": "1.4",
+						"msTeams": map[string]interface{}{"width": "Full"},
+					},
+					"contentType": "application/vnd.microsoft.card.adaptive",
+				}},
+				"type": "message",
+			},
+		},
+		{
+			name:     "Error if the workflow rejects the card",
+			settings: `{"message": "message"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			status:      http.StatusBadRequest,
+			expMsgError: "webhook response status 400 Bad Request",
+		},
+		{
+			name:         "Error if the URL is missing",
+			settings:     `{"url": ""}`,
+			expInitError: "could not find url property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				body = b
+				w.WriteHeader(c.status)
+			}))
+			defer server.Close()
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			if _, ok := settingsJSON.CheckGet("url"); !ok {
+				settingsJSON.Set("url", server.URL+"/workflows/123/triggers/manual/paths/invoke")
+			}
+
+			fc := FactoryConfig{
+				Config: &NotificationChannelConfig{
+					Name:     "teams_workflows_testing",
+					Type:     "teamsworkflows",
+					Settings: settingsJSON,
+				},
+				ImageStore:          newFakeImageStore(1),
+				NotificationService: CreateNotificationService(t),
+				Template:            tmpl,
+			}
+			n, err := buildTeamsWorkflowsNotifier(fc)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			if c.expMsgError != "" {
+				require.False(t, ok)
+				require.ErrorContains(t, err, c.expMsgError)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expBody), string(body))
+		})
+	}
+}
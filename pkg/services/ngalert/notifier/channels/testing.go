@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -172,3 +173,11 @@ func CreateNotificationService(t *testing.T) *notifications.NotificationService
 
 	return ns
 }
+
+// encryptForTests encrypts the value for the secure settings of a notification channel.
+func encryptForTests(t *testing.T, secretsService secrets.Service, value string) []byte {
+	t.Helper()
+	encrypted, err := secretsService.Encrypt(context.Background(), []byte(value), secrets.WithoutScope())
+	require.NoError(t, err)
+	return encrypted
+}
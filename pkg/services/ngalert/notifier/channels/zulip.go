@@ -0,0 +1,176 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+const (
+	// zulipMaxTopicLength is the maximum number of characters of a topic in Zulip.
+	zulipMaxTopicLength = 60
+	// zulipMaxContentLength is the maximum number of bytes of a message in Zulip.
+	zulipMaxContentLength = 10000
+)
+
+// ZulipNotifier is responsible for sending alert notifications to a stream of Zulip as a bot.
+type ZulipNotifier struct {
+	*Base
+	log      log.Logger
+	ns       notifications.WebhookSender
+	images   ImageStore
+	tmpl     *template.Template
+	settings *zulipSettings
+}
+
+type zulipSettings struct {
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	BotEmail string `json:"bot_email,omitempty" yaml:"bot_email,omitempty"`
+	APIKey   string `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	Stream   string `json:"stream,omitempty" yaml:"stream,omitempty"`
+	Topic    string `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func buildZulipSettings(fc FactoryConfig) (*zulipSettings, error) {
+	settings := &zulipSettings{}
+	err := fc.Config.unmarshalSettings(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		return nil, errors.New("could not find Zulip server url property in settings")
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q", settings.URL)
+	}
+	settings.URL = strings.TrimSuffix(u.String(), "/")
+	if settings.BotEmail == "" {
+		return nil, errors.New("could not find bot email property in settings")
+	}
+	settings.APIKey = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "api_key", settings.APIKey)
+	if settings.APIKey == "" {
+		return nil, errors.New("could not find API key property in settings")
+	}
+	if settings.Stream == "" {
+		return nil, errors.New("could not find stream property in settings")
+	}
+	if settings.Topic == "" {
+		settings.Topic = DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = DefaultMessageEmbed
+	}
+	return settings, nil
+}
+
+func ZulipFactory(fc FactoryConfig) (NotificationChannel, error) {
+	notifier, err := buildZulipNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+// buildZulipNotifier is the constructor for the Zulip notifier.
+func buildZulipNotifier(fc FactoryConfig) (*ZulipNotifier, error) {
+	settings, err := buildZulipSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	return &ZulipNotifier{
+		Base: NewBase(&models.AlertNotification{
+			Uid:                   fc.Config.UID,
+			Name:                  fc.Config.Name,
+			Type:                  fc.Config.Type,
+			DisableResolveMessage: fc.Config.DisableResolveMessage,
+			Settings:              fc.Config.Settings,
+		}),
+		log:      log.New("alerting.notifier.zulip"),
+		ns:       fc.NotificationService,
+		images:   fc.ImageStore,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (zn *ZulipNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := TmplText(ctx, zn.tmpl, as, zn.log, &tmplErr)
+
+	topic := truncateInRunes(tmpl(zn.settings.Topic), zulipMaxTopicLength)
+	content := tmpl(zn.settings.Message)
+	if tmplErr != nil {
+		zn.log.Warn("failed to template Zulip message", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+
+	// Zulip shows a preview of the links to images
+	var images strings.Builder
+	_ = withStoredImages(ctx, zn.log, zn.images, func(index int, image ngmodels.Image) error {
+		if image.HasURL() {
+			images.WriteString(fmt.Sprintf("\n[%s](%s)", as[index].Name(), image.URL))
+		}
+		return nil
+	}, as...)
+	if images.Len() > 0 {
+		content += "\n" + images.String()
+	}
+	content, truncated := TruncateInBytes(content, zulipMaxContentLength)
+	if truncated {
+		zn.log.Warn("Zulip message too long, truncating message", "OriginalMessage", zn.settings.Message)
+	}
+
+	form := url.Values{}
+	form.Set("type", "stream")
+	form.Set("to", tmpl(zn.settings.Stream))
+	form.Set("topic", topic)
+	form.Set("content", content)
+	if tmplErr != nil {
+		zn.log.Warn("failed to template Zulip stream", "error", tmplErr.Error(), "fallback", zn.settings.Stream)
+		form.Set("to", zn.settings.Stream)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         zn.settings.URL + "/api/v1/messages",
+		User:        zn.settings.BotEmail,
+		Password:    zn.settings.APIKey,
+		Body:        form.Encode(),
+		HttpMethod:  http.MethodPost,
+		ContentType: "application/x-www-form-urlencoded",
+	}
+	if err := zn.ns.SendWebhookSync(ctx, cmd); err != nil {
+		zn.log.Error("failed to send notification to Zulip", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (zn *ZulipNotifier) SendResolved() bool {
+	return !zn.GetDisableResolveMessage()
+}
+
+// truncateInRunes truncates a string to the given number of characters, the last being the truncation marker.
+func truncateInRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + truncationMarker
+}
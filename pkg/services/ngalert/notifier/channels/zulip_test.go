@@ -0,0 +1,146 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestZulipNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		apiKey       string
+		alerts       []*types.Alert
+		expForm      url.Values
+		expInitError string
+	}{
+		{
+			name: "Custom topic and message with an image",
+			settings: `{
+				"bot_email": "grafana-bot@example.com",
+				"stream": "alerts",
+				"topic": "{{ .CommonLabels.alertname }}",
+				"message": "{{ len .Alerts.Firing }} firing"
+			}`,
+			apiKey: "secret",
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {"alert1"},
+				"content": {"1 firing\n\n[alert1](https://www.example.com/test-image-1.jpg)"},
+			},
+		},
+		{
+			name: "Truncate long topic",
+			settings: `{
+				"bot_email": "grafana-bot@example.com",
+				"stream": "alerts",
+				"topic": "{{ .CommonLabels.alertname }}",
+				"message": "message"
+			}`,
+			apiKey: "secret",
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": model.LabelValue(strings.Repeat("1", 100))},
+					},
+				},
+			},
+			expForm: url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {strings.Repeat("1", zulipMaxTopicLength-1) + "…"},
+				"content": {"message"},
+			},
+		},
+		{
+			name:         "Error if the stream is missing",
+			settings:     `{"bot_email": "grafana-bot@example.com"}`,
+			apiKey:       "secret",
+			expInitError: "could not find stream property in settings",
+		},
+		{
+			name:         "Error if the API key is missing",
+			settings:     `{"bot_email": "grafana-bot@example.com", "stream": "alerts"}`,
+			expInitError: "could not find API key property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var form url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "/api/v1/messages", r.URL.Path)
+				user, password, ok := r.BasicAuth()
+				require.True(t, ok)
+				require.Equal(t, "grafana-bot@example.com", user)
+				require.Equal(t, "secret", password)
+				require.NoError(t, r.ParseForm())
+				form = r.PostForm
+			}))
+			defer server.Close()
+
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			settingsJSON.Set("url", server.URL+"/")
+			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+			secureSettings := map[string][]byte{}
+			if c.apiKey != "" {
+				secureSettings["api_key"] = encryptForTests(t, secretsService, c.apiKey)
+			}
+
+			fc := FactoryConfig{
+				Config: &NotificationChannelConfig{
+					Name:           "zulip_testing",
+					Type:           "zulip",
+					Settings:       settingsJSON,
+					SecureSettings: secureSettings,
+				},
+				ImageStore:          newFakeImageStore(1),
+				NotificationService: CreateNotificationService(t),
+				DecryptFunc:         secretsService.GetDecryptedValue,
+				Template:            tmpl,
+			}
+			n, err := buildZulipNotifier(fc)
+			if c.expInitError != "" {
+				require.EqualError(t, err, c.expInitError)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, c.expForm, form)
+		})
+	}
+}
@@ -1,9 +1,39 @@
 package channels_config
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
 )
 
+var (
+	pluginNotifiersMtx sync.RWMutex
+	pluginNotifiers    = map[string]*NotifierPlugin{}
+)
+
+// RegisterPluginNotifier adds the metadata of a notification channel that is provided by a plugin to the available
+// notifiers. It replaces the metadata of a channel of the same type that was registered before.
+func RegisterPluginNotifier(notifier *NotifierPlugin) {
+	pluginNotifiersMtx.Lock()
+	defer pluginNotifiersMtx.Unlock()
+	pluginNotifiers[notifier.Type] = notifier
+}
+
+// getPluginNotifiers returns the metadata of the notification channels that are provided by plugins, sorted by type.
+func getPluginNotifiers() []*NotifierPlugin {
+	pluginNotifiersMtx.RLock()
+	defer pluginNotifiersMtx.RUnlock()
+	result := make([]*NotifierPlugin, 0, len(pluginNotifiers))
+	for _, n := range pluginNotifiers {
+		result = append(result, n)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
 // GetAvailableNotifiers returns the metadata of all the notification channels that can be configured.
 func GetAvailableNotifiers() []*NotifierPlugin {
 	pushoverSoundOptions := []SelectOption{
@@ -103,7 +133,7 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 		},
 	}
 
-	return []*NotifierPlugin{
+	notifiers := []*NotifierPlugin{
 		{
 			Type:        "dingding",
 			Name:        "DingDing",
@@ -640,6 +670,37 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "teamsworkflows",
+			Name:        "Microsoft Teams Workflows",
+			Description: "Sends notifications to the webhook of a Microsoft Teams workflow",
+			Heading:     "Teams Workflows settings",
+			Info:        "The workflow must post the Adaptive Card it receives to a chat or a channel.",
+			Options: []NotifierOption{
+				{
+					Label:        "URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "Teams workflow webhook url",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the Teams message.",
+					PropertyName: "title",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Placeholder:  channels.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
 		{
 			Type:        "telegram",
 			Name:        "Telegram",
@@ -1145,5 +1206,249 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "mattermost",
+			Name:        "Mattermost",
+			Description: "Sends notifications to Mattermost incoming webhooks",
+			Heading:     "Mattermost settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Webhook URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://mattermost.example.com/hooks/xxx-generatedkey-xxx",
+					Description:  "URL of the incoming webhook.",
+					PropertyName: "url",
+					Secure:       true,
+					Required:     true,
+				},
+				{
+					Label:        "Channel",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the channel of the incoming webhook, if it is allowed.",
+					PropertyName: "channel",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the username of the incoming webhook, if it is allowed.",
+					PropertyName: "username",
+				},
+				{
+					Label:        "Icon URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the profile picture of the incoming webhook, if it is allowed.",
+					PropertyName: "icon_url",
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message Content",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message, Markdown is supported.",
+					Placeholder:  channels.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "zulip",
+			Name:        "Zulip",
+			Description: "Sends notifications to a Zulip stream",
+			Heading:     "Zulip settings",
+			Info:        "Notifications are sent by a generic bot that must be subscribed to the stream.",
+			Options: []NotifierOption{
+				{
+					Label:        "Server URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://example.zulipchat.com",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Bot Email",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana-bot@example.zulipchat.com",
+					PropertyName: "bot_email",
+					Required:     true,
+				},
+				{
+					Label:        "API Key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "API key of the bot.",
+					PropertyName: "api_key",
+					Secure:       true,
+					Required:     true,
+				},
+				{
+					Label:        "Stream",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "alerts",
+					PropertyName: "stream",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated topic of the message, truncated to 60 characters.",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "topic",
+				},
+				{
+					Label:        "Message Content",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message, Markdown is supported.",
+					Placeholder:  channels.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "matrix",
+			Name:        "Matrix",
+			Description: "Sends notifications to a Matrix room",
+			Heading:     "Matrix settings",
+			Info:        "The user of the access token must have joined the room.",
+			Options: []NotifierOption{
+				{
+					Label:        "Homeserver URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://matrix.example.com",
+					PropertyName: "homeserver_url",
+					Required:     true,
+				},
+				{
+					Label:        "Room ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "!qporfwt:matrix.example.com",
+					PropertyName: "room_id",
+					Required:     true,
+				},
+				{
+					Label:        "Access Token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "Access token of the user that sends the messages.",
+					PropertyName: "access_token",
+					Secure:       true,
+					Required:     true,
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message Content",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message",
+					Placeholder:  channels.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "sns",
+			Name:        "AWS SNS",
+			Description: "Publishes notifications to AWS SNS",
+			Heading:     "AWS SNS settings",
+			Info:        "Specify exactly one of topic ARN, target ARN or phone number.",
+			Options: []NotifierOption{
+				{
+					Label:        "Topic ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "arn:aws:sns:us-east-1:123456789012:alerts",
+					PropertyName: "topic_arn",
+				},
+				{
+					Label:        "Target ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "ARN of a mobile platform endpoint.",
+					PropertyName: "target_arn",
+				},
+				{
+					Label:        "Phone Number",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Phone number in E.164 format to send the notifications by SMS.",
+					Placeholder:  "+15555550100",
+					PropertyName: "phone_number",
+				},
+				{
+					Label:        "Region",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Defaults to the region of the topic or target ARN.",
+					Placeholder:  "us-east-1",
+					PropertyName: "region",
+				},
+				{
+					Label:        "API URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the endpoint of the SNS API.",
+					PropertyName: "api_url",
+				},
+				{
+					Label:        "Access Key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Leave empty to use the default credentials of the environment of Grafana.",
+					PropertyName: "access_key",
+					Secure:       true,
+				},
+				{
+					Label:        "Secret Key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "secret_key",
+					Secure:       true,
+				},
+				{
+					Label:        "Assume Role ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "ARN of a role to assume with the credentials.",
+					PropertyName: "assume_role_arn",
+				},
+				{
+					Label:        "Subject",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated subject of the emails, truncated to 100 characters.",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "Message Content",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message",
+					Placeholder:  channels.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
+	return append(notifiers, getPluginNotifiers()...)
 }
@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+// deliveryLogCapacity is the number of notification attempts that are kept per organization.
+const deliveryLogCapacity = 1000
+
+// urlRegex matches the URLs in the errors of the integrations, such as the errors of the HTTP client, which contain
+// the URL of the request.
+var urlRegex = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// ErrReceiverNotFound is returned when the receiver does not exist in the configuration of the Alertmanager.
+var ErrReceiverNotFound = errors.New("receiver not found")
+
+// deliveryLog keeps the latest notification attempts of the integrations of an Alertmanager in memory.
+// When it is full, the oldest attempts are replaced.
+type deliveryLog struct {
+	mtx        sync.RWMutex
+	deliveries []apimodels.NotificationDelivery
+	next       int
+	full       bool
+}
+
+func newDeliveryLog(capacity int) *deliveryLog {
+	return &deliveryLog{deliveries: make([]apimodels.NotificationDelivery, capacity)}
+}
+
+func (l *deliveryLog) add(d apimodels.NotificationDelivery) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.deliveries[l.next] = d
+	l.next = (l.next + 1) % len(l.deliveries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// list returns the attempts of the receiver, most recent first. A limit less than or equal to 0 returns them all.
+func (l *deliveryLog) list(receiver string, limit int) []apimodels.NotificationDelivery {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	size := l.next
+	if l.full {
+		size = len(l.deliveries)
+	}
+	result := make([]apimodels.NotificationDelivery, 0)
+	for i := 1; i <= size; i++ {
+		d := l.deliveries[(l.next-i+len(l.deliveries))%len(l.deliveries)]
+		if d.Receiver != receiver {
+			continue
+		}
+		result = append(result, d)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
+}
+
+// GetReceiverDeliveries returns the latest notification attempts of the integrations of the receiver, most recent
+// first. It returns ErrReceiverNotFound if the receiver does not exist.
+func (am *Alertmanager) GetReceiverDeliveries(receiver string, limit int) ([]apimodels.NotificationDelivery, error) {
+	am.reloadConfigMtx.RLock()
+	found := false
+	for _, r := range am.receivers {
+		if r.Name() == receiver {
+			found = true
+			break
+		}
+	}
+	am.reloadConfigMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrReceiverNotFound, receiver)
+	}
+	return am.deliveries.list(receiver, limit), nil
+}
+
+type deliveryAttemptsKey struct{}
+
+// withDeliveryAttempts returns a stage that counts the attempts of the retry stage that follows it, so that the
+// integration can record which attempt it is.
+func withDeliveryAttempts() notify.Stage {
+	return notify.StageFunc(func(ctx context.Context, _ gokitlog.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+		return context.WithValue(ctx, deliveryAttemptsKey{}, new(int)), alerts, nil
+	})
+}
+
+// nextDeliveryAttempt returns the number of attempts before this one, and counts this one.
+func nextDeliveryAttempt(ctx context.Context) int {
+	attempts, ok := ctx.Value(deliveryAttemptsKey{}).(*int)
+	if !ok {
+		return 0
+	}
+	// the attempts of the retry stage are sequential
+	previous := *attempts
+	*attempts++
+	return previous
+}
+
+// deliveryRecorder is a notifier that records the attempts of the notifier of an integration in the delivery log and in
+// the metrics.
+type deliveryRecorder struct {
+	channels.NotificationChannel
+	orgID       string
+	receiver    string
+	integration string
+	uid         string
+	index       int
+	deliveries  *deliveryLog
+	metrics     *metrics.Integration
+}
+
+func (r *deliveryRecorder) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	retry := nextDeliveryAttempt(ctx)
+	start := time.Now()
+	ok, err := r.NotificationChannel.Notify(ctx, alerts...)
+	duration := time.Since(start)
+
+	groupKey, _ := notify.GroupKey(ctx)
+	d := apimodels.NotificationDelivery{
+		Receiver:       r.receiver,
+		Integration:    r.integration,
+		IntegrationUID: r.uid,
+		IntegrationIdx: r.index,
+		GroupKey:       groupKey,
+		Alerts:         len(alerts),
+		Status:         apimodels.NotificationDeliverySuccess,
+		Duration:       duration.String(),
+		Retry:          retry,
+		Timestamp:      start,
+	}
+	if err != nil {
+		d.Status = apimodels.NotificationDeliveryFailure
+		d.Error = redactURLs(err.Error())
+		d.StatusCode = statusCodeFromError(err)
+	}
+	r.deliveries.add(d)
+
+	if r.metrics != nil {
+		r.metrics.Attempts.With(prometheus.Labels{"org": r.orgID, "integration": r.integration, "result": d.Status}).Inc()
+		r.metrics.Duration.With(prometheus.Labels{"org": r.orgID, "integration": r.integration}).Observe(duration.Seconds())
+		if retry > 0 {
+			r.metrics.Retries.With(prometheus.Labels{"org": r.orgID, "integration": r.integration}).Inc()
+		}
+	}
+	return ok, err
+}
+
+// statusCodeFromError returns the status code of the response of the integration, or 0 if the error does not have it.
+func statusCodeFromError(err error) int {
+	var webhookErr notifications.WebhookResponseError
+	if errors.As(err, &webhookErr) {
+		return webhookErr.StatusCode
+	}
+	// such as the errors of the AWS SDK
+	var requestErr interface{ StatusCode() int }
+	if errors.As(err, &requestErr) {
+		return requestErr.StatusCode()
+	}
+	return 0
+}
+
+// redactURLs replaces the URLs of an error with their scheme and host, as the user info, the path and the query of
+// URLs can contain secrets, such as the token of a Slack webhook or of a Telegram bot.
+func redactURLs(msg string) string {
+	return urlRegex.ReplaceAllStringFunc(msg, func(match string) string {
+		u, err := url.Parse(match)
+		if err != nil || u.Host == "" {
+			return "[redacted]"
+		}
+		return u.Scheme + "://" + u.Host + "/[redacted]"
+	})
+}
@@ -181,7 +181,9 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 			// These metrics are not exported by Grafana and are mostly a placeholder.
 			// To export them, we need to translate the metrics from each individual registry and,
 			// then aggregate them on the main registry.
+			// The metrics of the integrations are the exception, they are shared by all organizations.
 			m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
+			m.Integration = moa.metrics.Integration
 			am, err := newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
 			if err != nil {
 				moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "error", err)
@@ -309,6 +311,41 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// CheckClusterMembership returns an error if the members of the HA cluster cannot be known with the configured peer,
+// which would silently make every member evaluate every rule when the evaluation of the rules is sharded.
+func (moa *MultiOrgAlertmanager) CheckClusterMembership() error {
+	switch moa.peer.(type) {
+	case *cluster.Peer, *NilPeer:
+		return nil
+	default:
+		return fmt.Errorf("the members of the HA cluster cannot be listed with the peer of type %T", moa.peer)
+	}
+}
+
+// ClusterMembers returns the names of the members of the HA cluster, including this instance.
+// It returns nil if the HA cluster is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembers() []string {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return nil
+	}
+	peers := p.Peers()
+	members := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		members = append(members, peer.Name())
+	}
+	return members
+}
+
+// ClusterMemberName returns the name of this instance in the HA cluster, or an empty string if it is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMemberName() string {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return ""
+	}
+	return p.Name()
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
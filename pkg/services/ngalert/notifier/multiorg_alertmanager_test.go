@@ -287,3 +287,16 @@ var brokenConfig = `
 		}]
 	}
 }`
+
+type unknownPeer struct {
+	NilPeer
+}
+
+func TestMultiOrgAlertmanager_CheckClusterMembership(t *testing.T) {
+	moa := &MultiOrgAlertmanager{peer: &NilPeer{}}
+	require.NoError(t, moa.CheckClusterMembership())
+	require.Nil(t, moa.ClusterMembers())
+
+	moa.peer = &unknownPeer{}
+	require.ErrorContains(t, moa.CheckClusterMembership(), "unknownPeer")
+}
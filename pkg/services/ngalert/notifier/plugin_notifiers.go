@@ -0,0 +1,219 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// PluginNotifyPath is the path of the resource of a backend plugin that receives the notifications.
+const PluginNotifyPath = "notify"
+
+// PluginContextProvider provides the context of the requests to the plugins.
+type PluginContextProvider interface {
+	Get(ctx context.Context, pluginID string, user *user.SignedInUser) (backend.PluginContext, bool, error)
+}
+
+// PluginNotifyClient sends the notifications to the resource PluginNotifyPath of the backend plugins.
+type PluginNotifyClient struct {
+	client          backend.CallResourceHandler
+	contextProvider PluginContextProvider
+}
+
+func NewPluginNotifyClient(client backend.CallResourceHandler, contextProvider PluginContextProvider) *PluginNotifyClient {
+	return &PluginNotifyClient{client: client, contextProvider: contextProvider}
+}
+
+// Notify implements channels.PluginNotifyClient. The plugin must reply with a 2xx status code.
+func (c *PluginNotifyClient) Notify(ctx context.Context, orgID int64, pluginID string, body []byte) error {
+	notifierUser := &user.SignedInUser{
+		UserID:           -1,
+		IsServiceAccount: true,
+		Login:            "grafana_alertmanager",
+		OrgID:            orgID,
+		OrgRole:          org.RoleAdmin,
+	}
+	pCtx, exists, err := c.contextProvider.Get(ctx, pluginID, notifierUser)
+	if err != nil {
+		return fmt.Errorf("failed to get the context of plugin %s: %w", pluginID, err)
+	}
+	if !exists {
+		return fmt.Errorf("plugin %s is not installed", pluginID)
+	}
+
+	var resp *backend.CallResourceResponse
+	err = c.client.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: pCtx,
+		Path:          PluginNotifyPath,
+		Method:        http.MethodPost,
+		URL:           PluginNotifyPath,
+		Headers:       map[string][]string{"Content-Type": {"application/json"}},
+		Body:          body,
+	}, callResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		// only the first response is kept, a plugin could stream more
+		if resp == nil {
+			resp = r
+		}
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to send the notification to plugin %s: %w", pluginID, err)
+	}
+	if resp == nil {
+		return fmt.Errorf("plugin %s did not reply to the notification", pluginID)
+	}
+	if resp.Status/100 != 2 {
+		return fmt.Errorf("plugin %s replied with status %d: %s", pluginID, resp.Status, string(resp.Body))
+	}
+	return nil
+}
+
+type callResourceResponseSenderFunc func(*backend.CallResourceResponse) error
+
+func (fn callResourceResponseSenderFunc) Send(resp *backend.CallResourceResponse) error {
+	return fn(resp)
+}
+
+// RegisterPluginNotifiers registers a receiver type for every backend plugin that declares a notifier in its
+// plugin.json. The type of the receivers is the ID of the plugin. A plugin that declares an invalid notifier is skipped.
+func RegisterPluginNotifiers(ctx context.Context, store plugins.Store, client channels.PluginNotifyClient, logger log.Logger) {
+	for _, p := range store.Plugins(ctx) {
+		if p.Notifier == nil {
+			continue
+		}
+		logger := logger.New("pluginId", p.ID)
+		if !p.Backend {
+			logger.Warn("Notifier of the plugin is ignored because it is not a backend plugin")
+			continue
+		}
+		schema, err := parseNotifierSchema(p.Notifier.SettingsSchema)
+		if err != nil {
+			logger.Warn("Notifier of the plugin is ignored because its settings schema is invalid", "error", err)
+			continue
+		}
+		factory := channels.PluginNotifierFactory(channels.PluginNotifierConfig{
+			PluginID:     p.ID,
+			SecureFields: p.Notifier.SecureFields,
+			Validate:     settingsValidator(schema),
+			Client:       client,
+		})
+		if !channels.RegisterPluginFactory(p.ID, factory) {
+			logger.Warn("Notifier of the plugin is ignored because its ID is the type of a built-in notifier")
+			continue
+		}
+		channels_config.RegisterPluginNotifier(pluginNotifierMetadata(p, schema))
+		logger.Info("Registered the notifier of the plugin")
+	}
+}
+
+func parseNotifierSchema(raw json.RawMessage) (*openapi3.Schema, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("settingsSchema is required")
+	}
+	schema := &openapi3.Schema{}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		return nil, err
+	}
+	if schema.Type != openapi3.TypeObject {
+		return nil, fmt.Errorf("the type of the schema must be %q", openapi3.TypeObject)
+	}
+	if err := schema.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// settingsValidator returns a function that validates the settings of a receiver against the schema.
+func settingsValidator(schema *openapi3.Schema) func(map[string]interface{}) error {
+	return func(settings map[string]interface{}) error {
+		// the settings can contain json.Number, which the schema does not support, so they are decoded again
+		b, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := json.Unmarshal(b, &value); err != nil {
+			return err
+		}
+		if err := schema.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+		return nil
+	}
+}
+
+// pluginNotifierMetadata returns the metadata of the notifier, in which the options are the properties of the schema.
+func pluginNotifierMetadata(p plugins.PluginDTO, schema *openapi3.Schema) *channels_config.NotifierPlugin {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	secure := make(map[string]bool, len(p.Notifier.SecureFields))
+	for _, name := range p.Notifier.SecureFields {
+		secure[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := make([]channels_config.NotifierOption, 0, len(names))
+	for _, name := range names {
+		prop := schema.Properties[name].Value
+		if prop == nil {
+			continue
+		}
+		option := channels_config.NotifierOption{
+			Element:      channels_config.ElementTypeInput,
+			InputType:    channels_config.InputTypeText,
+			Label:        prop.Title,
+			Description:  prop.Description,
+			PropertyName: name,
+			Required:     required[name],
+			Secure:       secure[name],
+		}
+		if option.Label == "" {
+			option.Label = name
+		}
+		if def, ok := prop.Default.(string); ok {
+			option.Placeholder = def
+		}
+		switch {
+		case len(prop.Enum) > 0:
+			option.Element = channels_config.ElementTypeSelect
+			for _, v := range prop.Enum {
+				value := fmt.Sprint(v)
+				option.SelectOptions = append(option.SelectOptions, channels_config.SelectOption{Value: value, Label: value})
+			}
+		case prop.Type == openapi3.TypeBoolean:
+			option.Element = channels_config.ElementTypeCheckbox
+		}
+		options = append(options, option)
+	}
+
+	name := p.Notifier.Name
+	if name == "" {
+		name = p.Name
+	}
+	return &channels_config.NotifierPlugin{
+		Type:        p.ID,
+		Name:        name,
+		Description: p.Notifier.Description,
+		Heading:     name + " settings",
+		Options:     options,
+	}
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakePluginContextProvider struct {
+	installed bool
+}
+
+func (f *fakePluginContextProvider) Get(_ context.Context, pluginID string, user *user.SignedInUser) (backend.PluginContext, bool, error) {
+	if !f.installed {
+		return backend.PluginContext{}, false, nil
+	}
+	return backend.PluginContext{OrgID: user.OrgID, PluginID: pluginID}, true, nil
+}
+
+func TestRegisterPluginNotifiers(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"required": ["project"],
+		"properties": {
+			"project": {"type": "string", "title": "Project", "description": "The project of the tickets."},
+			"priority": {"type": "string", "enum": ["low", "high"]},
+			"assign": {"type": "boolean"},
+			"token": {"type": "string", "title": "API token"}
+		}
+	}`)
+	notifierPlugin := func(id string, backend bool, schema []byte) plugins.PluginDTO {
+		return plugins.PluginDTO{JSONData: plugins.JSONData{
+			ID:      id,
+			Type:    plugins.App,
+			Name:    "Ticketing",
+			Backend: backend,
+			Notifier: &plugins.NotifierJSONData{
+				Description:    "Creates tickets",
+				SettingsSchema: schema,
+				SecureFields:   []string{"token"},
+			},
+		}}
+	}
+	store := plugins.FakePluginStore{PluginList: []plugins.PluginDTO{
+		notifierPlugin("acme-ticketing-app", true, schema),
+		notifierPlugin("acme-frontend-app", false, schema),
+		notifierPlugin("acme-invalid-app", true, []byte(`{"type": "string"}`)),
+		notifierPlugin("webhook", true, schema),
+		{JSONData: plugins.JSONData{ID: "acme-other-app", Type: plugins.App, Backend: true}},
+	}}
+
+	RegisterPluginNotifiers(context.Background(), store, NewPluginNotifyClient(nil, &fakePluginContextProvider{}), log.NewNopLogger())
+
+	for _, id := range []string{"acme-frontend-app", "acme-invalid-app", "acme-other-app"} {
+		_, exists := channels.Factory(id)
+		require.False(t, exists, id)
+	}
+
+	var metadata *channels_config.NotifierPlugin
+	webhooks := 0
+	for _, n := range channels_config.GetAvailableNotifiers() {
+		switch n.Type {
+		case "acme-ticketing-app":
+			metadata = n
+		case "webhook":
+			webhooks++
+		}
+	}
+	require.Equal(t, 1, webhooks)
+	require.NotNil(t, metadata)
+	require.Equal(t, "Ticketing", metadata.Name)
+	require.Equal(t, "Creates tickets", metadata.Description)
+	require.Equal(t, []channels_config.NotifierOption{
+		{Element: channels_config.ElementTypeCheckbox, InputType: channels_config.InputTypeText, Label: "assign", PropertyName: "assign"},
+		{Element: channels_config.ElementTypeSelect, InputType: channels_config.InputTypeText, Label: "priority", PropertyName: "priority", SelectOptions: []channels_config.SelectOption{
+			{Value: "low", Label: "low"}, {Value: "high", Label: "high"},
+		}},
+		{Element: channels_config.ElementTypeInput, InputType: channels_config.InputTypeText, Label: "Project", Description: "The project of the tickets.", PropertyName: "project", Required: true},
+		{Element: channels_config.ElementTypeInput, InputType: channels_config.InputTypeText, Label: "API token", PropertyName: "token", Secure: true},
+	}, metadata.Options)
+
+	factory, exists := channels.Factory("acme-ticketing-app")
+	require.True(t, exists)
+	build := func(settings string) error {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		fc, err := channels.NewFactoryConfig(&channels.NotificationChannelConfig{Type: "acme-ticketing-app", Settings: settingsJSON}, nil, func(_ context.Context, _ map[string][]byte, _ string, fallback string) string {
+			return fallback
+		}, nil, nil)
+		require.NoError(t, err)
+		_, err = factory(fc)
+		return err
+	}
+	require.NoError(t, build(`{"project": "OPS", "priority": "high", "token": "secret"}`))
+	require.ErrorContains(t, build(`{"priority": "high"}`), "project")
+	require.ErrorContains(t, build(`{"project": "OPS", "priority": "urgent"}`), "priority")
+}
+
+func TestPluginNotifyClient_Notify(t *testing.T) {
+	var request *backend.CallResourceRequest
+	reply := func(status int) backend.CallResourceHandlerFunc {
+		return func(_ context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+			request = req
+			return sender.Send(&backend.CallResourceResponse{Status: status, Body: []byte("reply")})
+		}
+	}
+
+	t.Run("should post the notification to the resource of the plugin", func(t *testing.T) {
+		client := NewPluginNotifyClient(reply(http.StatusAccepted), &fakePluginContextProvider{installed: true})
+		require.NoError(t, client.Notify(context.Background(), 2, "acme-ticketing-app", []byte(`{}`)))
+		require.Equal(t, PluginNotifyPath, request.Path)
+		require.Equal(t, http.MethodPost, request.Method)
+		require.Equal(t, []byte(`{}`), request.Body)
+		require.Equal(t, int64(2), request.PluginContext.OrgID)
+		require.Equal(t, "acme-ticketing-app", request.PluginContext.PluginID)
+	})
+
+	t.Run("should fail if the plugin replies with an error", func(t *testing.T) {
+		client := NewPluginNotifyClient(reply(http.StatusInternalServerError), &fakePluginContextProvider{installed: true})
+		require.ErrorContains(t, client.Notify(context.Background(), 1, "acme-ticketing-app", []byte(`{}`)), "status 500: reply")
+	})
+
+	t.Run("should fail if the plugin is not installed", func(t *testing.T) {
+		client := NewPluginNotifyClient(reply(http.StatusOK), &fakePluginContextProvider{})
+		require.ErrorContains(t, client.Notify(context.Background(), 1, "acme-ticketing-app", []byte(`{}`)), "not installed")
+	})
+}
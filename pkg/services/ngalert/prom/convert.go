@@ -0,0 +1,191 @@
+// Package prom converts the rule files of Prometheus into Grafana-managed alert rules.
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrInvalidRules is returned when a rule file of Prometheus is not valid.
+var ErrInvalidRules = errors.New("invalid Prometheus rules")
+
+const (
+	// QueryRefID is the reference ID of the query of the expression of the rule.
+	QueryRefID = "A"
+	// ReduceRefID is the reference ID of the expression that reduces the result of the query to numbers.
+	ReduceRefID = "B"
+	// ConditionRefID is the reference ID of the condition of the rule.
+	ConditionRefID = "C"
+
+	// ImportSource is the value of the annotation models.ImportSourceAnnotation of the converted rules.
+	ImportSource = "prometheus"
+
+	// queryTimeRange is the time range of the instant query. Prometheus looks back at most 5 minutes for the latest
+	// sample of a series, the query is given twice that to include the range selectors of the usual rules.
+	queryTimeRange = 10 * time.Minute
+)
+
+// condition fires an alert for every series of the result of the query, as Prometheus does.
+var condition = fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", ReduceRefID)
+
+// valueVariable matches the variable $value, but not $values.
+var valueVariable = regexp.MustCompile(`\$value\b`)
+
+// Group is a rule group of Prometheus converted into a Grafana-managed rule group.
+type Group struct {
+	apimodels.PostableRuleGroupConfig
+	// Skipped are the names of the recording rules of the group, which are not converted.
+	Skipped []string
+}
+
+// ParseGroups parses the rule groups of a rule file of Prometheus. It returns ErrInvalidRules if the file is not valid,
+// including its expressions and templates.
+func ParseGroups(content []byte) ([]rulefmt.RuleGroup, error) {
+	groups, errs := rulefmt.Parse(content)
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRules, strings.Join(msgs, "; "))
+	}
+	return groups.Groups, nil
+}
+
+// ConvertGroups converts the alerting rules of the groups into Grafana-managed rules that query the Prometheus data
+// source with the UID. The titles of the rules are unique across all the groups, as required in a folder: the
+// duplicates get a numeric suffix.
+func ConvertGroups(groups []rulefmt.RuleGroup, datasourceUID string) ([]Group, error) {
+	result := make([]Group, 0, len(groups))
+	titles := make(map[string]int)
+	for _, group := range groups {
+		converted := Group{
+			PostableRuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     group.Name,
+				Interval: group.Interval,
+			},
+		}
+		for _, rule := range group.Rules {
+			if rule.Record.Value != "" {
+				converted.Skipped = append(converted.Skipped, rule.Record.Value)
+				continue
+			}
+			title := rule.Alert.Value
+			titles[title]++
+			if n := titles[title]; n > 1 {
+				title = fmt.Sprintf("%s (%d)", title, n)
+			}
+			node, err := convertRule(rule, title, datasourceUID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert rule '%s' of group '%s': %w", rule.Alert.Value, group.Name, err)
+			}
+			converted.Rules = append(converted.Rules, node)
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+func convertRule(rule rulefmt.RuleNode, title string, datasourceUID string) (apimodels.PostableExtendedRuleNode, error) {
+	data, err := alertQueries(rule.Expr.Value, datasourceUID)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	forDuration := rule.For
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:         &forDuration,
+			Labels:      rule.Labels,
+			Annotations: convertAnnotations(rule.Annotations),
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:     title,
+			Condition: ConditionRefID,
+			Data:      data,
+			// an expression without result does not fire, and an evaluation that fails does not resolve the alerts
+			NoDataState:  apimodels.OK,
+			ExecErrState: apimodels.ErrorErrState,
+		},
+	}, nil
+}
+
+func alertQueries(promQL string, datasourceUID string) ([]ngmodels.AlertQuery, error) {
+	exprDatasource := map[string]string{"type": expr.DatasourceType, "uid": expr.DatasourceUID}
+	models := []struct {
+		refID         string
+		datasourceUID string
+		timeRange     ngmodels.RelativeTimeRange
+		model         map[string]interface{}
+	}{
+		{
+			refID:         QueryRefID,
+			datasourceUID: datasourceUID,
+			timeRange:     ngmodels.RelativeTimeRange{From: ngmodels.Duration(queryTimeRange)},
+			model: map[string]interface{}{
+				"datasource":    map[string]string{"type": "prometheus", "uid": datasourceUID},
+				"expr":          promQL,
+				"instant":       true,
+				"range":         false,
+				"intervalMs":    1000,
+				"maxDataPoints": 43200,
+			},
+		},
+		{
+			refID:         ReduceRefID,
+			datasourceUID: expr.DatasourceUID,
+			model: map[string]interface{}{
+				"datasource": exprDatasource,
+				"type":       "reduce",
+				"expression": QueryRefID,
+				"reducer":    "last",
+			},
+		},
+		{
+			refID:         ConditionRefID,
+			datasourceUID: expr.DatasourceUID,
+			model: map[string]interface{}{
+				"datasource": exprDatasource,
+				"type":       "math",
+				"expression": condition,
+			},
+		},
+	}
+
+	queries := make([]ngmodels.AlertQuery, 0, len(models))
+	for _, m := range models {
+		m.model["refId"] = m.refID
+		raw, err := json.Marshal(m.model)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, ngmodels.AlertQuery{
+			RefID:             m.refID,
+			DatasourceUID:     m.datasourceUID,
+			RelativeTimeRange: m.timeRange,
+			Model:             raw,
+		})
+	}
+	return queries, nil
+}
+
+// convertAnnotations replaces the variable $value of Prometheus, the value of the alert, with the value of the reduced
+// result of the query. In Grafana, $value is a description of the values of all the queries and expressions. It adds
+// the annotation that marks the rule as imported from Prometheus.
+func convertAnnotations(annotations map[string]string) map[string]string {
+	result := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		result[k] = valueVariable.ReplaceAllString(v, fmt.Sprintf("$$values.%s.Value", ReduceRefID))
+	}
+	result[ngmodels.ImportSourceAnnotation] = ImportSource
+	return result
+}
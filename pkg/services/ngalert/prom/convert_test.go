@@ -0,0 +1,126 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestParseGroups(t *testing.T) {
+	t.Run("should parse the groups of a rule file", func(t *testing.T) {
+		groups, err := ParseGroups([]byte(`
+groups:
+  - name: a
+    interval: 1m
+    rules:
+      - alert: HighLatency
+        expr: histogram_quantile(0.99, rate(latency_bucket[5m])) > 1
+  - name: b
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`))
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		require.Equal(t, "a", groups[0].Name)
+		require.Equal(t, model.Duration(time.Minute), groups[0].Interval)
+		require.Equal(t, "job:up:sum", groups[1].Rules[0].Record.Value)
+	})
+
+	t.Run("should fail if the rule file is not valid", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"invalid yaml":      "groups: [",
+			"unknown field":     "groups:\n  - name: a\n    tenant: b\n    rules: []\n",
+			"invalid promql":    "groups:\n  - name: a\n    rules:\n      - alert: A\n        expr: up ==\n",
+			"invalid template":  "groups:\n  - name: a\n    rules:\n      - alert: A\n        expr: up\n        annotations:\n          summary: '{{ $labels.job'\n",
+			"duplicated groups": "groups:\n  - name: a\n    rules: []\n  - name: a\n    rules: []\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseGroups([]byte(content))
+				require.ErrorIs(t, err, ErrInvalidRules)
+			})
+		}
+	})
+}
+
+func TestConvertGroups(t *testing.T) {
+	groups, err := ParseGroups([]byte(`
+groups:
+  - name: a
+    interval: 2m
+    rules:
+      - record: job:errors:rate5m
+        expr: sum by (job) (rate(errors_total[5m]))
+      - alert: HighErrorRate
+        expr: job:errors:rate5m > 0.5
+        for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: '{{ $labels.job }} has an error rate of {{ $value | humanize }}'
+      - alert: HighErrorRate
+        expr: job:errors:rate5m > 5
+  - name: b
+    rules:
+      - alert: HighErrorRate
+        expr: job:errors:rate5m > 50
+`))
+	require.NoError(t, err)
+
+	converted, err := ConvertGroups(groups, "prometheus-uid")
+	require.NoError(t, err)
+	require.Len(t, converted, 2)
+
+	a := converted[0]
+	require.Equal(t, "a", a.Name)
+	require.Equal(t, model.Duration(2*time.Minute), a.Interval)
+	require.Equal(t, []string{"job:errors:rate5m"}, a.Skipped)
+	require.Len(t, a.Rules, 2)
+
+	rule := a.Rules[0]
+	require.Equal(t, apimodels.GrafanaManagedRule, rule.Type())
+	require.Equal(t, "HighErrorRate", rule.GrafanaManagedAlert.Title)
+	require.Equal(t, ConditionRefID, rule.GrafanaManagedAlert.Condition)
+	require.Equal(t, apimodels.OK, rule.GrafanaManagedAlert.NoDataState)
+	require.Equal(t, apimodels.ErrorErrState, rule.GrafanaManagedAlert.ExecErrState)
+	require.Equal(t, model.Duration(10*time.Minute), *rule.ApiRuleNode.For)
+	require.Equal(t, map[string]string{"severity": "page"}, rule.ApiRuleNode.Labels)
+	require.Equal(t, map[string]string{
+		"summary":                       "{{ $labels.job }} has an error rate of {{ $values.B.Value | humanize }}",
+		ngmodels.ImportSourceAnnotation: ImportSource,
+	}, rule.ApiRuleNode.Annotations)
+
+	data := rule.GrafanaManagedAlert.Data
+	require.Len(t, data, 3)
+	require.Equal(t, QueryRefID, data[0].RefID)
+	require.Equal(t, "prometheus-uid", data[0].DatasourceUID)
+	require.Equal(t, ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)}, data[0].RelativeTimeRange)
+	var query map[string]interface{}
+	require.NoError(t, json.Unmarshal(data[0].Model, &query))
+	require.Equal(t, "job:errors:rate5m > 0.5", query["expr"])
+	require.Equal(t, true, query["instant"])
+	require.Equal(t, QueryRefID, query["refId"])
+
+	for i, refID := range []string{ReduceRefID, ConditionRefID} {
+		q := data[i+1]
+		require.Equal(t, refID, q.RefID)
+		require.Equal(t, expr.DatasourceUID, q.DatasourceUID)
+		isExpr, err := q.IsExpression()
+		require.NoError(t, err)
+		require.True(t, isExpr)
+	}
+
+	// the titles are unique across the groups
+	require.Equal(t, "HighErrorRate (2)", a.Rules[1].GrafanaManagedAlert.Title)
+	require.Equal(t, model.Duration(0), *a.Rules[1].ApiRuleNode.For)
+	require.Equal(t, map[string]string{ngmodels.ImportSourceAnnotation: ImportSource}, a.Rules[1].ApiRuleNode.Annotations)
+	require.Equal(t, "HighErrorRate (3)", converted[1].Rules[0].GrafanaManagedAlert.Title)
+	require.Equal(t, model.Duration(0), converted[1].Interval)
+}
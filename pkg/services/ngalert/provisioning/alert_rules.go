@@ -77,6 +77,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 		return models.AlertRule{}, err
 	}
 	rule.Updated = time.Now()
+	if err := store.ValidateDependencies(ctx, service.ruleStore, rule.OrgID, &store.GroupDelta{New: []*models.AlertRule{&rule}}); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
@@ -205,6 +208,10 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 		return nil
 	}
 
+	if err := store.ValidateDependencies(ctx, service.ruleStore, orgID, delta); err != nil {
+		return err
+	}
+
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
 		if err != nil {
@@ -280,6 +287,10 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	delta := &store.GroupDelta{Update: []store.RuleDelta{{Existing: &storedRule, New: &rule}}}
+	if err := store.ValidateDependencies(ctx, service.ruleStore, rule.OrgID, delta); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
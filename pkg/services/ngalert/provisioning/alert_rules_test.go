@@ -309,6 +309,27 @@ func TestAlertRuleService(t *testing.T) {
 
 		require.ErrorIs(t, err, models.ErrQuotaReached)
 	})
+
+	t.Run("dependencies on rules that do not exist or forming a cycle should be rejected", func(t *testing.T) {
+		ruleService := createAlertRuleService(t)
+		var orgID int64 = 1
+
+		orphan := dummyRule("orphan", orgID)
+		orphan.DependsOn = &models.AlertRuleDependency{UID: "missing"}
+		_, err := ruleService.CreateAlertRule(context.Background(), orphan, models.ProvenanceAPI, 0)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		parent, err := ruleService.CreateAlertRule(context.Background(), dummyRule("parent", orgID), models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+		child := dummyRule("child", orgID)
+		child.DependsOn = &models.AlertRuleDependency{UID: parent.UID}
+		child, err = ruleService.CreateAlertRule(context.Background(), child, models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+
+		parent.DependsOn = &models.AlertRuleDependency{UID: child.UID}
+		_, err = ruleService.UpdateAlertRule(context.Background(), parent, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
 }
 
 func createAlertRuleService(t *testing.T) AlertRuleService {
@@ -0,0 +1,149 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type MaintenanceWindowService struct {
+	store MaintenanceWindowStore
+	prov  ProvisioningStore
+	xact  TransactionManager
+	log   log.Logger
+	// onChange is called after a maintenance window is created, updated or deleted.
+	onChange func()
+}
+
+func NewMaintenanceWindowService(store MaintenanceWindowStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MaintenanceWindowService {
+	return &MaintenanceWindowService{
+		store:    store,
+		prov:     prov,
+		xact:     xact,
+		log:      log,
+		onChange: func() {},
+	}
+}
+
+// OnChange sets the function that is called after a maintenance window is created, updated or deleted.
+func (svc *MaintenanceWindowService) OnChange(fn func()) {
+	svc.onChange = fn
+}
+
+// GetMaintenanceWindows returns all maintenance windows within the specified org.
+func (svc *MaintenanceWindowService) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]definitions.MaintenanceWindow, error) {
+	windows, err := svc.store.ListMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&models.MaintenanceWindow{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]definitions.MaintenanceWindow, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, definitions.NewMaintenanceWindow(*window, provenances[window.UID]))
+	}
+	return result, nil
+}
+
+// GetMaintenanceWindow returns the maintenance window with the given UID. It returns models.ErrMaintenanceWindowNotFound if it does not exist.
+func (svc *MaintenanceWindowService) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (definitions.MaintenanceWindow, error) {
+	window, err := svc.store.GetMaintenanceWindow(ctx, orgID, uid)
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	provenance, err := svc.prov.GetProvenance(ctx, window, orgID)
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	return definitions.NewMaintenanceWindow(*window, provenance), nil
+}
+
+// CreateMaintenanceWindow adds a new maintenance window within the specified org. A UID is generated if it is empty. The created maintenance window is returned.
+func (svc *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, orgID int64, mw definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error) {
+	window := mw.UpstreamModel(orgID)
+	if window.UID == "" {
+		window.UID = util.GenerateShortUID()
+	}
+	if err := window.Validate(); err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	window.Updated = time.Now()
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertMaintenanceWindow(ctx, &window); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &window, orgID, mw.Provenance)
+	})
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	svc.onChange()
+	return definitions.NewMaintenanceWindow(window, mw.Provenance), nil
+}
+
+// UpdateMaintenanceWindow replaces an existing maintenance window within the specified org. The replaced maintenance window is returned.
+// It returns models.ErrMaintenanceWindowNotFound if the maintenance window does not exist.
+func (svc *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, orgID int64, mw definitions.MaintenanceWindow) (definitions.MaintenanceWindow, error) {
+	window := mw.UpstreamModel(orgID)
+	if err := window.Validate(); err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	stored, err := svc.store.GetMaintenanceWindow(ctx, orgID, window.UID)
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	if err := svc.checkProvenance(ctx, stored, orgID, mw.Provenance); err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	window.ID = stored.ID
+	window.Updated = time.Now()
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateMaintenanceWindow(ctx, &window); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &window, orgID, mw.Provenance)
+	})
+	if err != nil {
+		return definitions.MaintenanceWindow{}, err
+	}
+	svc.onChange()
+	return definitions.NewMaintenanceWindow(window, mw.Provenance), nil
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the given UID in the given org. If the maintenance window does not exist, no error is returned.
+func (svc *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	target := &models.MaintenanceWindow{OrgID: orgID, UID: uid}
+	if err := svc.checkProvenance(ctx, target, orgID, provenance); err != nil {
+		return err
+	}
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteMaintenanceWindow(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, target, orgID)
+	})
+	if err != nil {
+		return err
+	}
+	svc.onChange()
+	return nil
+}
+
+// checkProvenance returns an error if the maintenance window is provisioned with a different provenance.
+func (svc *MaintenanceWindowService) checkProvenance(ctx context.Context, window *models.MaintenanceWindow, orgID int64, provenance models.Provenance) error {
+	stored, err := svc.prov.GetProvenance(ctx, window, orgID)
+	if err != nil {
+		return err
+	}
+	if stored != provenance && stored != models.ProvenanceNone {
+		return fmt.Errorf("%w: cannot change provenance from '%s' to '%s'", models.ErrMaintenanceWindowFailedValidation, stored, provenance)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestMaintenanceWindowService(t *testing.T) {
+	sut := createMaintenanceWindowService(t)
+	ctx := context.Background()
+	startsAt := time.Date(2022, 10, 13, 20, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(2 * time.Hour)
+
+	t.Run("should create a window with a generated UID", func(t *testing.T) {
+		created, err := sut.CreateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{
+			Title:      "deploy",
+			Mode:       models.MaintenanceModeSkipEvaluation,
+			StartsAt:   &startsAt,
+			EndsAt:     &endsAt,
+			FolderUID:  "folder",
+			Provenance: models.ProvenanceAPI,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+
+		window, err := sut.GetMaintenanceWindow(ctx, 1, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, "deploy", window.Title)
+		require.Equal(t, models.ProvenanceAPI, window.Provenance)
+		require.True(t, window.StartsAt.Equal(startsAt))
+	})
+
+	t.Run("should reject an invalid window", func(t *testing.T) {
+		_, err := sut.CreateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{Title: "no period", Mode: models.MaintenanceModeMaintenance})
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowFailedValidation)
+	})
+
+	t.Run("should not change the provenance of a provisioned window", func(t *testing.T) {
+		_, err := sut.CreateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{
+			UID:           "from-file",
+			Title:         "nightly",
+			Mode:          models.MaintenanceModeMaintenance,
+			TimeIntervals: []timeinterval.TimeInterval{{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 60}}}},
+			Provenance:    models.ProvenanceFile,
+		})
+		require.NoError(t, err)
+
+		_, err = sut.UpdateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{
+			UID:           "from-file",
+			Title:         "changed",
+			Mode:          models.MaintenanceModeMaintenance,
+			TimeIntervals: []timeinterval.TimeInterval{{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 60}}}},
+			Provenance:    models.ProvenanceAPI,
+		})
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowFailedValidation)
+
+		err = sut.DeleteMaintenanceWindow(ctx, 1, "from-file", models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowFailedValidation)
+
+		err = sut.DeleteMaintenanceWindow(ctx, 1, "from-file", models.ProvenanceFile)
+		require.NoError(t, err)
+	})
+
+	t.Run("should return not found when updating a window that does not exist", func(t *testing.T) {
+		_, err := sut.UpdateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{
+			UID:      "unknown",
+			Title:    "deploy",
+			Mode:     models.MaintenanceModeSkipEvaluation,
+			StartsAt: &startsAt,
+			EndsAt:   &endsAt,
+		})
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("should return the windows of the organization", func(t *testing.T) {
+		windows, err := sut.GetMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, windows, 1)
+		windows, err = sut.GetMaintenanceWindows(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, windows)
+	})
+
+	t.Run("should call the change handler after the windows change", func(t *testing.T) {
+		changes := 0
+		sut.OnChange(func() { changes++ })
+		window := definitions.MaintenanceWindow{
+			UID:      "changes",
+			Title:    "deploy",
+			Mode:     models.MaintenanceModeSkipEvaluation,
+			StartsAt: &startsAt,
+			EndsAt:   &endsAt,
+		}
+
+		_, err := sut.CreateMaintenanceWindow(ctx, 1, window)
+		require.NoError(t, err)
+		require.Equal(t, 1, changes)
+
+		window.Title = "changed"
+		_, err = sut.UpdateMaintenanceWindow(ctx, 1, window)
+		require.NoError(t, err)
+		require.Equal(t, 2, changes)
+
+		_, err = sut.CreateMaintenanceWindow(ctx, 1, definitions.MaintenanceWindow{Title: "no period", Mode: models.MaintenanceModeMaintenance})
+		require.Error(t, err)
+		require.Equal(t, 2, changes)
+
+		err = sut.DeleteMaintenanceWindow(ctx, 1, "changes", models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Equal(t, 3, changes)
+	})
+}
+
+func createMaintenanceWindowService(t *testing.T) *MaintenanceWindowService {
+	t.Helper()
+	sqlStore := db.InitTestDB(t)
+	store := store.DBstore{
+		SQLStore: sqlStore,
+		Logger:   log.NewNopLogger(),
+	}
+	return NewMaintenanceWindowService(store, store, sqlStore, log.NewNopLogger())
+}
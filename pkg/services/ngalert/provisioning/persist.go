@@ -44,6 +44,15 @@ type RuleStore interface {
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) error
 }
 
+// MaintenanceWindowStore represents the ability to persist and query maintenance windows.
+type MaintenanceWindowStore interface {
+	ListMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error)
+	InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+}
+
 // QuotaChecker represents the ability to evaluate whether quotas are met.
 //
 //go:generate mockery --name QuotaChecker --structname MockQuotaChecker --inpackage --filename quota_checker_mock.go --with-expecter
@@ -136,6 +136,26 @@ func FromAlertStateToPostableAlerts(firingStates []*state.State, stateManager *s
 	ts := time.Now()
 
 	for _, alertState := range firingStates {
+		// the alert instances suppressed by a firing parent rule are not sent, they are sent when the suppression ends
+		// if they still fire. The ones that were sent before are resolved once, so that the Alertmanager does not keep
+		// them firing.
+		if alertState.SuppressionReason == ngModels.StateReasonSuppressed {
+			if alertState.LastSentAt.IsZero() {
+				continue
+			}
+			alert := stateToPostableAlert(alertState, appURL)
+			alert.EndsAt = strfmt.DateTime(ts)
+			alerts.PostableAlerts = append(alerts.PostableAlerts, *alert)
+			alertState.LastSentAt = time.Time{}
+			sentAlerts = append(sentAlerts, alertState)
+			continue
+		}
+		// the alert instances in maintenance that were never sent are not sent, they are sent when the maintenance ends
+		// if they still fire. The ones that were sent before are re-sent and resolved as usual, so that the Alertmanager
+		// does not resolve them on its own while they fire.
+		if alertState.SuppressionReason == ngModels.StateReasonMaintenance && alertState.LastSentAt.IsZero() {
+			continue
+		}
 		if !alertState.NeedsSending(stateManager.ResendDelay) {
 			continue
 		}
@@ -10,10 +10,12 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/go-openapi/strfmt"
 	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
@@ -265,6 +267,84 @@ func randomTimeInPast() time.Time {
 	return time.Now().Add(-randomDuration())
 }
 
+func Test_FromAlertStateToPostableAlerts_Suppressed(t *testing.T) {
+	appURL := &url.URL{Scheme: "http", Host: "localhost"}
+	st := state.NewManager(metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(), appURL, nil, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+
+	sent := randomState(eval.Alerting)
+	sent.SuppressionReason = ngModels.StateReasonSuppressed
+	sent.Labels["instance"] = "sent"
+	notSent := randomState(eval.Alerting)
+	notSent.SuppressionReason = ngModels.StateReasonSuppressed
+	notSent.LastSentAt = time.Time{}
+
+	alerts := FromAlertStateToPostableAlerts([]*state.State{sent, notSent}, st, appURL)
+	require.Len(t, alerts.PostableAlerts, 1)
+	require.Equal(t, "sent", alerts.PostableAlerts[0].Labels["instance"])
+	require.False(t, time.Time(alerts.PostableAlerts[0].EndsAt).After(time.Now()))
+	require.True(t, sent.LastSentAt.IsZero())
+
+	// the resolved alert instance is not sent again while it is suppressed
+	alerts = FromAlertStateToPostableAlerts([]*state.State{sent, notSent}, st, appURL)
+	require.Empty(t, alerts.PostableAlerts)
+}
+
+func Test_FromAlertStateToPostableAlerts_Maintenance(t *testing.T) {
+	appURL := &url.URL{Scheme: "http", Host: "localhost"}
+	st := state.NewManager(metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(), appURL, nil, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+
+	t.Run("an alert sent before the maintenance is re-sent and resolved during it", func(t *testing.T) {
+		s := randomState(eval.Alerting)
+		s.LastSentAt = time.Time{}
+		s.LastEvaluationTime = time.Now()
+
+		// fires before the window
+		alerts := FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Len(t, alerts.PostableAlerts, 1)
+		require.False(t, s.LastSentAt.IsZero())
+
+		// keeps firing in the window, and is re-sent on the resend cadence
+		s.SuppressionReason = ngModels.StateReasonMaintenance
+		s.LastEvaluationTime = s.LastSentAt.Add(st.ResendDelay / 2)
+		alerts = FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Empty(t, alerts.PostableAlerts)
+		s.LastEvaluationTime = s.LastSentAt.Add(st.ResendDelay)
+		alerts = FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Len(t, alerts.PostableAlerts, 1)
+		require.True(t, time.Time(alerts.PostableAlerts[0].EndsAt).After(time.Now()))
+
+		// resolves in the window
+		s.State = eval.Normal
+		s.Resolved = true
+		s.EndsAt = time.Now()
+		s.LastEvaluationTime = s.LastSentAt.Add(st.ResendDelay / 2)
+		alerts = FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Len(t, alerts.PostableAlerts, 1)
+		require.Equal(t, strfmt.DateTime(s.EndsAt), alerts.PostableAlerts[0].EndsAt)
+	})
+
+	t.Run("an alert that was never sent is not sent during the maintenance", func(t *testing.T) {
+		s := randomState(eval.Alerting)
+		s.LastSentAt = time.Time{}
+		s.SuppressionReason = ngModels.StateReasonMaintenance
+		alerts := FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Empty(t, alerts.PostableAlerts)
+
+		s.State = eval.Normal
+		s.Resolved = true
+		alerts = FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Empty(t, alerts.PostableAlerts)
+		require.True(t, s.LastSentAt.IsZero())
+
+		// the maintenance ends while it fires
+		s.State = eval.Alerting
+		s.Resolved = false
+		s.SuppressionReason = ""
+		alerts = FromAlertStateToPostableAlerts([]*state.State{s}, st, appURL)
+		require.Len(t, alerts.PostableAlerts, 1)
+	})
+}
+
 func randomState(evalState eval.State) *state.State {
 	return &state.State{
 		State:              evalState,
@@ -0,0 +1,41 @@
+package schedule
+
+import (
+	"hash"
+	"hash/fnv"
+	"strconv"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// jitterOffset returns the offset, between 0 and the frequency of the rule, of the ticks at which the rule is due.
+// The rule is due when the number of the tick modulo its frequency equals the offset. The offset is derived from a hash
+// of the group of the rule, and of its UID if the strategy is setting.EvaluationJitterRule, so that it is the same on
+// every instance of Grafana and after a restart.
+func jitterOffset(rule *ngmodels.AlertRule, strategy string, frequency int64) int64 {
+	if frequency <= 1 {
+		return 0
+	}
+	var h hash.Hash64
+	switch strategy {
+	case setting.EvaluationJitterGroup:
+		h = groupKeyHash(rule.GetGroupKey())
+	case setting.EvaluationJitterRule:
+		h = groupKeyHash(rule.GetGroupKey())
+		// nolint:errcheck,gosec
+		h.Write([]byte("\xff" + rule.UID))
+	default:
+		return 0
+	}
+	return int64(h.Sum64() % uint64(frequency))
+}
+
+// groupKeyHash returns a fnv64a hash of the key. The fields are separated by a byte that is not valid in UTF-8.
+func groupKeyHash(key ngmodels.AlertRuleGroupKey) hash.Hash64 {
+	h := fnv.New64a()
+	// We can ignore err as fnv64a does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(strconv.FormatInt(key.OrgID, 10) + "\xff" + key.NamespaceUID + "\xff" + key.RuleGroup))
+	return h
+}
@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestJitterOffset(t *testing.T) {
+	const frequency = 6
+	rules := models.GenerateAlertRules(200, models.AlertRuleGen(models.WithInterval(frequency*10*time.Second)))
+
+	t.Run("should be zero without jitter", func(t *testing.T) {
+		for _, rule := range rules {
+			require.Zero(t, jitterOffset(rule, setting.EvaluationJitterNone, frequency))
+		}
+	})
+
+	t.Run("should be zero if the rule is evaluated at every tick", func(t *testing.T) {
+		require.Zero(t, jitterOffset(rules[0], setting.EvaluationJitterRule, 1))
+	})
+
+	t.Run("should be stable and spread the rules across their interval", func(t *testing.T) {
+		for _, strategy := range []string{setting.EvaluationJitterGroup, setting.EvaluationJitterRule} {
+			offsets := make(map[int64]int)
+			for _, rule := range rules {
+				offset := jitterOffset(rule, strategy, frequency)
+				require.GreaterOrEqual(t, offset, int64(0))
+				require.Less(t, offset, int64(frequency))
+				require.Equal(t, offset, jitterOffset(models.CopyRule(rule), strategy, frequency))
+				offsets[offset]++
+			}
+			require.Len(t, offsets, frequency, strategy)
+		}
+	})
+
+	t.Run("should be the same for the rules of a group with group jitter", func(t *testing.T) {
+		rule := rules[0]
+		other := models.CopyRule(rule)
+		other.UID = "other"
+		require.Equal(t, jitterOffset(rule, setting.EvaluationJitterGroup, frequency), jitterOffset(other, setting.EvaluationJitterGroup, frequency))
+
+		offsets := make(map[int64]struct{})
+		for i := 0; i < 50; i++ {
+			r := models.CopyRule(rule)
+			r.UID = util.GenerateShortUID()
+			offsets[jitterOffset(r, setting.EvaluationJitterRule, frequency)] = struct{}{}
+		}
+		require.Greater(t, len(offsets), 1)
+	})
+}
@@ -0,0 +1,31 @@
+package schedule
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// record evaluates a recording rule and writes the result of the query or expression
+// of its record with the recording writer.
+func (sch *schedule) record(evalCtx eval.EvaluationContext, e *evaluation) error {
+	ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+	if err != nil {
+		return fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	res, err := ruleEval.EvaluateRaw(evalCtx.Ctx, e.scheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	resp, ok := res.Responses[e.rule.Record.From]
+	if !ok {
+		return fmt.Errorf("no result for query or expression %s", e.rule.Record.From)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("failed to evaluate query or expression %s: %w", e.rule.Record.From, resp.Error)
+	}
+	if err := sch.recordingWriter.Write(evalCtx.Ctx, e.rule.Record.Metric, e.scheduledAt, resp.Frames, e.rule.Labels); err != nil {
+		return fmt.Errorf("failed to write the result of the rule: %w", err)
+	}
+	return nil
+}
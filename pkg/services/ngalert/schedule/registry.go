@@ -12,6 +12,8 @@ import (
 )
 
 var errRuleDeleted = errors.New("rule deleted")
+var errRulePaused = errors.New("rule paused")
+var errRuleNotOwned = errors.New("rule evaluated by another member of the cluster")
 
 type alertRuleInfoRegistry struct {
 	mu            sync.Mutex
@@ -140,6 +142,8 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// maintenanceWindows are the active maintenance windows in the mode Maintenance that apply to the rule.
+	maintenanceWindows []*models.MaintenanceWindow
 }
 
 type alertRulesRegistry struct {
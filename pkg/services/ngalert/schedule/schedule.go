@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sync/atomic"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	prometheusModel "github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -16,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -25,6 +28,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// maintenanceWindowsRefreshInterval is the maximum time the scheduler uses the maintenance windows it fetched before it
+// fetches them again.
+const maintenanceWindowsRefreshInterval = time.Minute
+
 // ScheduleService is an interface for a service that schedules the evaluation
 // of alert rules.
 //
@@ -52,6 +59,11 @@ type RulesStore interface {
 	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error
 }
 
+// MaintenanceWindowStore provides the maintenance windows that are applied to the rules.
+type MaintenanceWindowStore interface {
+	GetMaintenanceWindowsForScheduling(ctx context.Context) ([]*ngmodels.MaintenanceWindow, error)
+}
+
 type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
@@ -89,6 +101,25 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// recordingWriter writes the results of recording rules. It is nil if recording rules are disabled.
+	recordingWriter writer.Writer
+
+	// maintenanceStore provides the maintenance windows. It is nil if maintenance windows are disabled.
+	maintenanceStore MaintenanceWindowStore
+	// maintenanceWindows caches the maintenance windows of all the organizations. They are fetched again when they
+	// change, or every maintenanceWindowsRefreshInterval to get the changes made by the other instances of a cluster.
+	maintenanceWindows        []*ngmodels.MaintenanceWindow
+	maintenanceWindowsFetched time.Time
+	maintenanceWindowsChanged atomic.Bool
+
+	// evaluationJitter is the strategy that spreads the evaluations of the rules across their interval.
+	evaluationJitter string
+	// shardFilter tells whether this instance evaluates a rule group. It is nil if sharding is disabled.
+	shardFilter *shardFilter
+	// shardingStarted is set after the first tick. The states of the rules acquired after it are loaded from the
+	// database, because they were saved by the member of the cluster that evaluated them before.
+	shardingStarted bool
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -104,6 +135,12 @@ type SchedulerCfg struct {
 	RuleStore        RulesStore
 	Metrics          *metrics.Scheduler
 	AlertSender      AlertsSender
+	// RecordingWriter is optional. Recording rules are not evaluated if it is nil.
+	RecordingWriter writer.Writer
+	// MaintenanceStore is optional. Maintenance windows are not applied if it is nil.
+	MaintenanceStore MaintenanceWindowStore
+	// Membership is optional. The rule groups are shared between the members of the cluster if it is set.
+	Membership ClusterMembership
 }
 
 // NewScheduler returns a new schedule.
@@ -123,6 +160,12 @@ func NewScheduler(cfg SchedulerCfg, appURL *url.URL, stateManager *state.Manager
 		minRuleInterval:       cfg.Cfg.MinInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
+		maintenanceStore:      cfg.MaintenanceStore,
+		evaluationJitter:      cfg.Cfg.EvaluationJitter,
+	}
+	if cfg.Membership != nil {
+		sch.shardFilter = newShardFilter(cfg.Membership)
 	}
 
 	return &sch
@@ -194,6 +237,64 @@ func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	}
 }
 
+// MaintenanceWindowsChanged tells the scheduler to fetch the maintenance windows again at the next tick.
+func (sch *schedule) MaintenanceWindowsChanged() {
+	sch.maintenanceWindowsChanged.Store(true)
+}
+
+// activeMaintenanceWindows returns the maintenance windows that are active at the time of the tick.
+func (sch *schedule) activeMaintenanceWindows(ctx context.Context, tick time.Time) []*ngmodels.MaintenanceWindow {
+	if sch.maintenanceStore == nil {
+		return nil
+	}
+	if sch.maintenanceWindowsChanged.Swap(false) || tick.Sub(sch.maintenanceWindowsFetched) >= maintenanceWindowsRefreshInterval {
+		windows, err := sch.maintenanceStore.GetMaintenanceWindowsForScheduling(ctx)
+		if err != nil {
+			// the cached windows are used until they can be fetched again at the next tick
+			sch.log.Error("Failed to get maintenance windows, the previous ones are used", "error", err)
+		} else {
+			sch.maintenanceWindows = windows
+			sch.maintenanceWindowsFetched = tick
+		}
+	}
+	active := make([]*ngmodels.MaintenanceWindow, 0, len(sch.maintenanceWindows))
+	for _, w := range sch.maintenanceWindows {
+		if w.IsActive(tick) {
+			active = append(active, w)
+		}
+	}
+	return active
+}
+
+// maintenanceWindowsForRule returns the first active window that skips the evaluation of the rule, if any.
+// Otherwise, it returns the active windows in the mode Maintenance that apply to the rule. Their matchers are
+// matched against the labels of the alert instances by the state manager.
+func maintenanceWindowsForRule(windows []*ngmodels.MaintenanceWindow, rule *ngmodels.AlertRule) (*ngmodels.MaintenanceWindow, []*ngmodels.MaintenanceWindow) {
+	var result []*ngmodels.MaintenanceWindow
+	var ruleLabels map[string]string
+	for _, w := range windows {
+		if !w.AppliesTo(rule) {
+			continue
+		}
+		if w.Mode == ngmodels.MaintenanceModeMaintenance {
+			result = append(result, w)
+			continue
+		}
+		// the rule is not evaluated, so the matchers of the window are matched against the labels of the rule
+		if ruleLabels == nil {
+			ruleLabels = make(map[string]string, len(rule.Labels)+1)
+			for k, v := range rule.Labels {
+				ruleLabels[k] = v
+			}
+			ruleLabels[prometheusModel.AlertNameLabel] = rule.Title
+		}
+		if w.MatchesLabels(ruleLabels) {
+			return w, nil
+		}
+	}
+	return nil, result
+}
+
 type readyToRunItem struct {
 	ruleInfo *alertRuleInfo
 	evaluation
@@ -206,6 +307,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Error("Failed to update alert rules", "error", err)
 	}
 	alertRules, folderTitles := sch.schedulableAlertRules.all()
+	maintenanceWindows := sch.activeMaintenanceWindows(ctx, tick)
+	var isOwned func(ngmodels.AlertRuleGroupKey) bool
+	if sch.shardFilter != nil {
+		isOwned = sch.shardFilter.ownerFunc()
+	}
 
 	// registeredDefinitions is a map used for finding deleted alert rules
 	// initially it is assigned to all known alert rules from the previous cycle
@@ -222,6 +328,18 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	missingFolder := make(map[string][]string)
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if isOwned != nil && !isOwned(item.GetGroupKey()) {
+			sch.releaseAlertRule(key)
+			// remove the alert rule from the registered alert rules, as it is not deleted
+			delete(registeredDefinitions, key)
+			continue
+		}
+		if item.IsPaused {
+			sch.pauseAlertRule(ctx, key)
+			// remove the alert rule from the registered alert rules, as it is not deleted
+			delete(registeredDefinitions, key)
+			continue
+		}
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -233,8 +351,9 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			loadState := isOwned != nil && sch.shardingStarted
 			dispatcherGroup.Go(func() error {
-				return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
+				return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh, loadState)
 			})
 		}
 
@@ -246,7 +365,13 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
+		if item.IntervalSeconds != 0 && tickNum%itemFrequency == jitterOffset(item, sch.evaluationJitter, itemFrequency) {
+			skipWindow, windows := maintenanceWindowsForRule(maintenanceWindows, item)
+			if skipWindow != nil {
+				sch.log.Debug("Rule evaluation is skipped because of a maintenance window", append(key.LogContext(), "maintenanceWindow", skipWindow.UID)...)
+				delete(registeredDefinitions, key)
+				continue
+			}
 			var folderTitle string
 			if !sch.disableGrafanaFolder {
 				title, ok := folderTitles[item.NamespaceUID]
@@ -257,9 +382,10 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 				}
 			}
 			readyToRun = append(readyToRun, readyToRunItem{ruleInfo: ruleInfo, evaluation: evaluation{
-				scheduledAt: tick,
-				rule:        item,
-				folderTitle: folderTitle,
+				scheduledAt:        tick,
+				rule:               item,
+				folderTitle:        folderTitle,
+				maintenanceWindows: windows,
 			}})
 		}
 
@@ -270,6 +396,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
+	sch.shardingStarted = isOwned != nil
 
 	var step int64 = 0
 	if len(readyToRun) > 0 {
@@ -302,11 +429,48 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	return readyToRun, registeredDefinitions
 }
 
-func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersion) error {
+// pauseAlertRule stops the evaluation routine of a paused rule and clears its state. The state is checked at every tick
+// because the rule could have been paused by another instance, or by file provisioning before Grafana started.
+func (sch *schedule) pauseAlertRule(ctx context.Context, key ngmodels.AlertRuleKey) {
+	if ruleInfo, ok := sch.registry.del(key); ok {
+		sch.log.Info("Stopping the evaluation of the rule because it is paused", key.LogContext()...)
+		ruleInfo.stop(errRulePaused)
+	}
+	if len(sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)) == 0 {
+		return
+	}
+	states := sch.stateManager.ResetStateByRuleUID(ctx, key)
+	expiredAlerts := FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock)
+	if len(expiredAlerts.PostableAlerts) > 0 {
+		sch.alertsSender.Send(key, expiredAlerts)
+	}
+}
+
+// releaseAlertRule stops the evaluation routine of a rule that is evaluated by another member of the cluster. Its states
+// are removed from the cache without notifications but kept in the database, where the other member loads them from,
+// so that it continues the pending periods and resolves the alert instances sent by this instance.
+func (sch *schedule) releaseAlertRule(key ngmodels.AlertRuleKey) {
+	ruleInfo, ok := sch.registry.del(key)
+	if !ok {
+		return
+	}
+	sch.log.Info("Stopping the evaluation of the rule because it is evaluated by another member of the cluster", key.LogContext()...)
+	ruleInfo.stop(errRuleNotOwned)
+	sch.stateManager.ForgetStateByRuleUID(key)
+}
+
+func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersion, loadState bool) error {
 	grafanaCtx = ngmodels.WithRuleKey(grafanaCtx, key)
 	logger := sch.log.FromContext(grafanaCtx)
 	logger.Debug("Alert rule routine started")
 
+	// the rule was acquired from another member of the cluster, load the states it saved before the first evaluation.
+	if loadState {
+		if rule := sch.schedulableAlertRules.get(key); rule != nil {
+			sch.stateManager.LoadStateByRuleUID(grafanaCtx, rule)
+		}
+	}
+
 	orgID := fmt.Sprint(key.OrgID)
 	evalTotal := sch.metrics.EvalTotal.WithLabelValues(orgID)
 	evalDuration := sch.metrics.EvalDuration.WithLabelValues(orgID)
@@ -322,6 +486,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 
 	evaluate := func(ctx context.Context, attempt int64, e *evaluation) {
 		logger := logger.New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
+		if e.rule.IsRecordingRule() && sch.recordingWriter == nil {
+			logger.Debug("Skip evaluation of recording rule because recording rules are disabled")
+			return
+		}
 		start := sch.clock.Now()
 
 		schedulerUser := &user.SignedInUser{
@@ -338,7 +506,23 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				},
 			},
 		}
-		evalCtx := eval.Context(ctx, schedulerUser)
+		if e.rule.IsRecordingRule() {
+			err := sch.record(eval.Context(ctx, schedulerUser), e)
+			dur := sch.clock.Now().Sub(start)
+			evalTotal.Inc()
+			evalDuration.Observe(dur.Seconds())
+			if err != nil {
+				evalTotalFailures.Inc()
+				logger.Error("Failed to evaluate recording rule", "error", err, "duration", dur)
+				return
+			}
+			logger.Debug("Recording rule evaluated", "duration", dur)
+			return
+		}
+
+		evalCtx := eval.ContextWithActiveResults(ctx, schedulerUser, eval.ActiveResultsReaderFunc(func() []data.Labels {
+			return sch.stateManager.GetActiveResultLabels(e.rule)
+		}))
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
@@ -367,7 +551,11 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			logger.Debug("Skip updating the state because the context has been cancelled")
 			return
 		}
-		processedStates := sch.stateManager.ProcessEvalResults(ctx, e.scheduledAt, e.rule, results, sch.getRuleExtraLabels(e))
+		suppression := state.Suppression{
+			MaintenanceWindows: e.maintenanceWindows,
+			ParentFiring:       sch.isParentFiring(ctx, e.rule, logger),
+		}
+		processedStates := sch.stateManager.ProcessEvalResultsWithSuppression(ctx, e.scheduledAt, e.rule, results, sch.getRuleExtraLabels(e), suppression)
 		alerts := FromAlertStateToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 		if len(alerts.PostableAlerts) > 0 {
 			sch.alertsSender.Send(key, alerts)
@@ -467,6 +655,43 @@ func (sch *schedule) stopApplied(alertDefKey ngmodels.AlertRuleKey) {
 	sch.stopAppliedFunc(alertDefKey)
 }
 
+// isParentFiring returns true if the rule depends on a rule that has a firing alert instance that matches the
+// matchers of the dependency. The state of the parent rule is the state of its latest evaluation. If the parent rule
+// is evaluated by another member of the cluster, its state is read from the database, where that member saves it.
+func (sch *schedule) isParentFiring(ctx context.Context, rule *ngmodels.AlertRule, logger log.Logger) bool {
+	if rule.DependsOn == nil {
+		return false
+	}
+	matchers, err := rule.DependsOn.ParseMatchers()
+	if err != nil {
+		logger.Error("Failed to parse the matchers of the rule dependency, the rule is not suppressed", "parent", rule.DependsOn.UID, "error", err)
+		return false
+	}
+	var parentStates []*state.State
+	if sch.shardFilter != nil && !sch.registry.exists(ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: rule.DependsOn.UID}) {
+		parentStates, err = sch.stateManager.GetPersistedStatesForRuleUID(ctx, rule.OrgID, rule.DependsOn.UID)
+		if err != nil {
+			logger.Error("Failed to read the state of the rule that the rule depends on, the rule is not suppressed", "parent", rule.DependsOn.UID, "error", err)
+			return false
+		}
+	} else {
+		parentStates = sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.DependsOn.UID)
+	}
+	for _, s := range parentStates {
+		if s.State != eval.Alerting {
+			continue
+		}
+		lbs := make(prometheusModel.LabelSet, len(s.Labels))
+		for k, v := range s.Labels {
+			lbs[prometheusModel.LabelName(k)] = prometheusModel.LabelValue(v)
+		}
+		if matchers.Matches(lbs) {
+			return true
+		}
+	}
+	return false
+}
+
 func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
 	extraLabels := make(map[string]string, 4)
 
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	prometheusModel "github.com/prometheus/common/model"
@@ -21,11 +23,14 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -170,9 +175,10 @@ func TestProcessTicks(t *testing.T) {
 		assertEvalRun(t, evalAppliedCh, tick, alertRule2.GetKey())
 	})
 
+	// create alert rule with one base interval
+	alertRule3 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(cfg.BaseInterval), models.WithTitle("rule-3"))()
+
 	t.Run("on 7th tick a new alert rule should be evaluated", func(t *testing.T) {
-		// create alert rule with one base interval
-		alertRule3 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(cfg.BaseInterval), models.WithTitle("rule-3"))()
 		ruleStore.PutRule(ctx, alertRule3)
 		tick = tick.Add(cfg.BaseInterval)
 
@@ -185,6 +191,333 @@ func TestProcessTicks(t *testing.T) {
 
 		assertEvalRun(t, evalAppliedCh, tick, alertRule3.GetKey())
 	})
+
+	pausedRule3 := models.CopyRule(alertRule3)
+	pausedRule3.IsPaused = true
+	pausedRule3.Version++
+
+	t.Run("on 8th tick paused rule should not be evaluated but stopped, and its state cleared", func(t *testing.T) {
+		st.Put([]*state.State{{
+			OrgID:        alertRule3.OrgID,
+			AlertRuleUID: alertRule3.UID,
+			CacheID:      "test",
+			State:        eval.Alerting,
+			Labels:       data.Labels{"test": "test"},
+		}})
+		ruleStore.PutRule(ctx, pausedRule3)
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Empty(t, scheduled)
+		require.Emptyf(t, stopped, "None rules are expected to be stopped as deleted")
+
+		assertStopRun(t, stopAppliedCh, alertRule3.GetKey())
+		require.Empty(t, st.GetStatesForRuleUID(alertRule3.OrgID, alertRule3.UID))
+		notifier.AssertCalled(t, "Send", alertRule3.GetKey(), mock.Anything)
+	})
+
+	t.Run("on 9th tick paused rule should not be evaluated", func(t *testing.T) {
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, alertRule2, scheduled[0].rule)
+		require.Emptyf(t, stopped, "None rules are expected to be stopped")
+
+		assertEvalRun(t, evalAppliedCh, tick, alertRule2.GetKey())
+	})
+
+	t.Run("on 10th tick unpaused rule should be evaluated", func(t *testing.T) {
+		unpausedRule3 := models.CopyRule(pausedRule3)
+		unpausedRule3.IsPaused = false
+		unpausedRule3.Version++
+		ruleStore.PutRule(ctx, unpausedRule3)
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, unpausedRule3, scheduled[0].rule)
+		require.Emptyf(t, stopped, "None rules are expected to be stopped")
+
+		assertEvalRun(t, evalAppliedCh, tick, alertRule3.GetKey())
+	})
+}
+
+func TestProcessTicks_MaintenanceWindows(t *testing.T) {
+	testMetrics := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	maintenanceStore := &fakeMaintenanceWindowStore{}
+	cfg := setting.UnifiedAlertingSettings{
+		BaseInterval:            1 * time.Second,
+		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
+	}
+	mockedClock := clock.NewMock()
+	schedCfg := SchedulerCfg{
+		Cfg:              cfg,
+		C:                mockedClock,
+		RuleStore:        ruleStore,
+		Metrics:          testMetrics.GetSchedulerMetrics(),
+		AlertSender:      &AlertsSenderMock{},
+		MaintenanceStore: maintenanceStore,
+	}
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, nil, &state.NoopImageService{}, mockedClock, &state.FakeHistorian{})
+	sched := NewScheduler(schedCfg, &url.URL{Scheme: "http", Host: "localhost"}, st)
+
+	rule1 := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(cfg.BaseInterval), models.WithNamespace(&folder.Folder{UID: "other", Title: "other"}))()
+	rule1.Labels = map[string]string{"team": "sre"}
+	rule2 := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(cfg.BaseInterval), models.WithNamespace(&folder.Folder{UID: "folder", Title: "folder"}))()
+	rule2.Labels = map[string]string{"team": "ops"}
+	ruleStore.PutRule(ctx, rule1, rule2)
+
+	tick := time.Unix(0, 0).UTC()
+	teamMatcher, err := labels.NewMatcher(labels.MatchEqual, "team", "sre")
+	require.NoError(t, err)
+	skip := &models.MaintenanceWindow{OrgID: 1, UID: "skip", Title: "skip", Mode: models.MaintenanceModeSkipEvaluation, StartsAt: tick, EndsAt: tick.Add(2 * time.Second), Matchers: labels.Matchers{teamMatcher}}
+	maintenance := &models.MaintenanceWindow{OrgID: 1, UID: "maintenance", Title: "maintenance", Mode: models.MaintenanceModeMaintenance, StartsAt: tick, EndsAt: tick.Add(2 * time.Second), FolderUID: "folder"}
+	otherOrg := &models.MaintenanceWindow{OrgID: 2, UID: "other", Title: "other", Mode: models.MaintenanceModeSkipEvaluation, StartsAt: tick, EndsAt: tick.Add(2 * time.Second)}
+	maintenanceStore.windows = []*models.MaintenanceWindow{skip, maintenance, otherOrg}
+
+	t.Run("rules in the scope of an active window should be skipped or evaluated in maintenance", func(t *testing.T) {
+		tick = tick.Add(cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, rule2, scheduled[0].rule)
+		require.Equal(t, []*models.MaintenanceWindow{maintenance}, scheduled[0].maintenanceWindows)
+		require.Empty(t, stopped)
+	})
+
+	t.Run("rules should be evaluated when the windows are not active", func(t *testing.T) {
+		tick = tick.Add(2 * cfg.BaseInterval)
+
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 2)
+		for _, item := range scheduled {
+			require.Empty(t, item.maintenanceWindows)
+		}
+		require.Empty(t, stopped)
+	})
+
+	t.Run("windows should be fetched again only when they change or after the refresh interval", func(t *testing.T) {
+		require.Equal(t, 1, maintenanceStore.fetches)
+
+		window := &models.MaintenanceWindow{OrgID: 1, UID: "new", Title: "new", Mode: models.MaintenanceModeSkipEvaluation, StartsAt: tick, EndsAt: tick.Add(time.Hour)}
+		maintenanceStore.windows = []*models.MaintenanceWindow{window}
+		tick = tick.Add(cfg.BaseInterval)
+		scheduled, _ := sched.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 2)
+		require.Equal(t, 1, maintenanceStore.fetches)
+
+		sched.MaintenanceWindowsChanged()
+		tick = tick.Add(cfg.BaseInterval)
+		scheduled, _ = sched.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, scheduled)
+		require.Equal(t, 2, maintenanceStore.fetches)
+
+		maintenanceStore.windows = nil
+		tick = tick.Add(cfg.BaseInterval)
+		scheduled, _ = sched.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, scheduled)
+		require.Equal(t, 2, maintenanceStore.fetches)
+
+		tick = tick.Add(maintenanceWindowsRefreshInterval)
+		scheduled, _ = sched.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 2)
+		require.Equal(t, 3, maintenanceStore.fetches)
+	})
+}
+
+func TestProcessTicks_JitterAndSharding(t *testing.T) {
+	testMetrics := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	membership := &fakeClusterMembership{}
+	cfg := setting.UnifiedAlertingSettings{
+		BaseInterval:            1 * time.Second,
+		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
+		EvaluationJitter:        setting.EvaluationJitterRule,
+	}
+	mockedClock := clock.NewMock()
+	schedCfg := SchedulerCfg{
+		Cfg:         cfg,
+		C:           mockedClock,
+		RuleStore:   ruleStore,
+		Metrics:     testMetrics.GetSchedulerMetrics(),
+		AlertSender: &AlertsSenderMock{},
+		Membership:  membership,
+	}
+	instanceStore := &fakeInstanceStore{}
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, instanceStore, &state.NoopImageService{}, mockedClock, &state.FakeHistorian{})
+	sched := NewScheduler(schedCfg, &url.URL{Scheme: "http", Host: "localhost"}, st)
+
+	const frequency = 10
+	rules := models.GenerateAlertRules(20, models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(frequency*cfg.BaseInterval)))
+	ruleStore.PutRule(ctx, rules...)
+	for _, rule := range rules {
+		instanceStore.instances = append(instanceStore.instances, &models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: rule.OrgID, RuleUID: rule.UID, LabelsHash: "hash"},
+			Labels:           models.InstanceLabels{"instance": "saved"},
+			CurrentState:     models.InstanceStateFiring,
+		})
+	}
+
+	t.Run("rules should be evaluated once per interval at the tick of their offset", func(t *testing.T) {
+		evaluated := make(map[string]int64)
+		for tickNum := int64(0); tickNum < frequency; tickNum++ {
+			scheduled, stopped := sched.processTick(ctx, dispatcherGroup, time.Unix(tickNum, 0))
+			require.Empty(t, stopped)
+			for _, item := range scheduled {
+				require.NotContains(t, evaluated, item.rule.UID)
+				evaluated[item.rule.UID] = tickNum
+			}
+		}
+		require.Len(t, evaluated, len(rules))
+		ticks := make(map[int64]struct{})
+		for _, rule := range rules {
+			require.Equal(t, jitterOffset(rule, setting.EvaluationJitterRule, frequency), evaluated[rule.UID])
+			ticks[evaluated[rule.UID]] = struct{}{}
+		}
+		require.Greater(t, len(ticks), 1)
+	})
+
+	t.Run("rules of the groups owned by other members should not be evaluated", func(t *testing.T) {
+		membership.members = []string{"a", "b"}
+		membership.self = "a"
+		owned := newShardFilter(membership).ownerFunc()
+		states := make([]*state.State, 0, len(rules))
+		for _, rule := range rules {
+			states = append(states, &state.State{OrgID: rule.OrgID, AlertRuleUID: rule.UID, CacheID: "a", State: eval.Alerting})
+		}
+		st.Put(states)
+
+		expected := 0
+		scheduledCount := 0
+		for tickNum := int64(frequency); tickNum < 2*frequency; tickNum++ {
+			scheduled, stopped := sched.processTick(ctx, dispatcherGroup, time.Unix(tickNum, 0))
+			require.Empty(t, stopped)
+			for _, item := range scheduled {
+				require.True(t, owned(item.rule.GetGroupKey()))
+			}
+			scheduledCount += len(scheduled)
+		}
+		for _, rule := range rules {
+			_, err := sched.registry.get(rule.GetKey())
+			if owned(rule.GetGroupKey()) {
+				expected++
+				require.NoError(t, err)
+				require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+			} else {
+				require.Error(t, err)
+				require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			}
+		}
+		require.Equal(t, expected, scheduledCount)
+		require.Greater(t, expected, 0)
+		require.Less(t, expected, len(rules))
+	})
+
+	t.Run("states of the rules acquired from other members should be loaded from the database", func(t *testing.T) {
+		membership.self = "b"
+		owned := newShardFilter(membership).ownerFunc()
+
+		// the states are loaded by the routines of the rules, the tick does not wait for the database.
+		instanceStore.block = make(chan struct{})
+		sched.processTick(ctx, dispatcherGroup, time.Unix(2*frequency, 0))
+		for _, rule := range rules {
+			require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		}
+		close(instanceStore.block)
+
+		for _, rule := range rules {
+			if !owned(rule.GetGroupKey()) {
+				require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+				continue
+			}
+			require.Eventually(t, func() bool {
+				return len(st.GetStatesForRuleUID(rule.OrgID, rule.UID)) == 1
+			}, time.Second, 10*time.Millisecond)
+			states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			require.Equal(t, eval.Alerting, states[0].State)
+			require.Equal(t, "saved", states[0].Labels["instance"])
+		}
+	})
+}
+
+// fakeInstanceStore returns the saved alert instances of a rule. If block is set, the queries wait until it is closed.
+type fakeInstanceStore struct {
+	state.FakeInstanceStore
+	instances []*models.AlertInstance
+	block     chan struct{}
+}
+
+func (f *fakeInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) error {
+	if f.block != nil {
+		<-f.block
+	}
+	for _, instance := range f.instances {
+		if instance.RuleOrgID == q.RuleOrgID && instance.RuleUID == q.RuleUID {
+			q.Result = append(q.Result, instance)
+		}
+	}
+	return nil
+}
+
+func TestSchedule_isParentFiring(t *testing.T) {
+	sch := setupScheduler(t, newFakeRulesStore(), nil, prometheus.NewPedanticRegistry(), nil, nil)
+	parent := models.AlertRuleGen(models.WithOrgID(1))()
+	sch.stateManager.Put([]*state.State{
+		{OrgID: 1, AlertRuleUID: parent.UID, CacheID: "a", State: eval.Alerting, Labels: data.Labels{"cluster": "eu"}},
+		{OrgID: 1, AlertRuleUID: parent.UID, CacheID: "b", State: eval.Pending, Labels: data.Labels{"cluster": "us"}},
+	})
+	logger := log.NewNopLogger()
+
+	testCases := []struct {
+		desc      string
+		dependsOn *models.AlertRuleDependency
+		expected  bool
+	}{
+		{desc: "no dependency", dependsOn: nil, expected: false},
+		{desc: "any firing alert of the parent", dependsOn: &models.AlertRuleDependency{UID: parent.UID}, expected: true},
+		{desc: "a firing alert that matches", dependsOn: &models.AlertRuleDependency{UID: parent.UID, Matchers: []string{`cluster="eu"`}}, expected: true},
+		{desc: "a pending alert that matches", dependsOn: &models.AlertRuleDependency{UID: parent.UID, Matchers: []string{`cluster="us"`}}, expected: false},
+		{desc: "an unknown parent", dependsOn: &models.AlertRuleDependency{UID: "unknown"}, expected: false},
+		{desc: "invalid matchers", dependsOn: &models.AlertRuleDependency{UID: parent.UID, Matchers: []string{`cluster=~"(`}}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rule := models.AlertRuleGen(models.WithOrgID(1))()
+			rule.DependsOn = tc.dependsOn
+			require.Equal(t, tc.expected, sch.isParentFiring(context.Background(), rule, logger))
+		})
+	}
+
+	t.Run("should read the state of a parent evaluated by another member of the cluster from the database", func(t *testing.T) {
+		instanceStore := &fakeInstanceStore{instances: []*models.AlertInstance{{
+			AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: 1, RuleUID: parent.UID, LabelsHash: "hash"},
+			Labels:           models.InstanceLabels{"cluster": "ap"},
+			CurrentState:     models.InstanceStateFiring,
+		}}}
+		m := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+		sharded := setupScheduler(t, newFakeRulesStore(), nil, prometheus.NewPedanticRegistry(), nil, nil)
+		sharded.stateManager = state.NewManager(m.GetStateMetrics(), nil, instanceStore, &state.NoopImageService{}, clock.NewMock(), &state.FakeHistorian{})
+		sharded.shardFilter = newShardFilter(&fakeClusterMembership{members: []string{"a", "b"}, self: "a"})
+
+		rule := models.AlertRuleGen(models.WithOrgID(1))()
+		rule.DependsOn = &models.AlertRuleDependency{UID: parent.UID, Matchers: []string{`cluster="ap"`}}
+		require.True(t, sharded.isParentFiring(context.Background(), rule, logger))
+		rule.DependsOn.Matchers = []string{`cluster="eu"`}
+		require.False(t, sharded.isParentFiring(context.Background(), rule, logger))
+	})
 }
 
 func TestSchedule_ruleRoutine(t *testing.T) {
@@ -220,7 +553,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 			go func() {
 				ctx, cancel := context.WithCancel(context.Background())
 				t.Cleanup(cancel)
-				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion), false)
 			}()
 
 			expectedTime := time.UnixMicro(rand.Int63())
@@ -332,7 +665,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			ctx, cancel := context.WithCancel(context.Background())
 			go func() {
-				err := sch.ruleRoutine(ctx, models.AlertRuleKey{}, make(chan *evaluation), make(chan ruleVersion))
+				err := sch.ruleRoutine(ctx, models.AlertRuleKey{}, make(chan *evaluation), make(chan ruleVersion), false)
 				stoppedChan <- err
 			}()
 
@@ -351,7 +684,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			ctx, cancel := util.WithCancelCause(context.Background())
 			go func() {
-				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersion))
+				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersion), false)
 				stoppedChan <- err
 			}()
 
@@ -379,7 +712,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		go func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, updateChan)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, updateChan, false)
 		}()
 
 		// init evaluation loop so it got the rule version
@@ -459,7 +792,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		go func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion), false)
 		}()
 
 		evalChan <- &evaluation{
@@ -529,7 +862,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 			go func() {
 				ctx, cancel := context.WithCancel(context.Background())
 				t.Cleanup(cancel)
-				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion), false)
 			}()
 
 			evalChan <- &evaluation{
@@ -562,7 +895,7 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		go func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion), false)
 		}()
 
 		evalChan <- &evaluation{
@@ -578,6 +911,99 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 	})
 }
 
+func TestSchedule_ruleRoutine_RecordingRule(t *testing.T) {
+	setup := func(w writer.Writer) (*schedule, *fakeRulesStore, *AlertsSenderMock, chan time.Time) {
+		ruleStore := newFakeRulesStore()
+		sender := &AlertsSenderMock{}
+		evalAppliedChan := make(chan time.Time)
+		sch := setupScheduler(t, ruleStore, &state.FakeInstanceStore{}, prometheus.NewPedanticRegistry(), sender, nil)
+		sch.recordingWriter = w
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		return sch, ruleStore, sender, evalAppliedChan
+	}
+
+	run := func(sch *schedule, rule *models.AlertRule, scheduledAt time.Time) {
+		evalChan := make(chan *evaluation)
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion), false)
+		}()
+		evalChan <- &evaluation{
+			scheduledAt: scheduledAt,
+			rule:        rule,
+		}
+	}
+
+	withRecord := func(rule *models.AlertRule) {
+		rule.Labels = map[string]string{"team": "a"}
+		rule.Record = &models.Record{
+			Metric: "test_metric",
+			From:   "A",
+		}
+	}
+
+	t.Run("should write the result of the rule and not update the state", func(t *testing.T) {
+		w := &fakeWriter{}
+		sch, ruleStore, sender, evalAppliedChan := setup(w)
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), withRecord)()
+		ruleStore.PutRule(context.Background(), rule)
+
+		scheduledAt := time.UnixMicro(rand.Int63())
+		run(sch, rule, scheduledAt)
+		waitForTimeChannel(t, evalAppliedChan)
+
+		calls := w.getCalls()
+		require.Len(t, calls, 1)
+		require.Equal(t, "test_metric", calls[0].metric)
+		require.Equal(t, scheduledAt, calls[0].t)
+		require.Equal(t, rule.Labels, calls[0].extraLabels)
+		require.NotEmpty(t, calls[0].frames)
+
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip the rule if recording rules are disabled", func(t *testing.T) {
+		sch, ruleStore, sender, evalAppliedChan := setup(nil)
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), withRecord)()
+		ruleStore.PutRule(context.Background(), rule)
+
+		run(sch, rule, time.UnixMicro(rand.Int63()))
+		waitForTimeChannel(t, evalAppliedChan)
+
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+}
+
+type fakeWriterCall struct {
+	metric      string
+	t           time.Time
+	frames      data.Frames
+	extraLabels map[string]string
+}
+
+type fakeWriter struct {
+	mtx   sync.Mutex
+	calls []fakeWriterCall
+}
+
+func (w *fakeWriter) Write(_ context.Context, metric string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.calls = append(w.calls, fakeWriterCall{metric: metric, t: t, frames: frames, extraLabels: extraLabels})
+	return nil
+}
+
+func (w *fakeWriter) getCalls() []fakeWriterCall {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]fakeWriterCall(nil), w.calls...)
+}
+
 func TestSchedule_UpdateAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should call Update", func(t *testing.T) {
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringVirtualNodes is the number of points of every member on the hash ring. The more points, the more even the
+// distribution of the rule groups between the members.
+const ringVirtualNodes = 128
+
+// ClusterMembership provides the members of the HA cluster, which share the evaluation of the rule groups.
+type ClusterMembership interface {
+	// ClusterMembers returns the names of all the members of the cluster, including this instance.
+	// It returns an empty slice if the cluster is not configured.
+	ClusterMembers() []string
+	// ClusterMemberName returns the name of this instance in the cluster.
+	ClusterMemberName() string
+}
+
+// hashRing is a consistent hash ring that assigns the rule groups to the members of the cluster. When a member joins
+// or leaves the cluster, only the rule groups on its segments of the ring move to other members.
+type hashRing struct {
+	tokens  []uint64
+	members []string
+}
+
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		tokens:  make([]uint64, 0, len(members)*ringVirtualNodes),
+		members: make([]string, 0, len(members)*ringVirtualNodes),
+	}
+	type point struct {
+		token  uint64
+		member string
+	}
+	points := make([]point, 0, len(members)*ringVirtualNodes)
+	for _, member := range members {
+		for i := 0; i < ringVirtualNodes; i++ {
+			h := fnv.New64a()
+			// We can ignore err as fnv64a does not return an error
+			// nolint:errcheck,gosec
+			h.Write([]byte(member + "\xff" + strconv.Itoa(i)))
+			points = append(points, point{token: mix64(h.Sum64()), member: member})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].token == points[j].token {
+			return points[i].member < points[j].member
+		}
+		return points[i].token < points[j].token
+	})
+	for _, p := range points {
+		r.tokens = append(r.tokens, p.token)
+		r.members = append(r.members, p.member)
+	}
+	return r
+}
+
+// owner returns the member that owns the rule group, which is the member of the first point of the ring at or after
+// the hash of the group key.
+func (r *hashRing) owner(key ngmodels.AlertRuleGroupKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	token := mix64(groupKeyHash(key).Sum64())
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= token })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.members[i]
+}
+
+// mix64 is the finalizer of MurmurHash3. The high bits of fnv hashes of short strings that differ only by their last
+// bytes are close to each other, which makes them cluster on the ring. The finalizer spreads them across the ring.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// shardFilter tells whether this instance evaluates a rule group. The hash ring is rebuilt only when the members of
+// the cluster change.
+type shardFilter struct {
+	membership ClusterMembership
+
+	mtx     sync.Mutex
+	ringKey string
+	ring    *hashRing
+}
+
+func newShardFilter(membership ClusterMembership) *shardFilter {
+	return &shardFilter{membership: membership}
+}
+
+// ownerFunc returns a function that tells whether this instance owns a rule group. Every rule group is owned if the
+// cluster has no members, for example when the instance has not joined it yet.
+func (f *shardFilter) ownerFunc() func(key ngmodels.AlertRuleGroupKey) bool {
+	members := f.membership.ClusterMembers()
+	if len(members) == 0 {
+		return func(ngmodels.AlertRuleGroupKey) bool { return true }
+	}
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+	ringKey := strings.Join(sorted, "\xff")
+
+	f.mtx.Lock()
+	if f.ring == nil || f.ringKey != ringKey {
+		f.ring = newHashRing(sorted)
+		f.ringKey = ringKey
+	}
+	ring := f.ring
+	f.mtx.Unlock()
+
+	self := f.membership.ClusterMemberName()
+	return func(key ngmodels.AlertRuleGroupKey) bool {
+		return ring.owner(key) == self
+	}
+}
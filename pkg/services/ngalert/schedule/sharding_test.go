@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]models.AlertRuleGroupKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleGroupKey{OrgID: int64(i%3 + 1), NamespaceUID: fmt.Sprintf("folder-%d", i%17), RuleGroup: fmt.Sprintf("group-%d", i)})
+	}
+	members := []string{"a", "b", "c"}
+	ring := newHashRing(members)
+
+	t.Run("should share the groups between all members", func(t *testing.T) {
+		owned := make(map[string]int)
+		for _, key := range keys {
+			owned[ring.owner(key)]++
+		}
+		require.Len(t, owned, len(members))
+		for _, member := range members {
+			// the expected share is 1000 groups, allow for the variance of the hash
+			require.InDelta(t, len(keys)/len(members), owned[member], 300, member)
+		}
+	})
+
+	t.Run("should move only the groups of a member that leaves", func(t *testing.T) {
+		smaller := newHashRing([]string{"a", "c"})
+		for _, key := range keys {
+			before := ring.owner(key)
+			after := smaller.owner(key)
+			if before != "b" {
+				require.Equal(t, before, after)
+			} else {
+				require.NotEqual(t, "b", after)
+			}
+		}
+	})
+
+	t.Run("should have no owner without members", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestShardFilter(t *testing.T) {
+	key := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+
+	t.Run("should own every group without cluster members", func(t *testing.T) {
+		filter := newShardFilter(&fakeClusterMembership{})
+		require.True(t, filter.ownerFunc()(key))
+	})
+
+	t.Run("should assign every group to exactly one member", func(t *testing.T) {
+		members := []string{"c", "a", "b"}
+		owners := 0
+		for _, self := range members {
+			filter := newShardFilter(&fakeClusterMembership{members: members, self: self})
+			if filter.ownerFunc()(key) {
+				owners++
+			}
+		}
+		require.Equal(t, 1, owners)
+	})
+
+	t.Run("should rebuild the ring only when the members change", func(t *testing.T) {
+		membership := &fakeClusterMembership{members: []string{"a", "b"}, self: "a"}
+		filter := newShardFilter(membership)
+		filter.ownerFunc()
+		ring := filter.ring
+
+		membership.members = []string{"b", "a"}
+		filter.ownerFunc()
+		require.Same(t, ring, filter.ring)
+
+		membership.members = []string{"a", "b", "c"}
+		filter.ownerFunc()
+		require.NotSame(t, ring, filter.ring)
+	})
+}
@@ -80,3 +80,26 @@ func (f *fakeRulesStore) DeleteRule(rules ...*models.AlertRule) {
 func (f *fakeRulesStore) getNamespaceTitle(uid string) string {
 	return "TEST-FOLDER-" + uid
 }
+
+type fakeMaintenanceWindowStore struct {
+	windows []*models.MaintenanceWindow
+	fetches int
+}
+
+func (f *fakeMaintenanceWindowStore) GetMaintenanceWindowsForScheduling(_ context.Context) ([]*models.MaintenanceWindow, error) {
+	f.fetches++
+	return f.windows, nil
+}
+
+type fakeClusterMembership struct {
+	members []string
+	self    string
+}
+
+func (f *fakeClusterMembership) ClusterMembers() []string {
+	return f.members
+}
+
+func (f *fakeClusterMembership) ClusterMemberName() string {
+	return f.self
+}
@@ -113,6 +113,9 @@ func (rs *ruleStates) getOrCreate(ctx context.Context, log log.Logger, alertRule
 		}
 		state.Annotations = annotations
 		state.Values = values
+		if state.ResultLabels == nil {
+			state.ResultLabels = result.Instance.Copy()
+		}
 		rs.states[id] = state
 		return state
 	}
@@ -124,6 +127,7 @@ func (rs *ruleStates) getOrCreate(ctx context.Context, log log.Logger, alertRule
 		OrgID:              alertRule.OrgID,
 		CacheID:            id,
 		Labels:             lbs,
+		ResultLabels:       result.Instance.Copy(),
 		Annotations:        annotations,
 		EvaluationDuration: result.EvaluationDuration,
 		Values:             values,
@@ -42,6 +42,11 @@ func (h *AnnotationStateHistorian) RecordStates(ctx context.Context, rule *ngmod
 	go h.recordAnnotationsSync(ctx, panel, annotations, logger)
 }
 
+// QueryStates is not supported because the annotations do not contain the labels of the alert instances.
+func (h *AnnotationStateHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	return nil, ngmodels.ErrStateHistoryQueryNotSupported
+}
+
 func (h *AnnotationStateHistorian) buildAnnotations(rule *ngmodels.AlertRule, states []state.StateTransition, logger log.Logger) []annotations.Item {
 	items := make([]annotations.Item, 0, len(states))
 	for _, state := range states {
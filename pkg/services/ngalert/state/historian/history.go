@@ -0,0 +1,103 @@
+package historian
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// historyEntry is a state transition of an alert instance, as recorded by the historians that can be queried.
+type historyEntry struct {
+	Time     time.Time
+	RuleUID  string
+	Labels   data.Labels
+	Previous string
+	Current  string
+	// State is the state of Current without the reason. It is used to filter the transitions.
+	State string
+	// Values are nil if they are NaN or infinite, because they cannot be marshalled to JSON.
+	Values map[string]*float64
+	Error  string
+}
+
+func newHistoryEntries(rule *ngmodels.AlertRule, states []state.StateTransition) []historyEntry {
+	entries := make([]historyEntry, 0, len(states))
+	for _, s := range states {
+		entry := historyEntry{
+			Time:     s.LastEvaluationTime,
+			RuleUID:  rule.UID,
+			Labels:   removePrivateLabels(s.Labels),
+			Previous: s.PreviousFormatted(),
+			Current:  s.Formatted(),
+			State:    s.State.State.String(),
+			Values:   toNullableValues(s.Values),
+		}
+		if s.Error != nil {
+			entry.Error = s.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func toNullableValues(values map[string]float64) map[string]*float64 {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]*float64, len(values))
+	for k, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			result[k] = nil
+			continue
+		}
+		v := v
+		result[k] = &v
+	}
+	return result
+}
+
+// matchLabels returns true if the labels contain all the matchers with the same values.
+func matchLabels(labels data.Labels, matchers map[string]string) bool {
+	for name, value := range matchers {
+		if v, ok := labels[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// entriesToFrame returns a frame with a row per entry, the most recent first.
+func entriesToFrame(entries []historyEntry) (*data.Frame, error) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	frame := data.NewFrame("states",
+		data.NewField("time", nil, make([]time.Time, 0, len(entries))),
+		data.NewField("ruleUID", nil, make([]string, 0, len(entries))),
+		data.NewField("labels", nil, make([]json.RawMessage, 0, len(entries))),
+		data.NewField("previous", nil, make([]string, 0, len(entries))),
+		data.NewField("current", nil, make([]string, 0, len(entries))),
+		data.NewField("values", nil, make([]json.RawMessage, 0, len(entries))),
+		data.NewField("error", nil, make([]string, 0, len(entries))),
+	)
+	for _, entry := range entries {
+		labels, err := json.Marshal(entry.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal labels: %w", err)
+		}
+		values, err := json.Marshal(entry.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal values: %w", err)
+		}
+		frame.AppendRow(entry.Time, entry.RuleUID, json.RawMessage(labels), entry.Previous, entry.Current, json.RawMessage(values), entry.Error)
+	}
+	return frame, nil
+}
@@ -0,0 +1,280 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	lokiClientTimeout = 30 * time.Second
+
+	// lokiStreamSource is the value of the label "from" of the streams of the state history.
+	lokiStreamSource = "state-history"
+)
+
+// LokiStateHistorian is an implementation of state.Historian that pushes the state history to Loki.
+type LokiStateHistorian struct {
+	url               *url.URL
+	tenantID          string
+	basicAuthUsername string
+	basicAuthPassword string
+	client            *http.Client
+	log               log.Logger
+}
+
+// NewLokiHistorian returns a LokiStateHistorian for the Loki instance of the settings.
+func NewLokiHistorian(cfg setting.UnifiedAlertingStateHistorySettings) (*LokiStateHistorian, error) {
+	u, err := url.Parse(cfg.LokiURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Loki URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Loki URL %q: the scheme must be http or https", cfg.LokiURL)
+	}
+	return &LokiStateHistorian{
+		url:               u,
+		tenantID:          cfg.LokiTenantID,
+		basicAuthUsername: cfg.LokiBasicAuthUsername,
+		basicAuthPassword: cfg.LokiBasicAuthPassword,
+		client:            &http.Client{Timeout: lokiClientTimeout},
+		log:               log.New("ngalert.state.historian"),
+	}, nil
+}
+
+// lokiStream is a stream of the push API and of the results of the query API of Loki.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are pairs of a timestamp in nanoseconds and a log line.
+	Values [][2]string `json:"values"`
+}
+
+// lokiLine is the log line of a state transition. The labels of the alert instance are in the line
+// rather than in the stream to keep the number of streams low.
+type lokiLine struct {
+	Previous string              `json:"previous"`
+	Current  string              `json:"current"`
+	State    string              `json:"state"`
+	Values   map[string]*float64 `json:"values,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Labels   data.Labels         `json:"labels"`
+}
+
+// RecordStates writes a number of state transitions for a given rule to state history.
+func (h *LokiStateHistorian) RecordStates(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+	logger := h.log.FromContext(ctx)
+	// Build the stream before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	stream, err := buildLokiStream(rule, states)
+	if err != nil {
+		logger.Error("Error building state history stream", "error", err)
+		return
+	}
+	if len(stream.Values) == 0 {
+		return
+	}
+	go func() {
+		if err := h.push(ctx, stream); err != nil {
+			logger.Error("Error pushing state history to Loki", "error", err)
+			return
+		}
+		logger.Debug("Done pushing state history to Loki")
+	}()
+}
+
+func buildLokiStream(rule *ngmodels.AlertRule, states []state.StateTransition) (lokiStream, error) {
+	entries := newHistoryEntries(rule, states)
+	// Loki can reject the entries of a stream that are older than the latest one.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	stream := lokiStream{
+		Stream: map[string]string{
+			"from":      lokiStreamSource,
+			"orgID":     strconv.FormatInt(rule.OrgID, 10),
+			"ruleUID":   rule.UID,
+			"folderUID": rule.NamespaceUID,
+			"group":     rule.RuleGroup,
+		},
+		Values: make([][2]string, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		line, err := json.Marshal(lokiLine{
+			Previous: entry.Previous,
+			Current:  entry.Current,
+			State:    entry.State,
+			Values:   entry.Values,
+			Error:    entry.Error,
+			Labels:   entry.Labels,
+		})
+		if err != nil {
+			return lokiStream{}, fmt.Errorf("failed to marshal the state transition: %w", err)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), string(line)})
+	}
+	return stream, nil
+}
+
+func (h *LokiStateHistorian) push(ctx context.Context, streams ...lokiStream) error {
+	body, err := json.Marshal(struct {
+		Streams []lokiStream `json:"streams"`
+	}{Streams: streams})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the streams: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url.JoinPath("/loki/api/v1/push").String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	_, err = h.do(req)
+	return err
+}
+
+// QueryStates returns the state transitions that match the query, the most recent first.
+func (h *LokiStateHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	entries := make([]historyEntry, 0)
+	if query.RuleUIDs != nil && len(query.RuleUIDs) == 0 {
+		return entriesToFrame(entries)
+	}
+
+	params := url.Values{}
+	params.Set("query", buildLogQuery(query))
+	params.Set("start", strconv.FormatInt(query.From.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(query.To.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(query.Limit))
+	params.Set("direction", "backward")
+
+	u := h.url.JoinPath("/loki/api/v1/query_range")
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the query request: %w", err)
+	}
+	body, err := h.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Data struct {
+			Result []lokiStream `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the query response: %w", err)
+	}
+
+	for _, stream := range res.Data.Result {
+		for _, value := range stream.Values {
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q in the query response: %w", value[0], err)
+			}
+			var line lokiLine
+			if err := json.Unmarshal([]byte(value[1]), &line); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal a state transition: %w", err)
+			}
+			// The label names are sanitized by Loki, so the labels are compared again.
+			if !matchLabels(line.Labels, query.Labels) {
+				continue
+			}
+			entries = append(entries, historyEntry{
+				Time:     time.Unix(0, ns).UTC(),
+				RuleUID:  stream.Stream["ruleUID"],
+				Labels:   line.Labels,
+				Previous: line.Previous,
+				Current:  line.Current,
+				State:    line.State,
+				Values:   line.Values,
+				Error:    line.Error,
+			})
+		}
+	}
+	return entriesToFrame(entries)
+}
+
+// buildLogQuery returns the LogQL query of the state transitions that match the query.
+func buildLogQuery(query ngmodels.HistoryQuery) string {
+	selectors := []string{
+		fmt.Sprintf("from=%s", strconv.Quote(lokiStreamSource)),
+		fmt.Sprintf("orgID=%s", strconv.Quote(strconv.FormatInt(query.OrgID, 10))),
+	}
+	if query.RuleUID != "" {
+		selectors = append(selectors, fmt.Sprintf("ruleUID=%s", strconv.Quote(query.RuleUID)))
+	}
+	if len(query.RuleUIDs) > 0 {
+		uids := make([]string, 0, len(query.RuleUIDs))
+		for _, uid := range query.RuleUIDs {
+			uids = append(uids, regexp.QuoteMeta(uid))
+		}
+		selectors = append(selectors, fmt.Sprintf("ruleUID=~%s", strconv.Quote(strings.Join(uids, "|"))))
+	}
+	logQuery := "{" + strings.Join(selectors, ",") + "} | json"
+	if query.State != "" {
+		logQuery += fmt.Sprintf(" | state=%s", strconv.Quote(string(query.State)))
+	}
+
+	names := make([]string, 0, len(query.Labels))
+	for name := range query.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logQuery += fmt.Sprintf(" | labels_%s=%s", sanitizeLokiLabelName(name), strconv.Quote(query.Labels[name]))
+	}
+	return logQuery
+}
+
+// sanitizeLokiLabelName replaces the characters that are not valid in label names, like the json parser of Loki
+// does with the keys of the log lines.
+func sanitizeLokiLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (h *LokiStateHistorian) do(req *http.Request) ([]byte, error) {
+	if h.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", h.tenantID)
+	}
+	if h.basicAuthUsername != "" || h.basicAuthPassword != "" {
+		req.SetBasicAuth(h.basicAuthUsername, h.basicAuthPassword)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send the request to Loki: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of Loki: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		if len(body) > 1024 {
+			body = body[:1024]
+		}
+		return nil, fmt.Errorf("unexpected status code %d from Loki: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,173 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewLokiHistorian(t *testing.T) {
+	_, err := NewLokiHistorian(setting.UnifiedAlertingStateHistorySettings{LokiURL: "http://localhost:3100"})
+	require.NoError(t, err)
+
+	_, err = NewLokiHistorian(setting.UnifiedAlertingStateHistorySettings{LokiURL: "localhost:3100"})
+	require.Error(t, err)
+}
+
+func TestBuildLogQuery(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		query    ngmodels.HistoryQuery
+		expected string
+	}{
+		{
+			desc:     "should select the streams of the organization",
+			query:    ngmodels.HistoryQuery{OrgID: 1},
+			expected: `{from="state-history",orgID="1"} | json`,
+		},
+		{
+			desc:     "should select the stream of the rule",
+			query:    ngmodels.HistoryQuery{OrgID: 1, RuleUID: "rule-1"},
+			expected: `{from="state-history",orgID="1",ruleUID="rule-1"} | json`,
+		},
+		{
+			desc:     "should select the streams of the rules",
+			query:    ngmodels.HistoryQuery{OrgID: 1, RuleUIDs: []string{"rule-1", "rule.2"}},
+			expected: `{from="state-history",orgID="1",ruleUID=~"rule-1|rule\\.2"} | json`,
+		},
+		{
+			desc:     "should filter by state",
+			query:    ngmodels.HistoryQuery{OrgID: 1, State: ngmodels.InstanceStateFiring},
+			expected: `{from="state-history",orgID="1"} | json | state="Alerting"`,
+		},
+		{
+			desc:     "should filter by sanitized labels in order",
+			query:    ngmodels.HistoryQuery{OrgID: 1, Labels: map[string]string{"team": "a\"b", "app.kubernetes.io/name": "x"}},
+			expected: `{from="state-history",orgID="1"} | json | labels_app_kubernetes_io_name="x" | labels_team="a\"b"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, buildLogQuery(tc.query))
+		})
+	}
+}
+
+func TestLokiStateHistorian(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	rule := &ngmodels.AlertRule{OrgID: 1, UID: "rule-1", NamespaceUID: "folder-1", RuleGroup: "group-1"}
+
+	t.Run("should push a stream per rule with the transitions in order", func(t *testing.T) {
+		requests := make(chan *http.Request, 1)
+		bodies := make(chan []byte, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(setting.UnifiedAlertingStateHistorySettings{
+			LokiURL:               srv.URL,
+			LokiTenantID:          "tenant",
+			LokiBasicAuthUsername: "user",
+			LokiBasicAuthPassword: "password",
+		})
+		require.NoError(t, err)
+
+		h.RecordStates(context.Background(), rule, []state.StateTransition{
+			transition(now, eval.Alerting, eval.Normal, data.Labels{"team": "a"}),
+			transition(now.Add(-time.Minute), eval.Normal, eval.Alerting, data.Labels{"team": "a"}),
+		})
+
+		var req *http.Request
+		select {
+		case req = <-requests:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stream was not pushed")
+		}
+		require.Equal(t, "/loki/api/v1/push", req.URL.Path)
+		require.Equal(t, "tenant", req.Header.Get("X-Scope-OrgID"))
+		user, password, ok := req.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+
+		var body struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		require.NoError(t, json.Unmarshal(<-bodies, &body))
+		require.Len(t, body.Streams, 1)
+		require.Equal(t, map[string]string{
+			"from":      "state-history",
+			"orgID":     "1",
+			"ruleUID":   "rule-1",
+			"folderUID": "folder-1",
+			"group":     "group-1",
+		}, body.Streams[0].Stream)
+		require.Len(t, body.Streams[0].Values, 2)
+		require.Equal(t, fmt.Sprint(now.Add(-time.Minute).UnixNano()), body.Streams[0].Values[0][0])
+		require.JSONEq(t, `{"previous":"Normal","current":"Alerting","state":"Alerting","values":{"B":1},"labels":{"team":"a"}}`, body.Streams[0].Values[0][1])
+	})
+
+	t.Run("should query the transitions and compare the labels again", func(t *testing.T) {
+		var query string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/loki/api/v1/query_range", r.URL.Path)
+			require.Equal(t, "backward", r.URL.Query().Get("direction"))
+			require.Equal(t, "10", r.URL.Query().Get("limit"))
+			query = r.URL.Query().Get("query")
+			_, _ = fmt.Fprintf(w, `{"data": {"result": [{
+				"stream": {"ruleUID": "rule-1"},
+				"values": [
+					["%d", "{\"previous\":\"Alerting\",\"current\":\"Normal\",\"state\":\"Normal\",\"labels\":{\"a.b\":\"1\"}}"],
+					["%d", "{\"previous\":\"Normal\",\"current\":\"Alerting\",\"state\":\"Alerting\",\"labels\":{\"a_b\":\"1\"}}"]
+				]
+			}]}}`, now.UnixNano(), now.Add(-time.Minute).UnixNano())
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(setting.UnifiedAlertingStateHistorySettings{LokiURL: srv.URL})
+		require.NoError(t, err)
+
+		frame, err := h.QueryStates(context.Background(), ngmodels.HistoryQuery{
+			OrgID:  1,
+			Labels: map[string]string{"a.b": "1"},
+			From:   now.Add(-time.Hour),
+			To:     now,
+			Limit:  10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{from="state-history",orgID="1"} | json | labels_a_b="1"`, query)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, []string{"rule-1"}, stringValues(frame, "ruleUID"))
+	})
+
+	t.Run("should return an error if Loki responds with an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "too many outstanding requests", http.StatusTooManyRequests)
+		}))
+		t.Cleanup(srv.Close)
+
+		h, err := NewLokiHistorian(setting.UnifiedAlertingStateHistorySettings{LokiURL: srv.URL})
+		require.NoError(t, err)
+
+		_, err = h.QueryStates(context.Background(), ngmodels.HistoryQuery{OrgID: 1, Limit: 10})
+		require.ErrorContains(t, err, "unexpected status code 429")
+	})
+}
@@ -0,0 +1,25 @@
+package historian
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// NoOpHistorian is an implementation of state.Historian that does not record the state history.
+// It is used when the state history is disabled.
+type NoOpHistorian struct{}
+
+func NewNopHistorian() *NoOpHistorian {
+	return &NoOpHistorian{}
+}
+
+func (h *NoOpHistorian) RecordStates(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+}
+
+func (h *NoOpHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	return nil, ngmodels.ErrStateHistoryQueryNotSupported
+}
@@ -0,0 +1,202 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// sqlQueryBatchSize is the number of rows read at once when the state history is filtered by labels,
+// as the labels are filtered after the rows are read.
+const sqlQueryBatchSize = 1000
+
+// SQLStateHistorian is an implementation of state.Historian that uses a table of the Grafana database as the backing datastore.
+type SQLStateHistorian struct {
+	store     db.DB
+	retention time.Duration
+	log       log.Logger
+}
+
+func NewSQLHistorian(store db.DB, retention time.Duration) *SQLStateHistorian {
+	return &SQLStateHistorian{
+		store:     store,
+		retention: retention,
+		log:       log.New("ngalert.state.historian"),
+	}
+}
+
+// stateHistoryRow is a row of the alert_state_history table.
+type stateHistoryRow struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	Labels        string `xorm:"labels"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	State         string `xorm:"state"`
+	StateValues   string `xorm:"state_values"`
+	Error         string `xorm:"error"`
+	Epoch         int64  `xorm:"epoch"`
+}
+
+func (r stateHistoryRow) TableName() string {
+	return "alert_state_history"
+}
+
+// RecordStates writes a number of state transitions for a given rule to state history.
+func (h *SQLStateHistorian) RecordStates(ctx context.Context, rule *ngmodels.AlertRule, states []state.StateTransition) {
+	logger := h.log.FromContext(ctx)
+	// Build the rows before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	rows, err := buildStateHistoryRows(rule, states)
+	if err != nil {
+		logger.Error("Error building state history rows", "error", err)
+		return
+	}
+	go h.recordStatesSync(ctx, rows, logger)
+}
+
+func buildStateHistoryRows(rule *ngmodels.AlertRule, states []state.StateTransition) ([]stateHistoryRow, error) {
+	entries := newHistoryEntries(rule, states)
+	rows := make([]stateHistoryRow, 0, len(entries))
+	for _, entry := range entries {
+		labels, err := json.Marshal(entry.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal labels: %w", err)
+		}
+		values, err := json.Marshal(entry.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal values: %w", err)
+		}
+		rows = append(rows, stateHistoryRow{
+			OrgID:         rule.OrgID,
+			RuleUID:       entry.RuleUID,
+			Labels:        string(labels),
+			PreviousState: entry.Previous,
+			CurrentState:  entry.Current,
+			State:         entry.State,
+			StateValues:   string(values),
+			Error:         entry.Error,
+			Epoch:         entry.Time.UnixMilli(),
+		})
+	}
+	return rows, nil
+}
+
+func (h *SQLStateHistorian) recordStatesSync(ctx context.Context, rows []stateHistoryRow, logger log.Logger) {
+	if len(rows) == 0 {
+		return
+	}
+	err := h.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&rows)
+		return err
+	})
+	if err != nil {
+		logger.Error("Error saving state history batch", "error", err)
+		return
+	}
+	logger.Debug("Done saving state history batch")
+}
+
+// QueryStates returns the state transitions that match the query, the most recent first.
+func (h *SQLStateHistorian) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	entries := make([]historyEntry, 0)
+	if query.RuleUIDs != nil && len(query.RuleUIDs) == 0 {
+		return entriesToFrame(entries)
+	}
+	err := h.store.WithDbSession(ctx, func(sess *db.Session) error {
+		batchSize := query.Limit
+		if len(query.Labels) > 0 {
+			batchSize = sqlQueryBatchSize
+		}
+		for offset := 0; len(entries) < query.Limit; offset += batchSize {
+			q := sess.Table("alert_state_history").Where("org_id = ?", query.OrgID)
+			if query.RuleUID != "" {
+				q = q.Where("rule_uid = ?", query.RuleUID)
+			}
+			if query.RuleUIDs != nil {
+				q = q.In("rule_uid", query.RuleUIDs)
+			}
+			if query.State != "" {
+				q = q.Where("state = ?", string(query.State))
+			}
+			if !query.From.IsZero() {
+				q = q.Where("epoch >= ?", query.From.UnixMilli())
+			}
+			if !query.To.IsZero() {
+				q = q.Where("epoch <= ?", query.To.UnixMilli())
+			}
+
+			rows := make([]stateHistoryRow, 0)
+			if err := q.Desc("epoch", "id").Limit(batchSize, offset).Find(&rows); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				entry, err := row.toHistoryEntry()
+				if err != nil {
+					return err
+				}
+				if !matchLabels(entry.Labels, query.Labels) {
+					continue
+				}
+				entries = append(entries, entry)
+				if len(entries) == query.Limit {
+					break
+				}
+			}
+			if len(rows) < batchSize {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+	return entriesToFrame(entries)
+}
+
+func (r stateHistoryRow) toHistoryEntry() (historyEntry, error) {
+	entry := historyEntry{
+		Time:     time.UnixMilli(r.Epoch).UTC(),
+		RuleUID:  r.RuleUID,
+		Previous: r.PreviousState,
+		Current:  r.CurrentState,
+		State:    r.State,
+		Error:    r.Error,
+	}
+	if err := json.Unmarshal([]byte(r.Labels), &entry.Labels); err != nil {
+		return historyEntry{}, fmt.Errorf("failed to unmarshal labels of state history entry %d: %w", r.ID, err)
+	}
+	if r.StateValues != "" {
+		if err := json.Unmarshal([]byte(r.StateValues), &entry.Values); err != nil {
+			return historyEntry{}, fmt.Errorf("failed to unmarshal values of state history entry %d: %w", r.ID, err)
+		}
+	}
+	return entry, nil
+}
+
+// DeleteExpired deletes the state transitions that are older than the retention.
+// It returns the number of deleted transitions.
+func (h *SQLStateHistorian) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := h.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE epoch < ?", time.Now().Add(-h.retention).UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired state history: %w", err)
+	}
+	return deleted, nil
+}
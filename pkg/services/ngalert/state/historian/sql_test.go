@@ -0,0 +1,138 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationSQLStateHistorian(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	h := NewSQLHistorian(sqlstore.InitTestDB(t), time.Hour)
+
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	rule1 := &ngmodels.AlertRule{OrgID: 1, UID: "rule-1"}
+	rule2 := &ngmodels.AlertRule{OrgID: 1, UID: "rule-2"}
+	otherOrgRule := &ngmodels.AlertRule{OrgID: 2, UID: "rule-1"}
+
+	record := func(rule *ngmodels.AlertRule, states ...state.StateTransition) {
+		t.Helper()
+		rows, err := buildStateHistoryRows(rule, states)
+		require.NoError(t, err)
+		h.recordStatesSync(ctx, rows, h.log)
+	}
+	record(rule1,
+		transition(now.Add(-3*time.Minute), eval.Normal, eval.Alerting, data.Labels{"team": "a"}),
+		transition(now.Add(-2*time.Minute), eval.Normal, eval.Alerting, data.Labels{"team": "b"}),
+		transition(now.Add(-time.Minute), eval.Alerting, eval.Normal, data.Labels{"team": "a"}),
+	)
+	record(rule2, transition(now, eval.Normal, eval.Error, data.Labels{"team": "a"}))
+	record(otherOrgRule, transition(now, eval.Normal, eval.Alerting, data.Labels{"team": "a"}))
+
+	query := func(q ngmodels.HistoryQuery) *data.Frame {
+		t.Helper()
+		q.OrgID = 1
+		if q.Limit == 0 {
+			q.Limit = 100
+		}
+		frame, err := h.QueryStates(ctx, q)
+		require.NoError(t, err)
+		return frame
+	}
+
+	t.Run("should return the transitions of the organization, the most recent first", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{})
+		require.Equal(t, 4, frame.Rows())
+		require.Equal(t, []string{"rule-2", "rule-1", "rule-1", "rule-1"}, stringValues(frame, "ruleUID"))
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, "Error", frame.Fields[4].At(0))
+		require.Equal(t, "boom", frame.Fields[6].At(0))
+	})
+
+	t.Run("should filter by rule UID", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{RuleUID: "rule-1"})
+		require.Equal(t, []string{"rule-1", "rule-1", "rule-1"}, stringValues(frame, "ruleUID"))
+	})
+
+	t.Run("should filter by rule UIDs before applying the limit", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{RuleUIDs: []string{"rule-1"}, Limit: 1})
+		require.Equal(t, []string{"rule-1"}, stringValues(frame, "ruleUID"))
+		require.Equal(t, now.Add(-time.Minute), frame.Fields[0].At(0))
+
+		require.Equal(t, 0, query(ngmodels.HistoryQuery{RuleUIDs: []string{}}).Rows())
+	})
+
+	t.Run("should filter by labels", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{RuleUID: "rule-1", Labels: map[string]string{"team": "a"}})
+		require.Equal(t, 2, frame.Rows())
+		labels := frame.Fields[2].At(0).(json.RawMessage)
+		require.JSONEq(t, `{"team": "a"}`, string(labels))
+	})
+
+	t.Run("should filter by state", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{State: ngmodels.InstanceStateFiring})
+		require.Equal(t, []string{"Alerting", "Alerting"}, stringValues(frame, "current"))
+	})
+
+	t.Run("should filter by time range", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{From: now.Add(-150 * time.Second), To: now.Add(-time.Minute)})
+		require.Equal(t, []string{"Normal", "Alerting"}, stringValues(frame, "current"))
+	})
+
+	t.Run("should apply the limit after filtering by labels", func(t *testing.T) {
+		frame := query(ngmodels.HistoryQuery{Labels: map[string]string{"team": "b"}, Limit: 1})
+		require.Equal(t, 1, frame.Rows())
+		labels := frame.Fields[2].At(0).(json.RawMessage)
+		require.JSONEq(t, `{"team": "b"}`, string(labels))
+	})
+
+	t.Run("should delete the expired transitions", func(t *testing.T) {
+		record(rule1, transition(now.Add(-2*time.Hour), eval.Normal, eval.Pending, data.Labels{"team": "c"}))
+		require.Equal(t, 1, query(ngmodels.HistoryQuery{Labels: map[string]string{"team": "c"}}).Rows())
+
+		deleted, err := h.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+		require.Equal(t, 0, query(ngmodels.HistoryQuery{Labels: map[string]string{"team": "c"}}).Rows())
+		require.Equal(t, 4, query(ngmodels.HistoryQuery{}).Rows())
+	})
+}
+
+func transition(t time.Time, from, to eval.State, labels data.Labels) state.StateTransition {
+	s := &state.State{
+		State:              to,
+		Labels:             labels,
+		Values:             map[string]float64{"B": 1},
+		LastEvaluationTime: t,
+	}
+	if to == eval.Error {
+		s.Error = errors.New("boom")
+	}
+	return state.StateTransition{
+		State:         s,
+		PreviousState: from,
+	}
+}
+
+func stringValues(frame *data.Frame, name string) []string {
+	field, _ := frame.FieldByName(name)
+	values := make([]string, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		values = append(values, field.At(i).(string))
+	}
+	return values
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -121,24 +122,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			state := st.stateFromInstance(entry, ruleForEntry.Annotations)
+			rulesStates.states[state.CacheID] = state
 			statesCount++
 		}
 	}
@@ -146,6 +131,69 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// LoadStateByRuleUID loads the states of the rule from the database into the cache, unless the cache already has states
+// of the rule. It is used when this instance starts evaluating a rule that was evaluated by another member of the
+// cluster, so that the pending periods continue and the alerts it sent are resolved. Returns the number of loaded states.
+func (st *Manager) LoadStateByRuleUID(ctx context.Context, rule *ngModels.AlertRule) int {
+	if st.instanceStore == nil || len(st.cache.getStatesForRuleUID(rule.OrgID, rule.UID)) > 0 {
+		return 0
+	}
+	logger := st.log.New(rule.GetKey().LogContext()...)
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		logger.Error("Unable to fetch the state of the rule", "error", err)
+		return 0
+	}
+	for _, entry := range cmd.Result {
+		st.cache.set(st.stateFromInstance(entry, rule.Annotations))
+	}
+	logger.Debug("Rules state was loaded", "states", len(cmd.Result))
+	return len(cmd.Result)
+}
+
+// GetPersistedStatesForRuleUID returns the states of the rule saved in the database by its latest evaluation. It is
+// used for the rules evaluated by another member of the cluster, whose states are not in the cache.
+func (st *Manager) GetPersistedStatesForRuleUID(ctx context.Context, orgID int64, alertRuleUID string) ([]*State, error) {
+	if st.instanceStore == nil {
+		return nil, nil
+	}
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   alertRuleUID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		return nil, err
+	}
+	states := make([]*State, 0, len(cmd.Result))
+	for _, entry := range cmd.Result {
+		states = append(states, st.stateFromInstance(entry, nil))
+	}
+	return states, nil
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, annotations map[string]string) *State {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          annotations,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -165,15 +213,48 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, ruleKey ngModels.Ale
 	return states
 }
 
+// ForgetStateByRuleUID deletes the states of the rule from the cache. Unlike ResetStateByRuleUID, it does not delete
+// them from the database, because they are handed off to the member of the cluster that evaluates the rule now, which
+// loads them with LoadStateByRuleUID.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) []*State {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.log.Debug("Rules state was forgotten", append(ruleKey.LogContext(), "states", len(states))...)
+	return states
+}
+
 // ProcessEvalResults updates the current states that belong to a rule with the evaluation results.
 // if extraLabels is not empty, those labels will be added to every state. The extraLabels take precedence over rule labels and result labels
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []*State {
+	return st.ProcessEvalResultsWithSuppression(ctx, evaluatedAt, alertRule, results, extraLabels, Suppression{})
+}
+
+// Suppression describes why the alert instances of a rule must not be notified.
+type Suppression struct {
+	// MaintenanceWindows are the active maintenance windows in the mode Maintenance that apply to the rule.
+	// The states whose labels match a window get the suppression reason Maintenance. Their transitions are not
+	// recorded in the state history.
+	MaintenanceWindows []*ngModels.MaintenanceWindow
+	// ParentFiring is set if an alert instance of the rule that the rule depends on is firing.
+	// The states get the suppression reason Suppressed.
+	ParentFiring bool
+}
+
+// ProcessEvalResultsWithSuppression is ProcessEvalResults for a rule whose alert instances may be suppressed.
+// The scheduler does not send the suppressed alert instances to the Alertmanager.
+func (st *Manager) ProcessEvalResultsWithSuppression(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, suppression Suppression) []*State {
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
 	var states []StateTransition
 
 	for _, result := range results {
 		s := st.setNextState(ctx, alertRule, result, extraLabels, logger)
+		s.PreviousSuppressionReason = s.SuppressionReason
+		s.SuppressionReason = ""
+		if inMaintenance(suppression.MaintenanceWindows, s.Labels) {
+			s.SuppressionReason = ngModels.StateReasonMaintenance
+		} else if suppression.ParentFiring {
+			s.SuppressionReason = ngModels.StateReasonSuppressed
+		}
 		states = append(states, s)
 	}
 	resolvedStates := st.staleResultsHandler(ctx, logger, alertRule, evaluatedAt)
@@ -182,7 +263,8 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 
 	changedStates := make([]StateTransition, 0, len(states))
 	for _, s := range states {
-		if s.changed() {
+		// the transitions in and during maintenance are not recorded, but the first transition after it is
+		if s.changed() && s.SuppressionReason != ngModels.StateReasonMaintenance {
 			changedStates = append(changedStates, s)
 		}
 	}
@@ -199,6 +281,16 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 	return nextStates
 }
 
+// inMaintenance returns true if the labels match any of the maintenance windows.
+func inMaintenance(windows []*ngModels.MaintenanceWindow, lbs data.Labels) bool {
+	for _, w := range windows {
+		if w.MatchesLabels(lbs) {
+			return true
+		}
+	}
+	return false
+}
+
 // Set the current state based on evaluation results
 func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, logger log.Logger) StateTransition {
 	currentState := st.cache.getOrCreate(ctx, st.log, alertRule, result, extraLabels, st.externalURL)
@@ -273,6 +365,40 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// GetActiveResultLabels returns the labels of the evaluation results of the rule whose states are
+// pending or alerting. They are used by threshold expressions with a recovery threshold.
+func (st *Manager) GetActiveResultLabels(alertRule *ngModels.AlertRule) []data.Labels {
+	var result []data.Labels
+	for _, s := range st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		if s.State != eval.Pending && s.State != eval.Alerting {
+			continue
+		}
+		if s.ResultLabels != nil {
+			result = append(result, s.ResultLabels)
+			continue
+		}
+		// the states restored from the database do not have the labels of the result until they are evaluated again
+		result = append(result, resultLabels(s.Labels, alertRule))
+	}
+	return result
+}
+
+// resultLabels approximates the labels of the evaluation result that a state was created from by
+// removing the reserved labels and the labels of the rule from the labels of the state.
+func resultLabels(lbs data.Labels, alertRule *ngModels.AlertRule) data.Labels {
+	result := make(data.Labels, len(lbs))
+	for k, v := range lbs {
+		if _, ok := alertRule.Labels[k]; ok {
+			continue
+		}
+		if _, ok := ngModels.InternalLabelNameSet[k]; ok || k == ngModels.FolderTitleLabel || k == model.AlertNameLabel {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -217,8 +218,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -272,8 +274,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_1":             "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label_1": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -296,8 +299,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_2":             "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label_2": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -354,8 +358,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -416,8 +421,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -489,8 +495,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -584,8 +591,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -666,8 +674,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(20 * time.Second),
@@ -731,8 +740,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -796,8 +806,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -861,9 +872,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.NoData.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -927,8 +939,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -992,8 +1005,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1017,8 +1031,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1083,8 +1098,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test-1",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test-1"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1109,8 +1125,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test-2",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test-2"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1134,8 +1151,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1202,8 +1220,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1232,8 +1251,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1292,9 +1312,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.NoData.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1359,9 +1380,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.NoData.String(),
 
 					Results: []state.Evaluation{
 						{
@@ -1428,10 +1450,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Pending,
-					StateReason: eval.Error.String(),
-					Error:       errors.New("test error"),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
+					StateReason:  eval.Error.String(),
+					Error:        errors.New("test error"),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1520,9 +1543,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.Error.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.Error.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(20 * time.Second),
@@ -1602,8 +1626,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"datasource_uid":               "datasource_uid_1",
 						"ref_id":                       "A",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Error,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Error,
 					Error: expr.QueryError{
 						RefID: "A",
 						Err:   errors.New("this is an error"),
@@ -1680,10 +1705,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.Error.String(),
-					Error:       nil,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.Error.String(),
+					Error:        nil,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1754,10 +1780,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.Error.String(),
-					Error:       nil,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.Error.String(),
+					Error:        nil,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1854,8 +1881,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Error,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Error,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(40 * time.Second),
@@ -1936,8 +1964,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -2024,8 +2053,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -2087,8 +2117,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"job":                          "prod/grafana",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"cluster": "us-central-1", "namespace": "prod", "pod": "grafana"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -2241,8 +2272,9 @@ func TestStaleResultsHandler(t *testing.T) {
 						"alertname":                    rule.Title,
 						"test1":                        "testValue1",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"test1": "testValue1"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -2390,3 +2422,124 @@ func TestStaleResults(t *testing.T) {
 		})
 	})
 }
+
+func TestGetActiveResultLabels(t *testing.T) {
+	clk := clock.NewMock()
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+	rule := models.AlertRuleGen()()
+	rule.Labels = map[string]string{"team": "ops"}
+
+	results := eval.Results{
+		eval.Result{Instance: data.Labels{"host": "a"}, State: eval.Alerting, EvaluatedAt: clk.Now()},
+		eval.Result{Instance: data.Labels{"host": "b"}, State: eval.Normal, EvaluatedAt: clk.Now()},
+		// the label team of the result is overridden by the label of the rule, but it is kept in the result labels
+		eval.Result{Instance: data.Labels{"host": "c", "team": "dev"}, State: eval.Alerting, EvaluatedAt: clk.Now()},
+	}
+	st.ProcessEvalResults(context.Background(), clk.Now(), rule, results, data.Labels{
+		"alertname":                    rule.Title,
+		"__alert_rule_namespace_uid__": rule.NamespaceUID,
+		"__alert_rule_uid__":           rule.UID,
+		"grafana_folder":               "folder",
+	})
+
+	require.ElementsMatch(t, []data.Labels{{"host": "a"}, {"host": "c", "team": "dev"}}, st.GetActiveResultLabels(rule))
+}
+
+type recordingHistorian struct {
+	state.FakeHistorian
+	transitions []state.StateTransition
+}
+
+func (h *recordingHistorian) RecordStates(_ context.Context, _ *models.AlertRule, states []state.StateTransition) {
+	h.transitions = append(h.transitions, states...)
+}
+
+func TestProcessEvalResultsWithSuppression_Maintenance(t *testing.T) {
+	clk := clock.NewMock()
+	historian := &recordingHistorian{}
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clk, historian)
+	rule := models.AlertRuleGen()()
+	rule.For = 0
+	matcher, err := amlabels.NewMatcher(amlabels.MatchEqual, "host", "a")
+	require.NoError(t, err)
+	windows := []*models.MaintenanceWindow{{OrgID: rule.OrgID, UID: "maintenance", Title: "maintenance", Mode: models.MaintenanceModeMaintenance, Matchers: amlabels.Matchers{matcher}}}
+	results := func() eval.Results {
+		return eval.Results{
+			eval.Result{Instance: data.Labels{"host": "a"}, State: eval.Alerting, EvaluatedAt: clk.Now()},
+			eval.Result{Instance: data.Labels{"host": "b"}, State: eval.Alerting, EvaluatedAt: clk.Now()},
+		}
+	}
+	reasons := func(states []*state.State) map[string]string {
+		result := make(map[string]string, len(states))
+		for _, s := range states {
+			result[s.Labels["host"]] = state.FormatStateAndReason(s.State, s.StateReason)
+			if s.SuppressionReason != "" {
+				result[s.Labels["host"]] += " " + s.SuppressionReason
+			}
+		}
+		return result
+	}
+	recorded := func() []string {
+		var result []string
+		for _, s := range historian.transitions {
+			result = append(result, s.Labels["host"])
+		}
+		historian.transitions = nil
+		return result
+	}
+
+	states := st.ProcessEvalResultsWithSuppression(context.Background(), clk.Now(), rule, results(), nil, state.Suppression{MaintenanceWindows: windows})
+	require.Equal(t, map[string]string{"a": "Alerting Maintenance", "b": "Alerting"}, reasons(states))
+	require.Equal(t, []string{"b"}, recorded(), "the transitions of the instances in maintenance should not be recorded")
+
+	clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+	states = st.ProcessEvalResults(context.Background(), clk.Now(), rule, results(), nil)
+	require.Equal(t, map[string]string{"a": "Alerting", "b": "Alerting"}, reasons(states))
+	require.Empty(t, recorded(), "the end of the maintenance should not be recorded if the state is the same")
+}
+
+func TestProcessEvalResultsWithSuppression_MaintenanceKeepsStateReason(t *testing.T) {
+	clk := clock.NewMock()
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+	rule := models.AlertRuleGen()()
+	rule.For = 0
+	rule.NoDataState = models.Alerting
+	windows := []*models.MaintenanceWindow{{OrgID: rule.OrgID, UID: "maintenance", Title: "maintenance", Mode: models.MaintenanceModeMaintenance}}
+	results := func() eval.Results {
+		return eval.Results{eval.Result{Instance: data.Labels{"host": "a"}, State: eval.NoData, EvaluatedAt: clk.Now()}}
+	}
+
+	states := st.ProcessEvalResultsWithSuppression(context.Background(), clk.Now(), rule, results(), nil, state.Suppression{MaintenanceWindows: windows})
+	require.Len(t, states, 1)
+	require.Equal(t, "Alerting (NoData)", state.FormatStateAndReason(states[0].State, states[0].StateReason))
+	require.Equal(t, models.StateReasonMaintenance, states[0].SuppressionReason)
+
+	clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+	states = st.ProcessEvalResults(context.Background(), clk.Now(), rule, results(), nil)
+	require.Len(t, states, 1)
+	require.Equal(t, "Alerting (NoData)", state.FormatStateAndReason(states[0].State, states[0].StateReason))
+	require.Empty(t, states[0].SuppressionReason)
+}
+
+func TestProcessEvalResultsWithSuppression_ParentFiring(t *testing.T) {
+	clk := clock.NewMock()
+	st := state.NewManager(testMetrics.GetStateMetrics(), nil, &state.FakeInstanceStore{}, &state.NoopImageService{}, clk, &state.FakeHistorian{})
+	rule := models.AlertRuleGen()()
+	rule.For = 0
+	results := func() eval.Results {
+		return eval.Results{eval.Result{Instance: data.Labels{"host": "a"}, State: eval.Alerting, EvaluatedAt: clk.Now()}}
+	}
+
+	states := st.ProcessEvalResultsWithSuppression(context.Background(), clk.Now(), rule, results(), nil, state.Suppression{ParentFiring: true})
+	require.Len(t, states, 1)
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.Empty(t, states[0].StateReason)
+	require.Equal(t, models.StateReasonSuppressed, states[0].SuppressionReason)
+
+	clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+	states = st.ProcessEvalResultsWithSuppression(context.Background(), clk.Now(), rule, results(), nil, state.Suppression{})
+	require.Len(t, states, 1)
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.Empty(t, states[0].StateReason)
+	require.Empty(t, states[0].SuppressionReason)
+}
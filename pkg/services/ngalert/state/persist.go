@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -24,6 +26,10 @@ type RuleReader interface {
 type Historian interface {
 	// RecordStates writes a number of state transitions for a given rule to state history.
 	RecordStates(ctx context.Context, rule *models.AlertRule, states []StateTransition)
+
+	// QueryStates returns the state transitions that match the query as a data frame with a row per transition.
+	// It returns models.ErrStateHistoryQueryNotSupported if the history cannot be queried.
+	QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
 }
 
 // ImageCapturer captures images.
@@ -30,6 +30,10 @@ type State struct {
 	// StateReason is a textual description to explain why the state has its current state.
 	StateReason string
 
+	// SuppressionReason explains why the alert instance is held back from the Alertmanager, such as
+	// models.StateReasonMaintenance or models.StateReasonSuppressed. It is kept apart from StateReason, which it does not replace.
+	SuppressionReason string
+
 	// Results contains the result of the current and previous evaluations.
 	Results []Evaluation
 
@@ -53,6 +57,10 @@ type State struct {
 	// If a label is templated then the template is first evaluated to derive the final label.
 	Labels data.Labels
 
+	// ResultLabels contains the labels of the evaluation result that the state was created from. It is empty for the
+	// states restored from the database.
+	ResultLabels data.Labels
+
 	// Values contains the values of any instant vectors, reduce and math expressions, or classic
 	// conditions.
 	Values map[string]float64
@@ -93,6 +101,8 @@ type StateTransition struct {
 	*State
 	PreviousState       eval.State
 	PreviousStateReason string
+	// PreviousSuppressionReason is the suppression reason of the state before the evaluation.
+	PreviousSuppressionReason string
 }
 
 func (c StateTransition) Formatted() string {
@@ -148,6 +148,9 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,
+				IsPaused:         r.IsPaused,
+				DependsOn:        r.DependsOn,
 			})
 		}
 		if len(newRules) > 0 {
@@ -216,6 +219,9 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,
+				IsPaused:         r.New.IsPaused,
+				DependsOn:        r.New.DependsOn,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -506,5 +512,17 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.Record != nil {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return err
+		}
+	}
+
+	if alertRule.DependsOn != nil {
+		if err := alertRule.DependsOn.Validate(alertRule.UID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
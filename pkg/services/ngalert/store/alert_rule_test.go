@@ -66,6 +66,35 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 
 		require.ErrorIs(t, err, ErrOptimisticLock)
 	})
+
+	t.Run("should store the record of recording rules", func(t *testing.T) {
+		rule := createRule(t, store)
+		require.Nil(t, rule.Record)
+
+		newRule := models.CopyRule(rule)
+		newRule.Record = &models.Record{Metric: "cpu_usage:avg", From: newRule.Data[0].RefID}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.NoError(t, err)
+
+		dbrule := &models.AlertRule{}
+		var versions []models.AlertRuleVersion
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exist, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+			require.Truef(t, exist, fmt.Sprintf("rule with ID %d does not exist", rule.ID))
+			if err != nil {
+				return err
+			}
+			return sess.Table(models.AlertRuleVersion{}).Where("rule_uid = ?", rule.UID).Find(&versions)
+		})
+		require.NoError(t, err)
+		require.Equal(t, newRule.Record, dbrule.Record)
+		require.Len(t, versions, 1)
+		require.Equal(t, newRule.Record, versions[0].Record)
+	})
 }
 
 func withIntervalMatching(baseInterval time.Duration) func(*models.AlertRule) {
@@ -166,3 +166,43 @@ func UpdateCalculatedRuleFields(ch *GroupDelta) *GroupDelta {
 		Delete:         ch.Delete,
 	}
 }
+
+// ValidateDependencies checks the dependencies of the new and updated rules of the delta against the rules of the
+// organization as they are once the delta is applied. See models.ValidateRuleDependencies.
+func ValidateDependencies(ctx context.Context, ruleReader RuleReader, orgID int64, delta *GroupDelta) error {
+	changed := make([]*models.AlertRule, 0, len(delta.New)+len(delta.Update))
+	for _, rule := range delta.New {
+		if rule.DependsOn != nil {
+			changed = append(changed, rule)
+		}
+	}
+	for _, update := range delta.Update {
+		if update.New.DependsOn != nil {
+			changed = append(changed, update.New)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	q := &models.ListAlertRulesQuery{OrgID: orgID}
+	if err := ruleReader.ListAlertRules(ctx, q); err != nil {
+		return fmt.Errorf("failed to query database for the rules of the organization: %w", err)
+	}
+	rulesByUID := make(map[string]*models.AlertRule, len(q.Result)+len(delta.New))
+	for _, rule := range q.Result {
+		rulesByUID[rule.UID] = rule
+	}
+	for _, rule := range delta.Delete {
+		delete(rulesByUID, rule.UID)
+	}
+	for _, update := range delta.Update {
+		rulesByUID[update.New.UID] = update.New
+	}
+	for _, rule := range delta.New {
+		if rule.UID != "" {
+			rulesByUID[rule.UID] = rule
+		}
+	}
+	return models.ValidateRuleDependencies(changed, rulesByUID)
+}
@@ -455,3 +455,32 @@ func randFolder() *grafana_models.Folder {
 		CreatedBy: 0,
 	}
 }
+
+func TestValidateDependencies(t *testing.T) {
+	orgID := int64(rand.Int31())
+	fakeStore := fakes.NewRuleStore(t)
+	parent := models.AlertRuleGen(withOrgID(orgID))()
+	child := models.AlertRuleGen(withOrgID(orgID))()
+	child.DependsOn = &models.AlertRuleDependency{UID: parent.UID}
+	fakeStore.PutRule(context.Background(), parent, child)
+
+	t.Run("accepts a dependency on an existing rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(withOrgID(orgID))()
+		rule.DependsOn = &models.AlertRuleDependency{UID: child.UID}
+		require.NoError(t, ValidateDependencies(context.Background(), fakeStore, orgID, &GroupDelta{New: []*models.AlertRule{rule}}))
+	})
+
+	t.Run("rejects a dependency on a rule deleted by the changes", func(t *testing.T) {
+		rule := models.AlertRuleGen(withOrgID(orgID))()
+		rule.DependsOn = &models.AlertRuleDependency{UID: parent.UID}
+		delta := &GroupDelta{New: []*models.AlertRule{rule}, Delete: []*models.AlertRule{parent}}
+		require.ErrorIs(t, ValidateDependencies(context.Background(), fakeStore, orgID, delta), models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects an update that forms a cycle", func(t *testing.T) {
+		updated := models.CopyRule(parent)
+		updated.DependsOn = &models.AlertRuleDependency{UID: child.UID}
+		delta := &GroupDelta{Update: []RuleDelta{{Existing: parent, New: updated}}}
+		require.ErrorIs(t, ValidateDependencies(context.Background(), fakeStore, orgID, delta), models.ErrAlertRuleFailedValidation)
+	})
+}
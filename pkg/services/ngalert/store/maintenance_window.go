@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MaintenanceWindowStore is the database interface of the maintenance windows.
+type MaintenanceWindowStore interface {
+	// ListMaintenanceWindows returns the maintenance windows of the organization.
+	ListMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error)
+	// GetMaintenanceWindow returns the maintenance window with the UID. It returns
+	// models.ErrMaintenanceWindowNotFound if it does not exist.
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error)
+	// InsertMaintenanceWindow inserts the maintenance window and sets its ID.
+	InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	// UpdateMaintenanceWindow replaces the maintenance window with the same ID.
+	UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error
+	// DeleteMaintenanceWindow deletes the maintenance window with the UID. It does not return an error if it does not exist.
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+	// GetMaintenanceWindowsForScheduling returns the maintenance windows of all organizations.
+	GetMaintenanceWindowsForScheduling(ctx context.Context) ([]*models.MaintenanceWindow, error)
+}
+
+func (st DBstore) ListMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("id").Find(&windows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+func (st DBstore) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&window)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance window: %w", err)
+		}
+		if !exists {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (st DBstore) InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(window); err != nil {
+			if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+				return fmt.Errorf("%w: a maintenance window with the UID '%s' already exists", models.ErrMaintenanceWindowFailedValidation, window.UID)
+			}
+			return fmt.Errorf("failed to insert maintenance window: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		updated, err := sess.ID(window.ID).AllCols().Update(window)
+		if err != nil {
+			return fmt.Errorf("failed to update maintenance window: %w", err)
+		}
+		if updated == 0 {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.MaintenanceWindow{})
+		return err
+	})
+}
+
+func (st DBstore) GetMaintenanceWindowsForScheduling(ctx context.Context) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Find(&windows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	return windows, nil
+}
@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationMaintenanceWindows(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	// our database schema uses second precision for timestamps
+	now := time.Now().Truncate(time.Second).UTC()
+	oneOff := &models.MaintenanceWindow{
+		OrgID:     1,
+		UID:       "one-off",
+		Title:     "deploy",
+		Mode:      models.MaintenanceModeSkipEvaluation,
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		FolderUID: "folder",
+		RuleGroup: "group",
+		Updated:   now,
+	}
+	matcher, err := amlabels.NewMatcher(amlabels.MatchRegexp, "team", "sre|ops")
+	require.NoError(t, err)
+	recurring := &models.MaintenanceWindow{
+		OrgID: 1,
+		UID:   "recurring",
+		Title: "nightly",
+		Mode:  models.MaintenanceModeMaintenance,
+		TimeIntervals: []timeinterval.TimeInterval{{
+			Times: []timeinterval.TimeRange{{StartMinute: 60, EndMinute: 120}},
+		}},
+		Matchers: amlabels.Matchers{matcher},
+		Updated:  now,
+	}
+	otherOrg := &models.MaintenanceWindow{OrgID: 2, UID: "one-off", Title: "deploy", Mode: models.MaintenanceModeSkipEvaluation, Updated: now}
+
+	require.NoError(t, dbstore.InsertMaintenanceWindow(ctx, oneOff))
+	require.NoError(t, dbstore.InsertMaintenanceWindow(ctx, recurring))
+	require.NoError(t, dbstore.InsertMaintenanceWindow(ctx, otherOrg))
+	require.NotZero(t, oneOff.ID)
+
+	t.Run("should fail to insert a window with the same UID", func(t *testing.T) {
+		err := dbstore.InsertMaintenanceWindow(ctx, &models.MaintenanceWindow{OrgID: 1, UID: "one-off", Title: "copy", Mode: models.MaintenanceModeSkipEvaluation, Updated: now})
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowFailedValidation)
+	})
+
+	t.Run("should return the windows of the organization", func(t *testing.T) {
+		windows, err := dbstore.ListMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, windows, 2)
+		require.Equal(t, "one-off", windows[0].UID)
+		require.True(t, windows[0].StartsAt.Equal(oneOff.StartsAt))
+		require.True(t, windows[0].EndsAt.Equal(oneOff.EndsAt))
+		require.Equal(t, "recurring", windows[1].UID)
+		require.True(t, windows[1].StartsAt.IsZero())
+		require.Equal(t, recurring.TimeIntervals, windows[1].TimeIntervals)
+		require.Equal(t, recurring.Matchers.String(), windows[1].Matchers.String())
+		require.True(t, windows[1].MatchesLabels(map[string]string{"team": "ops"}))
+	})
+
+	t.Run("should get, update and delete a window", func(t *testing.T) {
+		window, err := dbstore.GetMaintenanceWindow(ctx, 1, "one-off")
+		require.NoError(t, err)
+		require.Equal(t, oneOff.ID, window.ID)
+
+		window.Title = "release"
+		require.NoError(t, dbstore.UpdateMaintenanceWindow(ctx, window))
+		window, err = dbstore.GetMaintenanceWindow(ctx, 1, "one-off")
+		require.NoError(t, err)
+		require.Equal(t, "release", window.Title)
+
+		require.NoError(t, dbstore.DeleteMaintenanceWindow(ctx, 1, "one-off"))
+		_, err = dbstore.GetMaintenanceWindow(ctx, 1, "one-off")
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+		require.ErrorIs(t, dbstore.UpdateMaintenanceWindow(ctx, window), models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("should return the windows of all organizations for scheduling", func(t *testing.T) {
+		windows, err := dbstore.GetMaintenanceWindowsForScheduling(ctx)
+		require.NoError(t, err)
+		require.Len(t, windows, 2)
+	})
+}
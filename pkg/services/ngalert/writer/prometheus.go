@@ -0,0 +1,91 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PrometheusWriter writes the results of recording rules to a Prometheus remote write endpoint.
+type PrometheusWriter struct {
+	url               string
+	basicAuthUsername string
+	basicAuthPassword string
+	headers           map[string]string
+	client            *http.Client
+	logger            log.Logger
+}
+
+// NewPrometheusWriter returns a PrometheusWriter for the remote write endpoint of the settings.
+func NewPrometheusWriter(cfg setting.UnifiedAlertingRecordingRuleSettings, logger log.Logger) (*PrometheusWriter, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid remote write URL %q: the scheme must be http or https", cfg.URL)
+	}
+	return &PrometheusWriter{
+		url:               cfg.URL,
+		basicAuthUsername: cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		headers:           cfg.CustomHeaders,
+		client:            &http.Client{Timeout: cfg.Timeout},
+		logger:            logger,
+	}, nil
+}
+
+// Write implements Writer.
+func (w *PrometheusWriter) Write(ctx context.Context, metric string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	frames, err := numbersToFrames(t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	series := remotewrite.TimeSeriesFromFramesWithMetricName(metric, frames...)
+	if len(series) == 0 {
+		w.logger.Debug("No samples to write", "metric", metric)
+		return nil
+	}
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the samples: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	if w.basicAuthUsername != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUsername, w.basicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the samples to the remote write endpoint: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d from the remote write endpoint: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	w.logger.Debug("Samples written", "metric", metric, "series", len(series))
+	return nil
+}
@@ -0,0 +1,126 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusWriter(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var requests []*prompb.WriteRequest
+	var headers []http.Header
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		req := &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(body, req))
+		requests = append(requests, req)
+		headers = append(headers, r.Header)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	writer, err := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{
+		URL:               server.URL,
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "password",
+		Timeout:           time.Second,
+		CustomHeaders:     map[string]string{"X-Scope-OrgID": "tenant"},
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	numbers := data.Frames{
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "a", "team": "other"}, []*float64{fp(1)})),
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "b"}, []*float64{fp(2)})),
+		// null numbers are not written
+		data.NewFrame("", data.NewField("B", data.Labels{"host": "c"}, []*float64{nil})),
+		// NoData
+		data.NewFrame(""),
+	}
+
+	t.Run("writes the numbers as samples of the metric", func(t *testing.T) {
+		requests, headers = nil, nil
+		err := writer.Write(context.Background(), "cpu:usage", now, numbers, map[string]string{"team": "sre"})
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+
+		series := requests[0].Timeseries
+		require.Len(t, series, 2)
+		for i, host := range []string{"a", "b"} {
+			require.Equal(t, map[string]string{
+				"__name__": "cpu:usage",
+				"host":     host,
+				"team":     "sre",
+			}, labelsMap(series[i].Labels))
+			require.Equal(t, []prompb.Sample{{Value: float64(i + 1), Timestamp: now.UnixMilli()}}, series[i].Samples)
+		}
+
+		user, password, ok := (&http.Request{Header: headers[0]}).BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+		require.Equal(t, "tenant", headers[0].Get("X-Scope-OrgID"))
+		require.Equal(t, "snappy", headers[0].Get("Content-Encoding"))
+	})
+
+	t.Run("does not send a request without samples", func(t *testing.T) {
+		requests, headers = nil, nil
+		err := writer.Write(context.Background(), "cpu:usage", now, data.Frames{data.NewFrame("")}, nil)
+		require.NoError(t, err)
+		require.Empty(t, requests)
+	})
+
+	t.Run("fails if the result is time series", func(t *testing.T) {
+		requests, headers = nil, nil
+		series := data.Frames{data.NewFrame("",
+			data.NewField("Time", nil, []time.Time{now, now.Add(time.Second)}),
+			data.NewField("B", nil, []*float64{fp(1), fp(2)}),
+		)}
+		err := writer.Write(context.Background(), "cpu:usage", now, series, nil)
+		require.ErrorIs(t, err, ErrNotNumbers)
+		require.Empty(t, requests)
+	})
+
+	t.Run("fails if the endpoint returns an error", func(t *testing.T) {
+		status = http.StatusBadRequest
+		t.Cleanup(func() {
+			status = http.StatusNoContent
+		})
+		err := writer.Write(context.Background(), "cpu:usage", now, numbers, nil)
+		require.ErrorContains(t, err, "unexpected status code 400")
+	})
+}
+
+func TestNewPrometheusWriter(t *testing.T) {
+	_, err := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{URL: "prometheus:9090/api/v1/write"}, log.NewNopLogger())
+	require.Error(t, err)
+}
+
+func labelsMap(labels []prompb.Label) map[string]string {
+	result := make(map[string]string, len(labels))
+	for _, l := range labels {
+		result[l.Name] = l.Value
+	}
+	return result
+}
+
+func fp(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,63 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ErrNotNumbers is returned when the result of a recording rule is not a set of numbers,
+// for example when it is a set of time series that are not reduced.
+var ErrNotNumbers = errors.New("the result of a recording rule must be numbers")
+
+// Writer writes the results of recording rules.
+type Writer interface {
+	// Write writes the numbers of frames as samples of the metric at the time t. The labels of the
+	// samples are the labels of the numbers and extraLabels, which take precedence.
+	Write(ctx context.Context, metric string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
+// numbersToFrames converts the numbers of frames, as returned by expressions, to frames with a single
+// row at the time t, so that they can be converted to time series. Frames without fields are NoData
+// and are ignored.
+func numbersToFrames(t time.Time, frames data.Frames, extraLabels map[string]string) (data.Frames, error) {
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		if frame.TimeSeriesSchema().Type != data.TimeSeriesTypeNot || frame.Rows() > 1 {
+			return nil, fmt.Errorf("%w, got a frame with %d rows of type %s", ErrNotNumbers, frame.Rows(), frame.TimeSeriesSchema().Type)
+		}
+		if frame.Rows() == 0 {
+			continue
+		}
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			if _, ok := field.ConcreteAt(0); !ok {
+				continue
+			}
+			value, err := field.FloatAt(0)
+			if err != nil {
+				return nil, err
+			}
+			labels := field.Labels.Copy()
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			for k, v := range extraLabels {
+				labels[k] = v
+			}
+			result = append(result, data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t}),
+				data.NewField("value", labels, []float64{value}),
+			))
+		}
+	}
+	return result, nil
+}
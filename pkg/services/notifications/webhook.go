@@ -97,7 +97,7 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		err := webhook.Validation(body, resp.StatusCode)
 		if err != nil {
 			ns.log.Debug("Webhook failed validation", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-			return fmt.Errorf("webhook failed validation: %w", err)
+			return WebhookResponseError{StatusCode: resp.StatusCode, Status: resp.Status, Err: fmt.Errorf("webhook failed validation: %w", err)}
 		}
 	}
 
@@ -107,5 +107,24 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	}
 
 	ns.log.Debug("Webhook failed", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-	return fmt.Errorf("webhook response status %v", resp.Status)
+	return WebhookResponseError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
+
+// WebhookResponseError is returned when the webhook responds with a status code that is not 2xx, or with a response
+// that fails the validation of the webhook, in which case Err is the validation error.
+type WebhookResponseError struct {
+	StatusCode int
+	Status     string
+	Err        error
+}
+
+func (e WebhookResponseError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("webhook response status %v", e.Status)
+}
+
+func (e WebhookResponseError) Unwrap() error {
+	return e.Err
 }
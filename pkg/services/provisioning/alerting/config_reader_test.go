@@ -3,8 +3,10 @@ package alerting
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -32,6 +34,8 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_mw        = "./testdata/maintenance_windows/correct-properties"
+	testFileMissingUID_mw               = "./testdata/maintenance_windows/missing-uid"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -158,4 +162,23 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a maintenance windows file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_mw)
+		require.NoError(t, err)
+		require.Len(t, file[0].MaintenanceWindows, 2)
+		oneOff := file[0].MaintenanceWindows[0]
+		require.Equal(t, int64(1), oneOff.OrgID)
+		require.Equal(t, "deploy", oneOff.MaintenanceWindow.UID)
+		require.Equal(t, models.MaintenanceModeSkipEvaluation, oneOff.MaintenanceWindow.Mode)
+		require.Equal(t, time.Date(2022, 10, 13, 20, 0, 0, 0, time.UTC), *oneOff.MaintenanceWindow.StartsAt)
+		recurring := file[0].MaintenanceWindows[1]
+		require.Equal(t, int64(1337), recurring.OrgID)
+		require.Len(t, recurring.MaintenanceWindow.TimeIntervals, 1)
+		require.Len(t, recurring.MaintenanceWindow.Matchers, 1)
+		require.Equal(t, []DeleteMaintenanceWindow{{OrgID: 1, UID: "old"}}, file[0].DeleteMaintenanceWindows)
+	})
+	t.Run("a maintenance windows file without uid should error", func(t *testing.T) {
+		_, err := configReader.readConfig(ctx, testFileMissingUID_mw)
+		require.Error(t, err)
+	})
 }
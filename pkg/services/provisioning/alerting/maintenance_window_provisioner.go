@@ -0,0 +1,66 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type MaintenanceWindowProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultMaintenanceWindowProvisioner struct {
+	logger                   log.Logger
+	maintenanceWindowService provisioning.MaintenanceWindowService
+}
+
+func NewMaintenanceWindowProvisioner(logger log.Logger,
+	maintenanceWindowService provisioning.MaintenanceWindowService) MaintenanceWindowProvisioner {
+	return &defaultMaintenanceWindowProvisioner{
+		logger:                   logger,
+		maintenanceWindowService: maintenanceWindowService,
+	}
+}
+
+func (c *defaultMaintenanceWindowProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, window := range file.MaintenanceWindows {
+			window.MaintenanceWindow.Provenance = models.ProvenanceFile
+			_, err := c.maintenanceWindowService.GetMaintenanceWindow(ctx, window.OrgID, window.MaintenanceWindow.UID)
+			if err == nil {
+				_, err = c.maintenanceWindowService.UpdateMaintenanceWindow(ctx, window.OrgID, window.MaintenanceWindow)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if !errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+				return err
+			}
+			_, err = c.maintenanceWindowService.CreateMaintenanceWindow(ctx, window.OrgID, window.MaintenanceWindow)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *defaultMaintenanceWindowProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteWindow := range file.DeleteMaintenanceWindows {
+			err := c.maintenanceWindowService.DeleteMaintenanceWindow(ctx, deleteWindow.OrgID, deleteWindow.UID, models.ProvenanceFile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
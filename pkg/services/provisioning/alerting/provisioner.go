@@ -18,6 +18,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	MaintenanceWindowService   provisioning.MaintenanceWindowService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -53,6 +54,11 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	mwProvisioner := NewMaintenanceWindowProvisioner(logger, cfg.MaintenanceWindowService)
+	err = mwProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("maintenance windows: %w", err)
+	}
 	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
 	err = npProvisioner.Provision(ctx, files)
 	if err != nil {
@@ -74,6 +80,10 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	err = mwProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("maintenance windows: %w", err)
+	}
 	logger.Info("finished to provision alerting")
 	return nil
 }
@@ -67,17 +67,32 @@ type AlertRuleGroup struct {
 }
 
 type AlertRuleV1 struct {
-	UID          values.StringValue    `json:"uid" yaml:"uid"`
-	Title        values.StringValue    `json:"title" yaml:"title"`
-	Condition    values.StringValue    `json:"condition" yaml:"condition"`
-	Data         []QueryV1             `json:"data" yaml:"data"`
-	DashboardUID values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
-	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
-	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
-	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
-	For          values.StringValue    `json:"for" yaml:"for"`
-	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
-	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+	UID          values.StringValue     `json:"uid" yaml:"uid"`
+	Title        values.StringValue     `json:"title" yaml:"title"`
+	Condition    values.StringValue     `json:"condition" yaml:"condition"`
+	Data         []QueryV1              `json:"data" yaml:"data"`
+	DashboardUID values.StringValue     `json:"dasboardUid" yaml:"dashboardUid"`
+	PanelID      values.Int64Value      `json:"panelId" yaml:"panelId"`
+	NoDataState  values.StringValue     `json:"noDataState" yaml:"noDataState"`
+	ExecErrState values.StringValue     `json:"execErrState" yaml:"execErrState"`
+	For          values.StringValue     `json:"for" yaml:"for"`
+	Annotations  values.StringMapValue  `json:"annotations" yaml:"annotations"`
+	Labels       values.StringMapValue  `json:"labels" yaml:"labels"`
+	IsPaused     values.BoolValue       `json:"isPaused" yaml:"isPaused"`
+	DependsOn    *AlertRuleDependencyV1 `json:"dependsOn" yaml:"dependsOn"`
+}
+
+type AlertRuleDependencyV1 struct {
+	UID      values.StringValue   `json:"uid" yaml:"uid"`
+	Matchers []values.StringValue `json:"matchers" yaml:"matchers"`
+}
+
+func (dependency *AlertRuleDependencyV1) mapToModel() *models.AlertRuleDependency {
+	result := &models.AlertRuleDependency{UID: dependency.UID.Value()}
+	for _, matcher := range dependency.Matchers {
+		result.Matchers = append(result.Matchers, matcher.Value())
+	}
+	return result
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -124,6 +139,10 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
+	alertRule.IsPaused = rule.IsPaused.Value()
+	if rule.DependsOn != nil {
+		alertRule.DependsOn = rule.DependsOn.mapToModel()
+	}
 	for _, queryV1 := range rule.Data {
 		query, err := queryV1.mapToModel()
 		if err != nil {
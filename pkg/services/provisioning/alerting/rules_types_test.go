@@ -165,6 +165,32 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a rule with out isPaused should not be paused", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.False(t, ruleMapped.IsPaused)
+	})
+	t.Run("a rule with isPaused should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		isPaused := values.BoolValue{}
+		err := yaml.Unmarshal([]byte("true"), &isPaused)
+		require.NoError(t, err)
+		rule.IsPaused = isPaused
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.True(t, ruleMapped.IsPaused)
+	})
+	t.Run("a rule with dependsOn should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		dependsOn := AlertRuleDependencyV1{}
+		err := yaml.Unmarshal([]byte("uid: database_down\nmatchers: ['cluster=\"eu\"']"), &dependsOn)
+		require.NoError(t, err)
+		rule.DependsOn = &dependsOn
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, &models.AlertRuleDependency{UID: "database_down", Matchers: []string{`cluster="eu"`}}, ruleMapped.DependsOn)
+	})
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
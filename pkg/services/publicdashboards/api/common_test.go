@@ -138,6 +138,7 @@ func buildQueryDataService(t *testing.T, cs datasources.CacheService, fpc *fakeP
 		&fakeDatasources.FakeDataSourceService{},
 		fpc,
 		&fakeOAuthTokenService{},
+		nil,
 	)
 }
 
@@ -0,0 +1,234 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	// CacheHeader is the header of the responses of the queries to the datasources with query caching enabled. It is
+	// HIT if the results of all the queries were cached, and MISS otherwise.
+	CacheHeader = "X-Grafana-Query-Cache"
+
+	cacheHit  = "HIT"
+	cacheMiss = "MISS"
+
+	// queryCachingEnabledKey and queryCachingTTLKey are the settings of the JSON data of a datasource enabling query
+	// caching, and setting for how long the results are cached, in seconds.
+	queryCachingEnabledKey = "queryCachingEnabled"
+	queryCachingTTLKey     = "queryCachingTTL"
+
+	defaultQueryCachingTTL = time.Minute
+
+	queryCacheKeyPrefix = "query-cache-"
+)
+
+// volatileQueryKeys are the keys of the model of a query which are unique to a request, and are ignored by the
+// cache key.
+var volatileQueryKeys = []string{"key", "requestId", "datasource", "datasourceId"}
+
+var queryCacheRequestsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "query_cache",
+		Name:      "requests_total",
+		Help:      "A counter for the requests to datasources with query caching enabled, by datasource type and result (hit or miss)",
+	},
+	[]string{"datasource_type", "result"},
+)
+
+// CacheStatus records whether the results of the queries of a request were cached.
+type CacheStatus struct {
+	hits   int32
+	misses int32
+}
+
+type cacheStatusKey struct{}
+
+// WithCacheStatus returns a context recording whether the results of the queries run with it were cached.
+func WithCacheStatus(ctx context.Context) (context.Context, *CacheStatus) {
+	status := &CacheStatus{}
+	return context.WithValue(ctx, cacheStatusKey{}, status), status
+}
+
+// Header returns the value of the cache header, which is empty if no queried datasource has query caching enabled.
+func (s *CacheStatus) Header() string {
+	hits, misses := atomic.LoadInt32(&s.hits), atomic.LoadInt32(&s.misses)
+	switch {
+	case misses > 0:
+		return cacheMiss
+	case hits > 0:
+		return cacheHit
+	default:
+		return ""
+	}
+}
+
+func recordCacheResult(ctx context.Context, dsType string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	queryCacheRequestsCounter.WithLabelValues(dsType, result).Inc()
+
+	status, ok := ctx.Value(cacheStatusKey{}).(*CacheStatus)
+	if !ok {
+		return
+	}
+	if hit {
+		atomic.AddInt32(&status.hits, 1)
+	} else {
+		atomic.AddInt32(&status.misses, 1)
+	}
+}
+
+// queryCachingTTL returns for how long the results of the queries to a datasource are cached, and false if query
+// caching is not enabled for the datasource.
+func queryCachingTTL(ds *datasources.DataSource) (time.Duration, bool) {
+	if ds.JsonData == nil || !ds.JsonData.Get(queryCachingEnabledKey).MustBool() {
+		return 0, false
+	}
+
+	ttl := time.Duration(ds.JsonData.Get(queryCachingTTLKey).MustInt64(0)) * time.Second
+	if ttl <= 0 {
+		ttl = defaultQueryCachingTTL
+	}
+	return ttl, true
+}
+
+type cacheKeyQuery struct {
+	RefID         string                 `json:"refId"`
+	QueryType     string                 `json:"queryType"`
+	MaxDataPoints int64                  `json:"maxDataPoints"`
+	Interval      time.Duration          `json:"interval"`
+	From          int64                  `json:"from"`
+	To            int64                  `json:"to"`
+	Duration      time.Duration          `json:"duration"`
+	Model         map[string]interface{} `json:"model"`
+}
+
+// isRelativeTime returns whether a time of the time range of a request is relative to now, such as now-1h or 1h.
+func isRelativeTime(t string) bool {
+	if strings.HasPrefix(t, "now") {
+		return true
+	}
+	// milliseconds since Unix epoch
+	if _, err := strconv.ParseInt(t, 10, 64); err == nil {
+		return false
+	}
+	_, err := time.ParseDuration("-" + t)
+	return err == nil
+}
+
+// queryCacheKey returns the key of the results of the queries to a datasource. The queries are normalized so that the
+// same queries of a dashboard viewed by several users share the results. If the time range is relative to now, its
+// end is truncated to the TTL and its duration is part of the key, so that the results are shared for the TTL.
+// Otherwise, the time range is part of the key.
+func queryCacheKey(ds *datasources.DataSource, queries []backend.DataQuery, ttl time.Duration, relativeTimeRange bool) (string, error) {
+	keyQueries := make([]cacheKeyQuery, 0, len(queries))
+	for _, q := range queries {
+		model := map[string]interface{}{}
+		if len(q.JSON) > 0 {
+			if err := json.Unmarshal(q.JSON, &model); err != nil {
+				return "", err
+			}
+		}
+		for _, key := range volatileQueryKeys {
+			delete(model, key)
+		}
+
+		keyQuery := cacheKeyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			Model:         model,
+		}
+		if relativeTimeRange {
+			keyQuery.To = q.TimeRange.To.Truncate(ttl).UnixNano()
+			keyQuery.Duration = q.TimeRange.To.Sub(q.TimeRange.From)
+		} else {
+			keyQuery.From = q.TimeRange.From.UnixNano()
+			keyQuery.To = q.TimeRange.To.UnixNano()
+		}
+		keyQueries = append(keyQueries, keyQuery)
+	}
+	sort.Slice(keyQueries, func(i, j int) bool {
+		return keyQueries[i].RefID < keyQueries[j].RefID
+	})
+
+	// The version and the update time of the datasource are part of the key, so that the results are not cached across
+	// changes of its settings.
+	key, err := json.Marshal(struct {
+		OrgID   int64           `json:"orgId"`
+		UID     string          `json:"uid"`
+		Version int             `json:"version"`
+		Updated int64           `json:"updated"`
+		Queries []cacheKeyQuery `json:"queries"`
+	}{ds.OrgId, ds.Uid, ds.Version, ds.Updated.UnixNano(), keyQueries})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(key)
+	return queryCacheKeyPrefix + hex.EncodeToString(hash[:]), nil
+}
+
+// queryDataWithCache returns the cached results of the queries of a request, or queries the datasource and caches
+// the results if none of the queries failed.
+func (s *Service) queryDataWithCache(ctx context.Context, ds *datasources.DataSource, ttl time.Duration,
+	relativeTimeRange bool, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	key, err := queryCacheKey(ds, req.Queries, ttl, relativeTimeRange)
+	if err != nil {
+		s.log.Warn("Failed to compute the query cache key", "datasource", ds.Uid, "error", err)
+		return s.pluginClient.QueryData(ctx, req)
+	}
+
+	if cached, err := s.queryCache.Get(ctx, key); err == nil {
+		if body, ok := cached.([]byte); ok {
+			resp := &backend.QueryDataResponse{}
+			err := json.Unmarshal(body, resp)
+			if err == nil {
+				recordCacheResult(ctx, ds.Type, true)
+				return resp, nil
+			}
+			s.log.Warn("Failed to read the cached query results", "datasource", ds.Uid, "error", err)
+		}
+	}
+	recordCacheResult(ctx, ds.Type, false)
+
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			return resp, nil
+		}
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		s.log.Warn("Failed to marshal the query results for the cache", "datasource", ds.Uid, "error", err)
+		return resp, nil
+	}
+	if err := s.queryCache.Set(ctx, key, body, ttl); err != nil {
+		s.log.Warn("Failed to cache the query results", "datasource", ds.Uid, "error", err)
+	}
+
+	return resp, nil
+}
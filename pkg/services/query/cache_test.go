@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+type countingPluginClient struct {
+	plugins.Client
+	calls int
+	err   error
+}
+
+func (c *countingPluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	c.calls++
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		resp.Responses[q.RefID] = backend.DataResponse{
+			Error: c.err,
+			Frames: data.Frames{data.NewFrame("series",
+				data.NewField("time", nil, []time.Time{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}),
+				data.NewField("value", nil, []float64{1})),
+			},
+		}
+	}
+	return resp, nil
+}
+
+func TestQueryDataCache(t *testing.T) {
+	setupCache := func(t *testing.T, jsonData string) (*testContext, *countingPluginClient) {
+		t.Helper()
+		tc := setup(t)
+		json, err := simplejson.NewJson([]byte(jsonData))
+		require.NoError(t, err)
+		tc.dataSourceCache.ds = &datasources.DataSource{Uid: "ds1", Type: "prometheus", JsonData: json}
+		client := &countingPluginClient{}
+		tc.queryService.pluginClient = client
+		tc.queryService.queryCache = remotecache.NewFakeStore(t)
+		return tc, client
+	}
+
+	queryData := func(t *testing.T, tc *testContext, skipCache bool, rawQuery string) (*backend.QueryDataResponse, string) {
+		t.Helper()
+		// An absolute time range, so that the queries are never in different time range buckets.
+		mr := metricRequestWithQueries(t, rawQuery)
+		mr.From, mr.To = "1640995200000", "1640998800000"
+		ctx, cacheStatus := WithCacheStatus(context.Background())
+		resp, err := tc.queryService.QueryData(ctx, tc.signedInUser, skipCache, mr)
+		require.NoError(t, err)
+		return resp, cacheStatus.Header()
+	}
+
+	t.Run("should return the cached results of the same queries", func(t *testing.T) {
+		tc, client := setupCache(t, `{"queryCachingEnabled": true, "queryCachingTTL": 300}`)
+
+		resp, status := queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up", "key": "Q-1"}`)
+		require.Equal(t, cacheMiss, status)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+
+		resp, status = queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up", "key": "Q-2"}`)
+		require.Equal(t, cacheHit, status)
+		require.Equal(t, 1, client.calls)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, 1., resp.Responses["A"].Frames[0].Fields[1].At(0))
+
+		_, status = queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "down"}`)
+		require.Equal(t, cacheMiss, status)
+		require.Equal(t, 2, client.calls)
+	})
+
+	t.Run("should not read the cache when skipping the cache", func(t *testing.T) {
+		tc, client := setupCache(t, `{"queryCachingEnabled": true}`)
+
+		queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		_, status := queryData(t, tc, true, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		require.Empty(t, status)
+		require.Equal(t, 2, client.calls)
+	})
+
+	t.Run("should not cache the results of failed queries", func(t *testing.T) {
+		tc, client := setupCache(t, `{"queryCachingEnabled": true}`)
+		client.err = context.DeadlineExceeded
+
+		queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		_, status := queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		require.Equal(t, cacheMiss, status)
+		require.Equal(t, 2, client.calls)
+	})
+
+	t.Run("should not cache the results if the user header is sent", func(t *testing.T) {
+		tc, client := setupCache(t, `{"queryCachingEnabled": true}`)
+		tc.queryService.cfg.SendUserHeader = true
+
+		queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		_, status := queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+		require.Empty(t, status)
+		require.Equal(t, 2, client.calls)
+	})
+
+	t.Run("should not cache the results of datasources without query caching", func(t *testing.T) {
+		for _, jsonData := range []string{`{}`, `{"queryCachingEnabled": true, "keepCookies": ["session"]}`} {
+			tc, client := setupCache(t, jsonData)
+
+			queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+			_, status := queryData(t, tc, false, `{"refId": "A", "datasourceId": 1, "expr": "up"}`)
+			require.Empty(t, status)
+			require.Equal(t, 2, client.calls)
+		}
+	})
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	ds := &datasources.DataSource{OrgId: 1, Uid: "ds1", Version: 1}
+	newQuery := func(model string, from, to time.Time) backend.DataQuery {
+		return backend.DataQuery{
+			RefID:     "A",
+			JSON:      []byte(model),
+			TimeRange: backend.TimeRange{From: from, To: to},
+		}
+	}
+	cacheKey := func(ds *datasources.DataSource, q backend.DataQuery, relativeTimeRange bool) string {
+		key, err := queryCacheKey(ds, []backend.DataQuery{q}, time.Hour, relativeTimeRange)
+		require.NoError(t, err)
+		return key
+	}
+	at := func(hour, min int) time.Time {
+		return time.Date(2022, 1, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	t.Run("should share the key of relative time ranges with the same duration for the TTL", func(t *testing.T) {
+		key := cacheKey(ds, newQuery(`{"expr": "up", "interval": "1m", "key": "Q-1"}`, at(9, 5), at(10, 5)), true)
+		require.Equal(t, key, cacheKey(ds, newQuery(`{"interval": "1m", "expr": "up", "key": "Q-2"}`, at(9, 50), at(10, 50)), true))
+		require.NotEqual(t, key, cacheKey(ds, newQuery(`{"expr": "up", "interval": "1m"}`, at(10, 5), at(11, 5)), true))
+		require.NotEqual(t, key, cacheKey(ds, newQuery(`{"expr": "up", "interval": "1m"}`, at(9, 35), at(10, 5)), true))
+		require.NotEqual(t, key, cacheKey(ds, newQuery(`{"expr": "down", "interval": "1m"}`, at(9, 5), at(10, 5)), true))
+	})
+
+	t.Run("should not share the key of different absolute time ranges", func(t *testing.T) {
+		key := cacheKey(ds, newQuery(`{"expr": "up"}`, at(10, 5), at(10, 20)), false)
+		require.Equal(t, key, cacheKey(ds, newQuery(`{"expr": "up"}`, at(10, 5), at(10, 20)), false))
+		require.NotEqual(t, key, cacheKey(ds, newQuery(`{"expr": "up"}`, at(10, 10), at(10, 50)), false))
+		require.NotEqual(t, key, cacheKey(ds, newQuery(`{"expr": "up"}`, at(10, 10), at(10, 25)), false))
+	})
+
+	t.Run("should not share the key across changes of the datasource", func(t *testing.T) {
+		key := cacheKey(ds, newQuery(`{"expr": "up"}`, at(10, 5), at(10, 20)), false)
+		require.NotEqual(t, key, cacheKey(&datasources.DataSource{OrgId: 1, Uid: "ds1", Version: 2}, newQuery(`{"expr": "up"}`, at(10, 5), at(10, 20)), false))
+		require.NotEqual(t, key, cacheKey(&datasources.DataSource{OrgId: 1, Uid: "ds1", Version: 1, Updated: at(9, 0)}, newQuery(`{"expr": "up"}`, at(10, 5), at(10, 20)), false))
+	})
+}
+
+func TestIsRelativeTime(t *testing.T) {
+	for _, tm := range []string{"now", "now-1h", "now/d", "1h"} {
+		require.True(t, isRelativeTime(tm), tm)
+	}
+	for _, tm := range []string{"1640995200000", "0", "2022-01-01"} {
+		require.False(t, isRelativeTime(tm), tm)
+	}
+}
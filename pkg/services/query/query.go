@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
@@ -36,6 +37,7 @@ func ProvideService(
 	dataSourceService datasources.DataSourceService,
 	pluginClient plugins.Client,
 	oAuthTokenService oauthtoken.OAuthTokenService,
+	remoteCache *remotecache.RemoteCache,
 ) *Service {
 	g := &Service{
 		cfg:                    cfg,
@@ -47,6 +49,10 @@ func ProvideService(
 		oAuthTokenService:      oAuthTokenService,
 		log:                    log.New("query_data"),
 	}
+	// The results of the queries are only cached if a remote cache is available.
+	if remoteCache != nil {
+		g.queryCache = remoteCache
+	}
 	g.log.Info("Query Service initialization")
 	return g
 }
@@ -59,6 +65,7 @@ type Service struct {
 	dataSourceService      datasources.DataSourceService
 	pluginClient           plugins.Client
 	oAuthTokenService      oauthtoken.OAuthTokenService
+	queryCache             remotecache.CacheStorage
 	log                    log.Logger
 }
 
@@ -125,6 +132,7 @@ func (s *Service) QueryData(ctx context.Context, user *user.SignedInUser, skipCa
 func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
 		OrgId:   user.OrgID,
+		Debug:   parsedReq.debug,
 		Queries: []expr.Query{},
 	}
 
@@ -221,9 +229,20 @@ func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.Si
 
 	ctx = httpclient.WithContextualMiddleware(ctx, middlewares...)
 
+	if ttl, ok := queryCachingTTL(ds); ok && s.canCacheQueries(ds, parsedReq) {
+		return s.queryDataWithCache(ctx, ds, ttl, parsedReq.relativeTimeRange, req)
+	}
+
 	return s.pluginClient.QueryData(ctx, req)
 }
 
+// canCacheQueries returns whether the results of the queries to a datasource can be cached. They are not cached if
+// they can depend on the identity of the user, as they are shared between users.
+func (s *Service) canCacheQueries(ds *datasources.DataSource, parsedReq *parsedRequest) bool {
+	return s.queryCache != nil && !parsedReq.skipCache && !s.cfg.SendUserHeader &&
+		!s.oAuthTokenService.IsOAuthPassThruEnabled(ds) && len(ds.AllowedCookies()) == 0
+}
+
 type parsedQuery struct {
 	datasource *datasources.DataSource
 	query      backend.DataQuery
@@ -232,8 +251,14 @@ type parsedQuery struct {
 
 type parsedRequest struct {
 	hasExpression bool
-	parsedQueries map[string][]parsedQuery
-	httpRequest   *http.Request
+	// debug is set when the expressions must return the results of every node and the execution trace.
+	debug bool
+	// skipCache is set when the results of the queries must not be cached, as requested by the X-Grafana-NoCache header.
+	skipCache bool
+	// relativeTimeRange is set when the time range of the queries is relative to now.
+	relativeTimeRange bool
+	parsedQueries     map[string][]parsedQuery
+	httpRequest       *http.Request
 }
 
 func (pr parsedRequest) getFlattenedQueries() []parsedQuery {
@@ -252,8 +277,11 @@ func (s *Service) parseMetricRequest(ctx context.Context, user *user.SignedInUse
 
 	timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
 	req := &parsedRequest{
-		hasExpression: false,
-		parsedQueries: make(map[string][]parsedQuery),
+		hasExpression:     false,
+		debug:             reqDTO.Debug,
+		skipCache:         skipCache,
+		relativeTimeRange: isRelativeTime(reqDTO.From) && isRelativeTime(reqDTO.To),
+		parsedQueries:     make(map[string][]parsedQuery),
 	}
 
 	// Parse the queries and store them by datasource
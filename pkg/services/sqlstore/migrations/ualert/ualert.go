@@ -568,7 +568,31 @@ func (m *rmMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		return err
 	}
 
-	exists, err := sess.IsTableExist("kv_store")
+	exists, err := sess.IsTableExist("alert_state_history")
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		_, err = sess.Exec("delete from alert_state_history")
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = sess.IsTableExist("alert_maintenance_window")
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		_, err = sess.Exec("delete from alert_maintenance_window")
+		if err != nil {
+			return err
+		}
+	}
+
+	exists, err = sess.IsTableExist("kv_store")
 	if err != nil {
 		return err
 	}
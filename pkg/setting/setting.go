@@ -436,6 +436,12 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// ExpressionsMaxConcurrentQueries is the maximum number of data source queries of
+	// an expression request that are executed at the same time. 0 means no limit.
+	ExpressionsMaxConcurrentQueries int
+	// ExpressionsQueryTimeout is the timeout of each data source query of an expression request.
+	// 0 means no timeout.
+	ExpressionsQueryTimeout time.Duration
 
 	ImageUploadProvider string
 
@@ -660,6 +666,8 @@ func (cfg *Cfg) readAnnotationSettings() error {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.ExpressionsMaxConcurrentQueries = expressions.Key("max_concurrent_queries").MustInt(10)
+	cfg.ExpressionsQueryTimeout = expressions.Key("query_timeout").MustDuration(0)
 }
 
 type AnnotationCleanupSettings struct {
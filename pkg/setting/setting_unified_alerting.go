@@ -52,6 +52,8 @@ const (
 	screenshotsDefaultCapture               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	recordingRulesDefaultTimeout            = 10 * time.Second
+	stateHistoryDefaultSQLRetention         = 30 * 24 * time.Hour
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -60,6 +62,25 @@ const (
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 )
 
+const (
+	// StateHistoryBackendAnnotations records the state history as annotations. It cannot be queried with the state history API.
+	StateHistoryBackendAnnotations = "annotations"
+	// StateHistoryBackendSQL records the state history in a table of the Grafana database.
+	StateHistoryBackendSQL = "sql"
+	// StateHistoryBackendLoki pushes the state history to Loki.
+	StateHistoryBackendLoki = "loki"
+)
+
+const (
+	// EvaluationJitterNone evaluates all the rules whose interval elapsed on the same tick of the scheduler.
+	EvaluationJitterNone = "none"
+	// EvaluationJitterGroup spreads the evaluations of the rule groups across their interval. The rules of a group
+	// are evaluated on the same tick.
+	EvaluationJitterGroup = "group"
+	// EvaluationJitterRule spreads the evaluations of the rules across their interval.
+	EvaluationJitterRule = "rule"
+)
+
 type UnifiedAlertingSettings struct {
 	AdminConfigPollInterval        time.Duration
 	AlertmanagerConfigPollInterval time.Duration
@@ -76,6 +97,10 @@ type UnifiedAlertingSettings struct {
 	DefaultConfiguration           string
 	Enabled                        *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
 	DisabledOrgs                   map[int64]struct{}
+	// EvaluationJitter is one of EvaluationJitterNone, EvaluationJitterGroup or EvaluationJitterRule.
+	EvaluationJitter string
+	// EvaluationSharding shares the evaluation of the rule groups between the members of the HA cluster.
+	EvaluationSharding bool
 	// BaseInterval interval of time the scheduler updates the rules and evaluates rules.
 	// Only for internal use and not user configuration.
 	BaseInterval time.Duration
@@ -83,6 +108,8 @@ type UnifiedAlertingSettings struct {
 	DefaultRuleEvaluationInterval time.Duration
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
+	RecordingRules                UnifiedAlertingRecordingRuleSettings
+	StateHistory                  UnifiedAlertingStateHistorySettings
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -95,6 +122,30 @@ type UnifiedAlertingReservedLabelSettings struct {
 	DisabledLabels map[string]struct{}
 }
 
+// UnifiedAlertingRecordingRuleSettings configures the Prometheus remote write endpoint
+// the results of recording rules are written to.
+type UnifiedAlertingRecordingRuleSettings struct {
+	Enabled           bool
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+	CustomHeaders     map[string]string
+}
+
+// UnifiedAlertingStateHistorySettings configures where the state transitions of alert instances are recorded.
+type UnifiedAlertingStateHistorySettings struct {
+	Enabled bool
+	// Backend is one of StateHistoryBackendAnnotations, StateHistoryBackendSQL or StateHistoryBackendLoki.
+	Backend string
+	// SQLRetention is how long the state history is kept by the SQL backend.
+	SQLRetention          time.Duration
+	LokiURL               string
+	LokiTenantID          string
+	LokiBasicAuthUsername string
+	LokiBasicAuthPassword string
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -272,6 +323,18 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MinInterval = uaMinInterval
 
+	uaCfg.EvaluationJitter = ua.Key("evaluation_jitter").MustString(EvaluationJitterNone)
+	switch uaCfg.EvaluationJitter {
+	case EvaluationJitterNone, EvaluationJitterGroup, EvaluationJitterRule:
+	default:
+		return fmt.Errorf("invalid value %q of setting 'evaluation_jitter', expected one of %s, %s, %s",
+			uaCfg.EvaluationJitter, EvaluationJitterNone, EvaluationJitterGroup, EvaluationJitterRule)
+	}
+	uaCfg.EvaluationSharding = ua.Key("evaluation_sharding").MustBool(false)
+	if uaCfg.EvaluationSharding && len(uaCfg.HAPeers) == 0 {
+		return errors.New("setting 'evaluation_sharding' requires the HA cluster, configure 'ha_peers'")
+	}
+
 	uaCfg.DefaultRuleEvaluationInterval = DefaultRuleEvaluationInterval
 	if uaMinInterval > uaCfg.DefaultRuleEvaluationInterval {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
@@ -294,6 +357,62 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.ReservedLabels = uaCfgReservedLabels
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := UnifiedAlertingRecordingRuleSettings{
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+		CustomHeaders:     make(map[string]string),
+	}
+	// "enabled" must not fall back to [unified_alerting], otherwise enabling Grafana Alerting would also enable recording rules.
+	if hasOwnKey(recordingRules, "enabled") {
+		uaCfgRecordingRules.Enabled = recordingRules.Key("enabled").MustBool(false)
+	}
+	if uaCfgRecordingRules.Enabled && uaCfgRecordingRules.URL == "" {
+		return errors.New("setting 'url' of section 'unified_alerting.recording_rules' is required when recording rules are enabled")
+	}
+	// Header values can contain spaces, so the headers are only split on commas.
+	for _, header := range strings.Split(recordingRules.Key("custom_headers").MustString(""), ",") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid custom header %q in section 'unified_alerting.recording_rules', expected the format name:value", header)
+		}
+		uaCfgRecordingRules.CustomHeaders[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
+	stateHistory := iniFile.Section("unified_alerting.state_history")
+	uaCfgStateHistory := UnifiedAlertingStateHistorySettings{
+		Enabled:               true,
+		Backend:               stateHistory.Key("backend").MustString(StateHistoryBackendAnnotations),
+		SQLRetention:          stateHistory.Key("sql_retention").MustDuration(stateHistoryDefaultSQLRetention),
+		LokiURL:               stateHistory.Key("loki_url").MustString(""),
+		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
+		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
+		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
+	}
+	if hasOwnKey(stateHistory, "enabled") {
+		uaCfgStateHistory.Enabled = stateHistory.Key("enabled").MustBool(true)
+	}
+	switch uaCfgStateHistory.Backend {
+	case StateHistoryBackendAnnotations, StateHistoryBackendSQL:
+	case StateHistoryBackendLoki:
+		if uaCfgStateHistory.Enabled && uaCfgStateHistory.LokiURL == "" {
+			return errors.New("setting 'loki_url' of section 'unified_alerting.state_history' is required when the backend is loki")
+		}
+	default:
+		return fmt.Errorf("invalid backend %q in section 'unified_alerting.state_history', expected one of %s, %s, %s",
+			uaCfgStateHistory.Backend, StateHistoryBackendAnnotations, StateHistoryBackendSQL, StateHistoryBackendLoki)
+	}
+	if uaCfgStateHistory.SQLRetention <= 0 {
+		return errors.New("setting 'sql_retention' of section 'unified_alerting.state_history' must be greater than zero")
+	}
+	uaCfg.StateHistory = uaCfgStateHistory
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -301,3 +420,14 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
+
+// hasOwnKey returns true if the key is set in the section itself. The keys of child sections,
+// for example [unified_alerting.recording_rules], fall back to the keys of the parent section.
+func hasOwnKey(section *ini.Section, name string) bool {
+	for _, key := range section.KeyStrings() {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
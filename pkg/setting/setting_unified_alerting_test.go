@@ -266,3 +266,103 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordingRulesSettings(t *testing.T) {
+	read := func(t *testing.T, options map[string]string) (Cfg, error) {
+		t.Helper()
+		f := ini.Empty()
+		cfg := NewCfg()
+		s, err := f.NewSection("unified_alerting")
+		require.NoError(t, err)
+		_, err = s.NewKey("enabled", "true")
+		require.NoError(t, err)
+		rr, err := f.NewSection("unified_alerting.recording_rules")
+		require.NoError(t, err)
+		for k, v := range options {
+			_, err = rr.NewKey(k, v)
+			require.NoError(t, err)
+		}
+		return *cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("should be disabled if not enabled in the section", func(t *testing.T) {
+		cfg, err := read(t, nil)
+		require.NoError(t, err)
+		require.False(t, cfg.UnifiedAlerting.RecordingRules.Enabled)
+		require.Equal(t, recordingRulesDefaultTimeout, cfg.UnifiedAlerting.RecordingRules.Timeout)
+	})
+
+	t.Run("should read the settings", func(t *testing.T) {
+		cfg, err := read(t, map[string]string{
+			"enabled":        "true",
+			"url":            "http://localhost:9090/api/v1/write",
+			"timeout":        "30s",
+			"custom_headers": "X-Scope-OrgID:tenant-1, X-Custom: value",
+		})
+		require.NoError(t, err)
+		rr := cfg.UnifiedAlerting.RecordingRules
+		require.True(t, rr.Enabled)
+		require.Equal(t, "http://localhost:9090/api/v1/write", rr.URL)
+		require.Equal(t, 30*time.Second, rr.Timeout)
+		require.Equal(t, map[string]string{"X-Scope-OrgID": "tenant-1", "X-Custom": "value"}, rr.CustomHeaders)
+	})
+
+	t.Run("should fail if url is missing", func(t *testing.T) {
+		_, err := read(t, map[string]string{"enabled": "true"})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if a custom header is invalid", func(t *testing.T) {
+		_, err := read(t, map[string]string{
+			"enabled":        "true",
+			"url":            "http://localhost:9090/api/v1/write",
+			"custom_headers": "X-Scope-OrgID",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestEvaluationSchedulingSettings(t *testing.T) {
+	read := func(t *testing.T, options map[string]string) (Cfg, error) {
+		t.Helper()
+		f := ini.Empty()
+		cfg := NewCfg()
+		s, err := f.NewSection("unified_alerting")
+		require.NoError(t, err)
+		_, err = s.NewKey("enabled", "true")
+		require.NoError(t, err)
+		for k, v := range options {
+			_, err = s.NewKey(k, v)
+			require.NoError(t, err)
+		}
+		return *cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("should not jitter or shard by default", func(t *testing.T) {
+		cfg, err := read(t, nil)
+		require.NoError(t, err)
+		require.Equal(t, EvaluationJitterNone, cfg.UnifiedAlerting.EvaluationJitter)
+		require.False(t, cfg.UnifiedAlerting.EvaluationSharding)
+	})
+
+	t.Run("should read the settings", func(t *testing.T) {
+		cfg, err := read(t, map[string]string{
+			"evaluation_jitter":   "rule",
+			"evaluation_sharding": "true",
+			"ha_peers":            "hostname1:9094,hostname2:9094",
+		})
+		require.NoError(t, err)
+		require.Equal(t, EvaluationJitterRule, cfg.UnifiedAlerting.EvaluationJitter)
+		require.True(t, cfg.UnifiedAlerting.EvaluationSharding)
+	})
+
+	t.Run("should fail if the jitter is unknown", func(t *testing.T) {
+		_, err := read(t, map[string]string{"evaluation_jitter": "random"})
+		require.ErrorContains(t, err, "evaluation_jitter")
+	})
+
+	t.Run("should fail if sharding is enabled without the HA cluster", func(t *testing.T) {
+		_, err := read(t, map[string]string{"evaluation_sharding": "true"})
+		require.ErrorContains(t, err, "evaluation_sharding")
+	})
+}
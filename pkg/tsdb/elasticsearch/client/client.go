@@ -28,6 +28,8 @@ type DatasourceInfo struct {
 	Database                   string
 	ESVersion                  *semver.Version
 	TimeField                  string
+	LogMessageField            string
+	LogLevelField              string
 	Interval                   string
 	TimeInterval               string
 	MaxConcurrentShardRequests int64
@@ -35,13 +37,22 @@ type DatasourceInfo struct {
 	XPack                      bool
 }
 
+// ConfiguredFields represents the fields of the documents configured in the datasource
+type ConfiguredFields struct {
+	TimeField       string
+	LogMessageField string
+	LogLevelField   string
+}
+
 const loggerName = "tsdb.elasticsearch.client"
 
 // Client represents a client which can interact with elasticsearch api
 type Client interface {
 	GetTimeField() string
+	GetConfiguredFields() ConfiguredFields
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
+	ExecuteSQL(r *SQLRequest) (*SQLResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
 	EnableDebug()
 }
@@ -85,6 +96,14 @@ func (c *baseClientImpl) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
+	return ConfiguredFields{
+		TimeField:       c.timeField,
+		LogMessageField: c.ds.LogMessageField,
+		LogLevelField:   c.ds.LogLevelField,
+	}
+}
+
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	timeInterval := c.ds.TimeInterval
 	return intervalv2.GetIntervalFrom(queryInterval, timeInterval, 0, 5*time.Second)
@@ -101,7 +120,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -134,7 +153,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*response, error) {
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
 		return nil, err
@@ -163,7 +182,7 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 		}
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	start := time.Now()
 	defer func() {
@@ -247,6 +266,80 @@ func (c *baseClientImpl) ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearch
 	return &msr, nil
 }
 
+// ExecuteSQL executes an Elasticsearch SQL query and returns the first page of rows. The cursor of the next page, if
+// any, is closed, as the rows of a query are not paginated.
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*SQLResponse, error) {
+	c.logger.Debug("Executing SQL query", "fetch size", r.FetchSize)
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	clientRes, err := c.executeRequest(http.MethodPost, "_sql", "format=json", "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	res := clientRes.httpResponse
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	c.logger.Debug("Received SQL response", "code", res.StatusCode, "status", res.Status, "content-length", res.ContentLength)
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr SQLResponse
+	if err := json.Unmarshal(resBody, &sr); err != nil {
+		return nil, err
+	}
+	sr.Status = res.StatusCode
+
+	if c.debugEnabled {
+		bodyJSON, err := simplejson.NewFromReader(bytes.NewBuffer(resBody))
+		var data *simplejson.Json
+		if err != nil {
+			c.logger.Error("Failed to decode http response into json", "error", err)
+		} else {
+			data = bodyJSON
+		}
+
+		sr.DebugInfo = &SearchDebugInfo{
+			Request: clientRes.reqInfo,
+			Response: &SearchResponseInfo{
+				Status: res.StatusCode,
+				Data:   data,
+			},
+		}
+	}
+
+	if sr.Cursor != "" {
+		c.closeSQLCursor(sr.Cursor)
+	}
+
+	return &sr, nil
+}
+
+func (c *baseClientImpl) closeSQLCursor(cursor string) {
+	body, err := json.Marshal(map[string]string{"cursor": cursor})
+	if err != nil {
+		c.logger.Warn("Failed to encode SQL cursor", "err", err)
+		return
+	}
+	clientRes, err := c.executeRequest(http.MethodPost, "_sql/close", "", "application/json", body)
+	if err != nil {
+		c.logger.Warn("Failed to close SQL cursor", "err", err)
+		return
+	}
+	if err := clientRes.httpResponse.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "err", err)
+	}
+}
+
 func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchRequest) []*multiRequest {
 	multiRequests := []*multiRequest{}
 
@@ -110,6 +110,62 @@ func TestClient_ExecuteMultisearch(t *testing.T) {
 	})
 }
 
+func TestClient_ExecuteSQL(t *testing.T) {
+	var requests []*http.Request
+	var requestBodies []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requestBodies = append(requestBodies, string(buf))
+
+		rw.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/_sql/close" {
+			_, err = rw.Write([]byte(`{"succeeded": true}`))
+			require.NoError(t, err)
+			return
+		}
+		_, err = rw.Write([]byte(`{
+			"columns": [{ "name": "host", "type": "keyword" }],
+			"rows": [["server-1"]],
+			"cursor": "sDXF1ZXJ5QW5kRmV0Y2gBAAAAAAAAAAEWYUpOYklQMHhRUEtld3RsNnFtYU1hQQ=="
+		}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	version, err := semver.NewVersion("8.0.0")
+	require.NoError(t, err)
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "metrics",
+		ESVersion:  version,
+		TimeField:  "@timestamp",
+	}
+	c, err := NewClient(context.Background(), &ds, backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()})
+	require.NoError(t, err)
+
+	res, err := c.ExecuteSQL(&SQLRequest{Query: "SELECT host FROM metrics", FetchSize: 1})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "/_sql", requests[0].URL.Path)
+	assert.Equal(t, "format=json", requests[0].URL.RawQuery)
+	assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"query": "SELECT host FROM metrics", "fetch_size": 1}`, requestBodies[0])
+
+	// the cursor of the next page is closed
+	assert.Equal(t, "/_sql/close", requests[1].URL.Path)
+	assert.JSONEq(t, `{"cursor": "sDXF1ZXJ5QW5kRmV0Y2gBAAAAAAAAAAEWYUpOYklQMHhRUEtld3RsNnFtYU1hQQ=="}`, requestBodies[1])
+
+	assert.Equal(t, 200, res.Status)
+	assert.Equal(t, []SQLColumn{{Name: "host", Type: "keyword"}}, res.Columns)
+	assert.Equal(t, [][]interface{}{{"server-1"}}, res.Rows)
+}
+
 func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, error) {
 	t.Helper()
 
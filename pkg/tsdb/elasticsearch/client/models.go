@@ -34,7 +34,7 @@ type SearchRequest struct {
 	Index       string
 	Interval    intervalv2.Interval
 	Size        int
-	Sort        map[string]interface{}
+	Sort        []map[string]interface{}
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
@@ -62,6 +62,20 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
+// SortOrder represents the order of a sort
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+// HighlightPreTagsString and HighlightPostTagsString surround the matches of the query in the highlighted fields
+const (
+	HighlightPreTagsString  = "@HIGHLIGHT@"
+	HighlightPostTagsString = "@/HIGHLIGHT@"
+)
+
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
 	Hits []map[string]interface{}
@@ -86,6 +100,28 @@ type MultiSearchResponse struct {
 	DebugInfo *SearchDebugInfo  `json:"-"`
 }
 
+// SQLRequest represents an Elasticsearch SQL request
+type SQLRequest struct {
+	Query     string `json:"query"`
+	FetchSize int    `json:"fetch_size,omitempty"`
+}
+
+// SQLColumn represents a column of an Elasticsearch SQL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents an Elasticsearch SQL response in the JSON format
+type SQLResponse struct {
+	Status    int                    `json:"status,omitempty"`
+	Error     map[string]interface{} `json:"error"`
+	Columns   []SQLColumn            `json:"columns"`
+	Rows      [][]interface{}        `json:"rows"`
+	Cursor    string                 `json:"cursor"`
+	DebugInfo *SearchDebugInfo       `json:"-"`
+}
+
 // Query represents a query
 type Query struct {
 	Bool *BoolQuery `json:"bool"`
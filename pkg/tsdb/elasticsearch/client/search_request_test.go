@@ -60,7 +60,8 @@ func TestSearchRequest(t *testing.T) {
 			})
 
 			t.Run("Should have correct sorting", func(t *testing.T) {
-				sort, ok := sr.Sort[timeField].(map[string]string)
+				require.Len(t, sr.Sort, 1)
+				sort, ok := sr.Sort[0][timeField].(map[string]string)
 				require.True(t, ok)
 				require.Equal(t, "desc", sort["order"])
 				require.Equal(t, "boolean", sort["unmapped_type"])
@@ -88,7 +89,7 @@ func TestSearchRequest(t *testing.T) {
 				require.Nil(t, err)
 				require.Equal(t, 200, json.Get("size").MustInt(0))
 
-				sort := json.GetPath("sort", timeField)
+				sort := json.Get("sort").GetIndex(0).Get(timeField)
 				require.Equal(t, "desc", sort.Get("order").MustString())
 				require.Equal(t, "boolean", sort.Get("unmapped_type").MustString())
 
@@ -146,6 +147,42 @@ func TestSearchRequest(t *testing.T) {
 		})
 	})
 
+	t.Run("When adding sorts, highlight and search after", func(t *testing.T) {
+		b := setup()
+		b.Sort(SortOrderAsc, timeField, "boolean")
+		b.Sort(SortOrderAsc, "_doc", "")
+		b.AddHighlight()
+		b.AddSearchAfter(1609459200000)
+		b.AddSearchAfter(42)
+
+		sr, err := b.Build()
+		require.Nil(t, err)
+
+		t.Run("When marshal to JSON should generate correct json", func(t *testing.T) {
+			body, err := json.Marshal(sr)
+			require.Nil(t, err)
+			json, err := simplejson.NewJson(body)
+			require.Nil(t, err)
+
+			sort := json.Get("sort")
+			require.Len(t, sort.MustArray(), 2)
+			require.Equal(t, "asc", sort.GetIndex(0).GetPath(timeField, "order").MustString())
+			require.Equal(t, "boolean", sort.GetIndex(0).GetPath(timeField, "unmapped_type").MustString())
+			require.Equal(t, "asc", sort.GetIndex(1).GetPath("_doc", "order").MustString())
+			require.Nil(t, sort.GetIndex(1).GetPath("_doc", "unmapped_type").Interface())
+
+			highlight := json.Get("highlight")
+			require.NotNil(t, highlight.GetPath("fields", "*").Interface())
+			require.Equal(t, []string{HighlightPreTagsString}, highlight.Get("pre_tags").MustStringArray())
+			require.Equal(t, []string{HighlightPostTagsString}, highlight.Get("post_tags").MustStringArray())
+			require.Equal(t, 2147483647, highlight.Get("fragment_size").MustInt())
+
+			searchAfter := json.Get("search_after")
+			require.Equal(t, int64(1609459200000), searchAfter.GetIndex(0).MustInt64())
+			require.Equal(t, int64(42), searchAfter.GetIndex(1).MustInt64())
+		})
+	})
+
 	t.Run("and adding multiple top level aggs", func(t *testing.T) {
 		b := setup()
 		aggBuilder := b.Agg()
@@ -57,8 +57,35 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	query := newTimeSeriesQuery(client, req.Queries, s.intervalCalculator)
-	return query.execute()
+	var timeSeriesQueries, sqlQueries []backend.DataQuery
+	for _, q := range req.Queries {
+		if q.QueryType == sqlQueryType {
+			sqlQueries = append(sqlQueries, q)
+		} else {
+			timeSeriesQueries = append(timeSeriesQueries, q)
+		}
+	}
+
+	result := backend.NewQueryDataResponse()
+	if len(timeSeriesQueries) > 0 {
+		res, err := newTimeSeriesQuery(client, timeSeriesQueries, s.intervalCalculator).execute()
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range res.Responses {
+			result.Responses[refID] = r
+		}
+	}
+	if len(sqlQueries) > 0 {
+		res, err := newSQLQuery(client, sqlQueries, s.intervalCalculator).execute()
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range res.Responses {
+			result.Responses[refID] = r
+		}
+	}
+	return result, nil
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -97,6 +124,16 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, errors.New("elasticsearch time field name is required")
 		}
 
+		logMessageField, ok := jsonData["logMessageField"].(string)
+		if !ok {
+			logMessageField = ""
+		}
+
+		logLevelField, ok := jsonData["logLevelField"].(string)
+		if !ok {
+			logLevelField = ""
+		}
+
 		interval, ok := jsonData["interval"].(string)
 		if !ok {
 			interval = ""
@@ -139,6 +176,8 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			MaxConcurrentShardRequests: int64(maxConcurrentShardRequests),
 			ESVersion:                  version,
 			TimeField:                  timeField,
+			LogMessageField:            logMessageField,
+			LogLevelField:              logLevelField,
 			Interval:                   interval,
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
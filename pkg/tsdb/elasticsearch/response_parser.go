@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
 	"regexp"
 	"sort"
@@ -20,6 +21,9 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -29,16 +33,19 @@ const (
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo,
+	configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -72,6 +79,21 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 			continue
 		}
 
+		if len(target.Metrics) > 0 {
+			switch target.Metrics[0].Type {
+			case logsType:
+				var searchDebugInfo *es.SearchDebugInfo
+				if i == 0 {
+					searchDebugInfo = rp.DebugInfo
+				}
+				result.Responses[target.RefID] = rp.processLogsResponse(res, target, searchDebugInfo)
+				continue
+			case rawDataType:
+				result.Responses[target.RefID] = rp.processRawDataResponse(res, debugInfo)
+				continue
+			}
+		}
+
 		queryRes := backend.DataResponse{}
 
 		props := make(map[string]string)
@@ -503,6 +525,290 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 	return nil
 }
 
+// frameTypeLogLines is the frame type of the log lines data plane contract, which is not defined by the plugin SDK yet.
+const frameTypeLogLines data.FrameType = "log-lines"
+
+// maxFlattenDepth is the depth of the nested objects of a document flattened to fields.
+const maxFlattenDepth = 10
+
+var searchWordsRegex = regexp.MustCompile(regexp.QuoteMeta(es.HighlightPreTagsString) + `(.+?)` +
+	regexp.QuoteMeta(es.HighlightPostTagsString))
+
+// logsCustomMeta is the custom metadata of a logs frame. SearchAfter contains the sort values of the last log line,
+// which can be set as the searchAfter setting of the query to get the next page of log lines.
+type logsCustomMeta struct {
+	*es.SearchDebugInfo
+	SearchWords []string      `json:"searchWords"`
+	Limit       int           `json:"limit"`
+	SearchAfter []interface{} `json:"searchAfter,omitempty"`
+}
+
+// document represents a hit of a search, with the nested objects of the source flattened.
+type document struct {
+	id        string
+	index     string
+	time      *time.Time
+	source    map[string]interface{}
+	highlight map[string]interface{}
+	sort      []interface{}
+}
+
+// processLogsResponse returns a frame of the log lines contract. The body of a line is the log message field if it is
+// configured in the datasource, or else the whole source of the document.
+func (rp *responseParser) processLogsResponse(res *es.SearchResponse, target *Query,
+	debugInfo *es.SearchDebugInfo) backend.DataResponse {
+	docs := rp.parseDocuments(res)
+	messageField := rp.ConfiguredFields.LogMessageField
+	levelField := rp.ConfiguredFields.LogLevelField
+
+	timestamps := make([]time.Time, len(docs))
+	bodies := make([]string, len(docs))
+	severities := make([]string, len(docs))
+	ids := make([]string, len(docs))
+	labels := make([]json.RawMessage, len(docs))
+	searchWords := make(map[string]struct{})
+
+	for i, doc := range docs {
+		if doc.time != nil {
+			timestamps[i] = *doc.time
+		}
+		ids[i] = doc.id
+
+		docLabels := map[string]interface{}{"_index": doc.index}
+		for k, v := range doc.source {
+			if k != messageField && k != levelField {
+				docLabels[k] = v
+			}
+		}
+		labels[i] = marshalValue(docLabels)
+
+		if message, ok := doc.source[messageField]; ok && messageField != "" {
+			bodies[i] = valueToString(message)
+		} else {
+			bodies[i] = string(marshalValue(doc.source))
+		}
+		if levelField != "" {
+			severities[i] = valueToString(doc.source[levelField])
+		}
+
+		for _, highlights := range doc.highlight {
+			fragments, _ := highlights.([]interface{})
+			for _, fragment := range fragments {
+				for _, match := range searchWordsRegex.FindAllStringSubmatch(valueToString(fragment), -1) {
+					searchWords[match[1]] = struct{}{}
+				}
+			}
+		}
+	}
+
+	fields := []*data.Field{
+		data.NewField("timestamp", nil, timestamps),
+		data.NewField("body", nil, bodies),
+	}
+	if levelField != "" {
+		fields = append(fields, data.NewField("severity", nil, severities))
+	}
+	fields = append(fields, data.NewField("id", nil, ids), data.NewField("labels", nil, labels))
+
+	meta := &logsCustomMeta{
+		SearchDebugInfo: debugInfo,
+		SearchWords:     make([]string, 0, len(searchWords)),
+		Limit:           getIntSetting(target.Metrics[0].Settings, "limit", defaultDocumentsSize),
+	}
+	for word := range searchWords {
+		meta.SearchWords = append(meta.SearchWords, word)
+	}
+	sort.Strings(meta.SearchWords)
+	if len(docs) > 0 {
+		meta.SearchAfter = docs[len(docs)-1].sort
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{
+		Type:                   frameTypeLogLines,
+		PreferredVisualization: data.VisTypeLogs,
+		Custom:                 meta,
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// processRawDataResponse returns a table of the documents, with a column for the time field, the id and the index of
+// the documents, followed by a column for each field of the sources.
+func (rp *responseParser) processRawDataResponse(res *es.SearchResponse, debugInfo *simplejson.Json) backend.DataResponse {
+	docs := rp.parseDocuments(res)
+
+	times := make([]*time.Time, len(docs))
+	ids := make([]string, len(docs))
+	indices := make([]string, len(docs))
+	names := make(map[string]struct{})
+	for i, doc := range docs {
+		times[i] = doc.time
+		ids[i] = doc.id
+		indices[i] = doc.index
+		for k := range doc.source {
+			names[k] = struct{}{}
+		}
+	}
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	fields := []*data.Field{
+		data.NewField(rp.ConfiguredFields.TimeField, nil, times),
+		data.NewField("_id", nil, ids),
+		data.NewField("_index", nil, indices),
+	}
+	for _, name := range sortedNames {
+		values := make([]interface{}, len(docs))
+		for i, doc := range docs {
+			values[i] = doc.source[name]
+		}
+		fields = append(fields, newDocumentField(name, values))
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{
+		Custom: debugInfo,
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// parseDocuments returns the documents of the hits of a search response. The time field is read from the doc value
+// fields if it is present, or else from the source.
+func (rp *responseParser) parseDocuments(res *es.SearchResponse) []document {
+	if res.Hits == nil {
+		return nil
+	}
+
+	timeField := rp.ConfiguredFields.TimeField
+	docs := make([]document, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		doc := document{
+			source: make(map[string]interface{}),
+		}
+		doc.id, _ = hit["_id"].(string)
+		doc.index, _ = hit["_index"].(string)
+		doc.highlight, _ = hit["highlight"].(map[string]interface{})
+		doc.sort, _ = hit["sort"].([]interface{})
+		if source, ok := hit["_source"].(map[string]interface{}); ok {
+			flattenSource("", source, doc.source, maxFlattenDepth)
+		}
+
+		timeValue := doc.source[timeField]
+		if fields, ok := hit["fields"].(map[string]interface{}); ok {
+			if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+				timeValue = values[0]
+			}
+		}
+		doc.time = parseDocumentTime(timeValue)
+		delete(doc.source, timeField)
+
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// flattenSource flattens the nested objects of a source into fields named with the path of the values.
+func flattenSource(prefix string, source map[string]interface{}, target map[string]interface{}, depth int) {
+	for k, v := range source {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && depth > 1 {
+			flattenSource(key, nested, target, depth-1)
+			continue
+		}
+		target[key] = v
+	}
+}
+
+// parseDocumentTime parses the value of the time field of a document, which is either an epoch in milliseconds or a
+// date in the RFC 3339 format.
+func parseDocumentTime(value interface{}) *time.Time {
+	var t time.Time
+	switch v := value.(type) {
+	case float64:
+		t = time.UnixMilli(int64(v)).UTC()
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			t = parsed.UTC()
+		} else if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			t = time.UnixMilli(epoch).UTC()
+		} else {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &t
+}
+
+// newDocumentField returns a nullable field of the type of the values of a document field, or a JSON field if the
+// values are objects or arrays, or of different types.
+func newDocumentField(name string, values []interface{}) *data.Field {
+	var fieldType data.FieldType
+	for _, value := range values {
+		var valueType data.FieldType
+		switch value.(type) {
+		case nil:
+			continue
+		case string:
+			valueType = data.FieldTypeNullableString
+		case float64:
+			valueType = data.FieldTypeNullableFloat64
+		case bool:
+			valueType = data.FieldTypeNullableBool
+		default:
+			valueType = data.FieldTypeNullableJSON
+		}
+		if fieldType != data.FieldTypeUnknown && fieldType != valueType {
+			valueType = data.FieldTypeNullableJSON
+		}
+		fieldType = valueType
+	}
+	if fieldType == data.FieldTypeUnknown {
+		fieldType = data.FieldTypeNullableString
+	}
+
+	field := data.NewFieldFromFieldType(fieldType, len(values))
+	field.Name = name
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if fieldType == data.FieldTypeNullableJSON {
+			raw := marshalValue(value)
+			field.Set(i, &raw)
+		} else {
+			field.SetConcrete(i, value)
+		}
+	}
+	return field
+}
+
+func marshalValue(value interface{}) json.RawMessage {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return raw
+}
+
+func valueToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return string(marshalValue(v))
+	}
+}
+
 func extractDataField(name string, v interface{}) *data.Field {
 	switch v.(type) {
 	case *string:
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
@@ -1185,6 +1186,165 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestProcessLogsResponse(t *testing.T) {
+	targets := map[string]string{
+		"A": `{
+			"timeField": "@timestamp",
+			"query": "hello",
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "2" } }]
+		}`,
+	}
+	response := `{
+		"responses": [
+			{
+				"hits": {
+					"total": { "value": 2, "relation": "eq" },
+					"hits": [
+						{
+							"_id": "fdsfs",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": "2019-06-24T09:51:19.765Z",
+								"message": "hello, i am a message",
+								"level": "debug",
+								"host": { "name": "server-1", "ip": "10.0.0.1" }
+							},
+							"fields": { "@timestamp": ["2019-06-24T09:51:19.765Z"] },
+							"highlight": { "message": ["@HIGHLIGHT@hello@/HIGHLIGHT@, i am a message"] },
+							"sort": [1561369879765, 2]
+						},
+						{
+							"_id": "kdospaidopa",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": 1561369879764,
+								"message": "hello, i am also a message",
+								"level": "error",
+								"host": { "name": "server-2", "ip": "10.0.0.2" }
+							},
+							"highlight": { "message": ["@HIGHLIGHT@hello@/HIGHLIGHT@, i am @HIGHLIGHT@also@/HIGHLIGHT@ a message"] },
+							"sort": [1561369879764, 1]
+						}
+					]
+				}
+			}
+		]
+	}`
+
+	t.Run("with the message and level fields configured", func(t *testing.T) {
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		rp.ConfiguredFields.LogMessageField = "message"
+		rp.ConfiguredFields.LogLevelField = "level"
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, frameTypeLogLines, frame.Meta.Type)
+		require.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, "timestamp", frame.Fields[0].Name)
+		require.Equal(t, time.Date(2019, 6, 24, 9, 51, 19, 765000000, time.UTC), frame.Fields[0].At(0))
+		require.Equal(t, time.Date(2019, 6, 24, 9, 51, 19, 764000000, time.UTC), frame.Fields[0].At(1))
+		require.Equal(t, "body", frame.Fields[1].Name)
+		require.Equal(t, "hello, i am a message", frame.Fields[1].At(0))
+		require.Equal(t, "severity", frame.Fields[2].Name)
+		require.Equal(t, "error", frame.Fields[2].At(1))
+		require.Equal(t, "id", frame.Fields[3].Name)
+		require.Equal(t, "fdsfs", frame.Fields[3].At(0))
+		require.Equal(t, "labels", frame.Fields[4].Name)
+		require.JSONEq(t, `{"_index":"mock-index","host.ip":"10.0.0.1","host.name":"server-1"}`,
+			string(frame.Fields[4].At(0).(json.RawMessage)))
+
+		meta, ok := frame.Meta.Custom.(*logsCustomMeta)
+		require.True(t, ok)
+		require.Equal(t, []string{"also", "hello"}, meta.SearchWords)
+		require.Equal(t, 2, meta.Limit)
+		require.Equal(t, []interface{}{float64(1561369879764), float64(1)}, meta.SearchAfter)
+	})
+
+	t.Run("without configured fields", func(t *testing.T) {
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, "body", frame.Fields[1].Name)
+		require.JSONEq(t, `{"host.ip":"10.0.0.1","host.name":"server-1","level":"debug","message":"hello, i am a message"}`,
+			frame.Fields[1].At(0).(string))
+		require.Equal(t, "id", frame.Fields[2].Name)
+	})
+}
+
+func TestProcessRawDataResponse(t *testing.T) {
+	targets := map[string]string{
+		"A": `{
+			"timeField": "@timestamp",
+			"metrics": [{ "type": "raw_data", "id": "1" }]
+		}`,
+	}
+	response := `{
+		"responses": [
+			{
+				"hits": {
+					"hits": [
+						{
+							"_id": "1",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": "2019-06-24T09:51:19.765Z",
+								"status": 200,
+								"tags": ["a", "b"],
+								"request": { "method": "GET" }
+							}
+						},
+						{
+							"_id": "2",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": "2019-06-24T09:51:18.765Z",
+								"status": "failed",
+								"success": false
+							}
+						}
+					]
+				}
+			}
+		]
+	}`
+	rp, err := newResponseParserForTest(targets, response)
+	require.NoError(t, err)
+	result, err := rp.getTimeSeries()
+	require.NoError(t, err)
+
+	frames := result.Responses["A"].Frames
+	require.Len(t, frames, 1)
+	frame := frames[0]
+
+	names := make([]string, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		names = append(names, field.Name)
+		require.Equal(t, 2, field.Len())
+	}
+	require.Equal(t, []string{"@timestamp", "_id", "_index", "request.method", "status", "success", "tags"}, names)
+
+	require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+	require.Equal(t, time.Date(2019, 6, 24, 9, 51, 18, 765000000, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[3].Type())
+	require.Nil(t, frame.Fields[3].At(1))
+	// the values of the status field have different types
+	require.Equal(t, data.FieldTypeNullableJSON, frame.Fields[4].Type())
+	require.Equal(t, json.RawMessage(`"failed"`), *frame.Fields[4].At(1).(*json.RawMessage))
+	require.Equal(t, data.FieldTypeNullableBool, frame.Fields[5].Type())
+	require.Equal(t, data.FieldTypeNullableJSON, frame.Fields[6].Type())
+	require.Equal(t, json.RawMessage(`["a","b"]`), *frame.Fields[6].At(0).(*json.RawMessage))
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1216,5 +1376,5 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{TimeField: "@timestamp"}), nil
 }
@@ -0,0 +1,298 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+)
+
+// sqlQueryType is the query type of the queries written in Elasticsearch SQL.
+const sqlQueryType = "sql"
+
+// defaultSQLFetchSize is the maximum number of rows returned by a SQL query if no fetch size is set.
+const defaultSQLFetchSize = 1000
+
+var sqlMacroRegex = regexp.MustCompile(`\$([_a-zA-Z0-9]+)\(([^\)]*)\)`)
+
+type sqlQuery struct {
+	client             es.Client
+	dataQueries        []backend.DataQuery
+	intervalCalculator intervalv2.Calculator
+}
+
+var newSQLQuery = func(client es.Client, dataQueries []backend.DataQuery,
+	intervalCalculator intervalv2.Calculator) *sqlQuery {
+	return &sqlQuery{
+		client:             client,
+		dataQueries:        dataQueries,
+		intervalCalculator: intervalCalculator,
+	}
+}
+
+func (e *sqlQuery) execute() (*backend.QueryDataResponse, error) {
+	result := backend.QueryDataResponse{
+		Responses: backend.Responses{},
+	}
+
+	for _, q := range e.dataQueries {
+		res, err := e.processQuery(q)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		result.Responses[q.RefID] = res
+	}
+
+	return &result, nil
+}
+
+func (e *sqlQuery) processQuery(q backend.DataQuery) (backend.DataResponse, error) {
+	model, err := simplejson.NewJson(q.JSON)
+	if err != nil {
+		return backend.DataResponse{}, err
+	}
+
+	rawSQL := model.Get("query").MustString()
+	if strings.TrimSpace(rawSQL) == "" {
+		return backend.DataResponse{Error: errors.New("invalid query, missing SQL query")}, nil
+	}
+
+	minInterval, err := e.client.GetMinInterval(model.Get("interval").MustString(""))
+	if err != nil {
+		return backend.DataResponse{}, err
+	}
+	interval := e.intervalCalculator.Calculate(q.TimeRange, minInterval, q.MaxDataPoints)
+
+	sql, err := interpolateSQLMacros(rawSQL, q.TimeRange, interval, e.client.GetTimeField())
+	if err != nil {
+		return backend.DataResponse{Error: err}, nil
+	}
+
+	res, err := e.client.ExecuteSQL(&es.SQLRequest{
+		Query:     sql,
+		FetchSize: getIntSetting(model, "fetchSize", defaultSQLFetchSize),
+	})
+	if err != nil {
+		return backend.DataResponse{}, err
+	}
+
+	meta := &data.FrameMeta{
+		ExecutedQueryString: sql,
+	}
+	if res.DebugInfo != nil {
+		meta.Custom = simplejson.NewFromAny(res.DebugInfo)
+	}
+
+	if res.Error != nil {
+		return backend.DataResponse{
+			Error:  errors.New(getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})),
+			Frames: data.Frames{&data.Frame{Meta: meta}},
+		}, nil
+	}
+
+	frame, err := sqlResponseToFrame(q.RefID, res)
+	if err != nil {
+		return backend.DataResponse{Error: err}, nil
+	}
+	if frame.Meta != nil {
+		meta.Type = frame.Meta.Type
+	}
+	frame.Meta = meta
+	if res.Cursor != "" {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("The result is limited to the first %d rows. Set a greater fetch size to get more rows.", len(res.Rows)),
+		})
+	}
+
+	return backend.DataResponse{Frames: data.Frames{frame}}, nil
+}
+
+// interpolateSQLMacros replaces the macros of a SQL query:
+//   - $__timeFilter(column) filters the column on the time range of the query. The time field of the datasource is
+//     used if no column is set.
+//   - $__timeFrom() and $__timeTo() are the start and the end of the time range of the query.
+//   - $__timeGroup(column, interval) groups the column in buckets of the interval, which can be $__interval.
+func interpolateSQLMacros(sql string, timeRange backend.TimeRange, interval intervalv2.Interval, timeField string) (string, error) {
+	var macroError error
+
+	sql = sqlMacroRegex.ReplaceAllStringFunc(sql, func(match string) string {
+		groups := sqlMacroRegex.FindStringSubmatch(match)
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.TrimSpace(arg)
+		}
+
+		res, err := evaluateSQLMacro(groups[1], args, timeRange, interval, timeField)
+		if err != nil && macroError == nil {
+			macroError = err
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
+func evaluateSQLMacro(name string, args []string, timeRange backend.TimeRange, interval intervalv2.Interval,
+	timeField string) (string, error) {
+	column := args[0]
+	if column == "" {
+		column = `"` + timeField + `"`
+	}
+
+	switch name {
+	case "__timeFilter":
+		return fmt.Sprintf("%s BETWEEN %s AND %s", column, sqlDatetime(timeRange.From), sqlDatetime(timeRange.To)), nil
+	case "__timeFrom":
+		return sqlDatetime(timeRange.From), nil
+	case "__timeTo":
+		return sqlDatetime(timeRange.To), nil
+	case "__timeGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval", name)
+		}
+		groupInterval := interval.Value
+		if args[1] != "$__interval" {
+			var err error
+			groupInterval, err = time.ParseDuration(args[1])
+			if err != nil {
+				return "", fmt.Errorf("error parsing interval %v", args[1])
+			}
+		}
+		seconds := int64(groupInterval / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		return fmt.Sprintf("HISTOGRAM(%s, INTERVAL %d SECONDS)", column, seconds), nil
+	default:
+		return "", fmt.Errorf("unknown macro %q", name)
+	}
+}
+
+func sqlDatetime(t time.Time) string {
+	return fmt.Sprintf("CAST('%s' AS DATETIME)", t.UTC().Format("2006-01-02T15:04:05.000Z"))
+}
+
+// sqlResponseToFrame converts the columns of a SQL response to the fields of a frame. If the rows are a time series,
+// they are sorted by time, and converted to the wide format if they have a string or boolean column.
+func sqlResponseToFrame(refID string, res *es.SQLResponse) (*data.Frame, error) {
+	timeIndex := -1
+	fields := make([]*data.Field, len(res.Columns))
+	for i, column := range res.Columns {
+		fields[i] = newSQLField(column, len(res.Rows))
+		if timeIndex == -1 && fields[i].Type() == data.FieldTypeNullableTime {
+			timeIndex = i
+		}
+	}
+
+	rows := res.Rows
+	if timeIndex != -1 {
+		rows = make([][]interface{}, len(res.Rows))
+		copy(rows, res.Rows)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return parseSQLTime(rows[i][timeIndex]).Before(parseSQLTime(rows[j][timeIndex]))
+		})
+	}
+
+	for rowIdx, row := range rows {
+		for colIdx, value := range row {
+			if colIdx >= len(fields) || value == nil {
+				continue
+			}
+			if err := setSQLValue(fields[colIdx], rowIdx, value); err != nil {
+				return nil, fmt.Errorf("failed to read column %s: %w", res.Columns[colIdx].Name, err)
+			}
+		}
+	}
+
+	frame := data.NewFrame(refID, fields...)
+	if len(rows) == 0 {
+		return frame, nil
+	}
+
+	switch frame.TimeSeriesSchema().Type {
+	case data.TimeSeriesTypeWide:
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesWide}
+	case data.TimeSeriesTypeLong:
+		wideFrame, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return nil, err
+		}
+		wideFrame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesWide}
+		return wideFrame, nil
+	}
+	return frame, nil
+}
+
+// newSQLField returns a nullable field of the type of a SQL column. The values of the columns of a type which is not
+// a date, a number or a boolean are converted to strings.
+func newSQLField(column es.SQLColumn, length int) *data.Field {
+	var fieldType data.FieldType
+	switch column.Type {
+	case "date", "datetime":
+		fieldType = data.FieldTypeNullableTime
+	case "byte", "short", "integer", "long", "unsigned_long", "double", "float", "half_float", "scaled_float":
+		fieldType = data.FieldTypeNullableFloat64
+	case "boolean":
+		fieldType = data.FieldTypeNullableBool
+	default:
+		fieldType = data.FieldTypeNullableString
+	}
+
+	field := data.NewFieldFromFieldType(fieldType, length)
+	field.Name = column.Name
+	return field
+}
+
+func setSQLValue(field *data.Field, idx int, value interface{}) error {
+	switch field.Type() {
+	case data.FieldTypeNullableTime:
+		t := parseSQLTime(value)
+		if t.IsZero() {
+			return fmt.Errorf("unexpected date %v", value)
+		}
+		field.Set(idx, &t)
+	case data.FieldTypeNullableFloat64:
+		f, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected number %v", value)
+		}
+		field.Set(idx, &f)
+	case data.FieldTypeNullableBool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected boolean %v", value)
+		}
+		field.Set(idx, &b)
+	default:
+		s := valueToString(value)
+		field.Set(idx, &s)
+	}
+	return nil
+}
+
+// parseSQLTime parses a date of a SQL response, which is in the ISO 8601 format. It returns the zero time if the
+// value is not a date.
+func parseSQLTime(value interface{}) time.Time {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
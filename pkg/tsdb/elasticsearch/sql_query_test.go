@@ -0,0 +1,203 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+)
+
+func TestInterpolateSQLMacros(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC),
+	}
+	interval := intervalv2.Interval{Value: 15 * time.Second, Text: "15s"}
+
+	testCases := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			sql:      `SELECT * FROM logs WHERE $__timeFilter(created)`,
+			expected: `SELECT * FROM logs WHERE created BETWEEN CAST('2018-05-15T17:50:00.000Z' AS DATETIME) AND CAST('2018-05-15T17:55:00.000Z' AS DATETIME)`,
+		},
+		{
+			sql:      `SELECT * FROM logs WHERE $__timeFilter()`,
+			expected: `SELECT * FROM logs WHERE "@timestamp" BETWEEN CAST('2018-05-15T17:50:00.000Z' AS DATETIME) AND CAST('2018-05-15T17:55:00.000Z' AS DATETIME)`,
+		},
+		{
+			sql:      `SELECT * FROM logs WHERE created > $__timeFrom() AND created < $__timeTo()`,
+			expected: `SELECT * FROM logs WHERE created > CAST('2018-05-15T17:50:00.000Z' AS DATETIME) AND created < CAST('2018-05-15T17:55:00.000Z' AS DATETIME)`,
+		},
+		{
+			sql:      `SELECT $__timeGroup("@timestamp", $__interval) AS t, COUNT(*) FROM logs GROUP BY t`,
+			expected: `SELECT HISTOGRAM("@timestamp", INTERVAL 15 SECONDS) AS t, COUNT(*) FROM logs GROUP BY t`,
+		},
+		{
+			sql:      `SELECT $__timeGroup(created, 1h) AS t, COUNT(*) FROM logs GROUP BY t`,
+			expected: `SELECT HISTOGRAM(created, INTERVAL 3600 SECONDS) AS t, COUNT(*) FROM logs GROUP BY t`,
+		},
+	}
+	for _, tc := range testCases {
+		sql, err := interpolateSQLMacros(tc.sql, timeRange, interval, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, sql)
+	}
+
+	t.Run("should fail for invalid macros", func(t *testing.T) {
+		for _, sql := range []string{
+			`SELECT $__unknown(a) FROM logs`,
+			`SELECT $__timeGroup(created) FROM logs`,
+			`SELECT $__timeGroup(created, 1x) FROM logs`,
+		} {
+			_, err := interpolateSQLMacros(sql, timeRange, interval, "@timestamp")
+			require.Error(t, err, sql)
+		}
+	})
+}
+
+func TestSQLResponseToFrame(t *testing.T) {
+	parseResponse := func(t *testing.T, body string) *es.SQLResponse {
+		t.Helper()
+		var res es.SQLResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &res))
+		return &res
+	}
+
+	t.Run("should return a table if the rows are not a time series", func(t *testing.T) {
+		frame, err := sqlResponseToFrame("A", parseResponse(t, `{
+			"columns": [
+				{ "name": "host", "type": "keyword" },
+				{ "name": "up", "type": "boolean" },
+				{ "name": "tags", "type": "object" },
+				{ "name": "count", "type": "long" }
+			],
+			"rows": [
+				["server-1", true, {"env": "prod"}, 3],
+				[null, false, null, null]
+			]
+		}`))
+		require.NoError(t, err)
+		require.Nil(t, frame.Meta)
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		require.Equal(t, "server-1", *frame.Fields[0].At(0).(*string))
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[1].Type())
+		require.Equal(t, `{"env":"prod"}`, *frame.Fields[2].At(0).(*string))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[3].Type())
+		require.Equal(t, 3., *frame.Fields[3].At(0).(*float64))
+	})
+
+	t.Run("should sort a wide time series by time", func(t *testing.T) {
+		frame, err := sqlResponseToFrame("A", parseResponse(t, `{
+			"columns": [
+				{ "name": "t", "type": "datetime" },
+				{ "name": "count", "type": "long" }
+			],
+			"rows": [
+				["2018-05-15T17:51:00.000Z", 2],
+				["2018-05-15T17:50:00.000Z", 1]
+			]
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, string(frame.Meta.Type))
+		require.Equal(t, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, 1., *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("should convert a long time series to a wide time series", func(t *testing.T) {
+		frame, err := sqlResponseToFrame("A", parseResponse(t, `{
+			"columns": [
+				{ "name": "t", "type": "datetime" },
+				{ "name": "host", "type": "keyword" },
+				{ "name": "count", "type": "long" }
+			],
+			"rows": [
+				["2018-05-15T17:51:00.000Z", "server-1", 3],
+				["2018-05-15T17:50:00.000Z", "server-2", 2],
+				["2018-05-15T17:50:00.000Z", "server-1", 1]
+			]
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, data.FrameTypeTimeSeriesWide, string(frame.Meta.Type))
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Fields[0].Len())
+		require.Equal(t, data.Labels{"host": "server-1"}, frame.Fields[1].Labels)
+		require.Equal(t, data.Labels{"host": "server-2"}, frame.Fields[2].Labels)
+		require.Equal(t, 1., *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 3., *frame.Fields[1].At(1).(*float64))
+	})
+}
+
+func TestExecuteSQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	executeSQLQuery := func(c es.Client, body string) (*backend.QueryDataResponse, error) {
+		queries := []backend.DataQuery{
+			{
+				RefID:     "A",
+				QueryType: sqlQueryType,
+				JSON:      json.RawMessage(body),
+				TimeRange: backend.TimeRange{From: from, To: to},
+			},
+		}
+		return newSQLQuery(c, queries, intervalv2.NewCalculator()).execute()
+	}
+
+	t.Run("should send the interpolated query", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponse = &es.SQLResponse{
+			Columns: []es.SQLColumn{{Name: "host", Type: "keyword"}},
+			Rows:    [][]interface{}{{"server-1"}},
+			Cursor:  "cursor",
+		}
+		result, err := executeSQLQuery(c, `{ "query": "SELECT host FROM logs WHERE $__timeFilter()", "fetchSize": "10" }`)
+		require.NoError(t, err)
+
+		require.Len(t, c.sqlRequests, 1)
+		expected := `SELECT host FROM logs WHERE "@timestamp" BETWEEN CAST('2018-05-15T17:50:00.000Z' AS DATETIME) AND CAST('2018-05-15T17:55:00.000Z' AS DATETIME)`
+		require.Equal(t, expected, c.sqlRequests[0].Query)
+		require.Equal(t, 10, c.sqlRequests[0].FetchSize)
+
+		res := result.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, expected, res.Frames[0].Meta.ExecutedQueryString)
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("should return the error of the response", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponse = &es.SQLResponse{
+			Status: 400,
+			Error: map[string]interface{}{
+				"root_cause": []interface{}{map[string]interface{}{"reason": "Unknown index [logs]"}},
+			},
+		}
+		result, err := executeSQLQuery(c, `{ "query": "SELECT * FROM logs" }`)
+		require.NoError(t, err)
+		require.Equal(t, defaultSQLFetchSize, c.sqlRequests[0].FetchSize)
+		require.EqualError(t, result.Responses["A"].Error, "Unknown index [logs]")
+	})
+
+	t.Run("should fail if the query is invalid", func(t *testing.T) {
+		c := newFakeClient()
+		result, err := executeSQLQuery(c, `{ "query": "" }`)
+		require.NoError(t, err)
+		require.Error(t, result.Responses["A"].Error)
+
+		result, err = executeSQLQuery(c, `{ "query": "SELECT $__unknown() FROM logs" }`)
+		require.NoError(t, err)
+		require.Error(t, result.Responses["A"].Error)
+		require.Empty(t, c.sqlRequests)
+	})
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
+// defaultDocumentsSize is the number of documents returned by the logs and raw data queries if no size is set.
+const defaultDocumentsSize = 500
+
 type timeSeriesQuery struct {
 	client             es.Client
 	dataQueries        []backend.DataQuery
@@ -57,7 +60,7 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
 	return rp.getTimeSeries()
 }
 
@@ -78,8 +81,19 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
 
+	if len(q.Metrics) > 0 {
+		switch q.Metrics[0].Type {
+		case logsType:
+			processLogsQuery(q, b, e.client.GetConfiguredFields())
+			return nil
+		case rawDataType:
+			processRawDataQuery(q, b, e.client.GetConfiguredFields())
+			return nil
+		}
+	}
+
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || q.Metrics[0].Type != rawDocumentType {
 			result.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
 			}
@@ -181,6 +195,45 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// processLogsQuery builds a search of the latest documents, with the matches of the query highlighted. The
+// searchAfter setting of the query contains the sort values of the hit to search after, to get the next page.
+func processLogsQuery(q *Query, b *es.SearchRequestBuilder, configuredFields es.ConfiguredFields) {
+	metric := q.Metrics[0]
+	order := es.SortOrderDesc
+	if metric.Settings.Get("sortDirection").MustString() == string(es.SortOrderAsc) {
+		order = es.SortOrderAsc
+	}
+	b.Size(getIntSetting(metric.Settings, "limit", defaultDocumentsSize))
+	b.Sort(order, configuredFields.TimeField, "boolean")
+	b.Sort(order, "_doc", "")
+	b.AddDocValueField(configuredFields.TimeField)
+	b.AddHighlight()
+
+	for _, value := range metric.Settings.Get("searchAfter").MustArray() {
+		b.AddSearchAfter(value)
+	}
+}
+
+// processRawDataQuery builds a search of the latest documents.
+func processRawDataQuery(q *Query, b *es.SearchRequestBuilder, configuredFields es.ConfiguredFields) {
+	metric := q.Metrics[0]
+	b.Size(getIntSetting(metric.Settings, "size", defaultDocumentsSize))
+	b.SortDesc(configuredFields.TimeField, "boolean")
+	b.SortDesc("_doc", "")
+	b.AddDocValueField(configuredFields.TimeField)
+}
+
+// getIntSetting returns the value of an integer setting, which is stored as a string by the query editor.
+func getIntSetting(settings *simplejson.Json, key string, defaultValue int) int {
+	if value, err := settings.Get(key).Int(); err == nil && value > 0 {
+		return value
+	}
+	if value, err := strconv.Atoi(settings.Get(key).MustString()); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
@@ -303,6 +303,63 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			require.Equal(t, sr.Size, 1337)
 		})
 
+		t.Run("With raw data metric", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "1337" }	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 1337, sr.Size)
+			require.Len(t, sr.Sort, 2)
+			require.Equal(t, map[string]string{"order": "desc", "unmapped_type": "boolean"}, sr.Sort[0]["@timestamp"])
+			require.Equal(t, map[string]string{"order": "desc"}, sr.Sort[1]["_doc"])
+			require.Equal(t, []string{"@timestamp"}, sr.CustomProps["docvalue_fields"])
+			require.Nil(t, sr.CustomProps["highlight"])
+			require.Empty(t, sr.Aggs)
+		})
+
+		t.Run("With logs metric", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "level:error",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": "100" } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 100, sr.Size)
+			require.Len(t, sr.Sort, 2)
+			require.Equal(t, map[string]string{"order": "desc", "unmapped_type": "boolean"}, sr.Sort[0]["@timestamp"])
+			require.Equal(t, map[string]string{"order": "desc"}, sr.Sort[1]["_doc"])
+			require.Equal(t, []string{"@timestamp"}, sr.CustomProps["docvalue_fields"])
+			require.NotNil(t, sr.CustomProps["highlight"])
+			require.Nil(t, sr.CustomProps["search_after"])
+			require.Empty(t, sr.Aggs)
+			require.Equal(t, "level:error", sr.Query.Bool.Filters[1].(*es.QueryStringFilter).Query)
+		})
+
+		t.Run("With logs metric with default limit, sort direction and search after", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "sortDirection": "asc", "searchAfter": [1609459200000, 42] } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 500, sr.Size)
+			require.Equal(t, map[string]string{"order": "asc", "unmapped_type": "boolean"}, sr.Sort[0]["@timestamp"])
+			require.Equal(t, map[string]string{"order": "asc"}, sr.Sort[1]["_doc"])
+			require.Len(t, sr.CustomProps["search_after"], 2)
+		})
+
 		t.Run("With date histogram agg", func(t *testing.T) {
 			c := newFakeClient()
 			_, err := executeTsdbQuery(c, `{
@@ -1046,10 +1103,15 @@ func TestSettingsCasting(t *testing.T) {
 
 type fakeClient struct {
 	timeField           string
+	logMessageField     string
+	logLevelField       string
 	multiSearchResponse *es.MultiSearchResponse
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	sqlResponse         *es.SQLResponse
+	sqlError            error
+	sqlRequests         []*es.SQLRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1066,6 +1128,14 @@ func (c *fakeClient) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
+	return es.ConfiguredFields{
+		TimeField:       c.timeField,
+		LogMessageField: c.logMessageField,
+		LogLevelField:   c.logLevelField,
+	}
+}
+
 func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return 15 * time.Second, nil
 }
@@ -1075,6 +1145,11 @@ func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSear
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.SQLResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	return c.sqlResponse, c.sqlError
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
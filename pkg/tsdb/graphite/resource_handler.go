@@ -0,0 +1,156 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/grafana/grafana/pkg/tsdb/httpresource"
+)
+
+const versionResource = "version"
+
+// resourceMethods are the HTTP methods allowed for the resources of the Graphite API served by the datasource.
+var resourceMethods = map[string][]string{
+	"metrics/find":             {http.MethodGet, http.MethodPost},
+	"tags/autoComplete/tags":   {http.MethodGet},
+	"tags/autoComplete/values": {http.MethodGet},
+	"functions":                {http.MethodGet},
+	versionResource:            {http.MethodGet},
+}
+
+// cachedResources are the resources which only change with the version of Graphite, and are cached.
+var cachedResources = map[string]bool{
+	"functions":     true,
+	versionResource: true,
+}
+
+type versionResponse struct {
+	Version string `json:"version"`
+}
+
+// CallResource serves the metrics/find, tags/autoComplete/tags, tags/autoComplete/values and functions endpoints of
+// the Graphite API, and the version of Graphite, which is empty if Graphite does not have a version endpoint.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	resource := strings.Trim(req.Path, "/")
+	methods, ok := resourceMethods[resource]
+	if !ok {
+		return httpresource.SendError(sender, http.StatusNotFound, fmt.Sprintf("unknown resource: %s", resource))
+	}
+	if !containsMethod(methods, req.Method) {
+		return httpresource.SendError(sender, http.StatusMethodNotAllowed, fmt.Sprintf("invalid resource method: %s", req.Method))
+	}
+
+	if cachedResources[resource] {
+		if res, found := dsInfo.resourceCache.Get(resource); found {
+			return sender.Send(res.(*backend.CallResourceResponse))
+		}
+	}
+
+	res, err := s.doResourceRequest(ctx, dsInfo, req, resource)
+	if err != nil {
+		return err
+	}
+
+	// only the successful responses of Graphite are cached, and not the errors nor the empty version
+	cacheable := cachedResources[resource] && res.Status == http.StatusOK
+	if resource == versionResource && (res.Status == http.StatusOK || res.Status == http.StatusNotFound) {
+		res = toVersionResponse(res)
+	}
+	if cacheable {
+		dsInfo.resourceCache.Set(resource, res, cache.DefaultExpiration)
+	}
+
+	return sender.Send(res)
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, req *backend.CallResourceRequest,
+	resource string) (*backend.CallResourceResponse, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, resource)
+	if reqURL, err := url.Parse(req.URL); err == nil {
+		u.RawQuery = reqURL.RawQuery
+	}
+
+	var body io.Reader
+	if req.Method == http.MethodPost {
+		body = bytes.NewReader(req.Body)
+	}
+	graphiteReq, err := http.NewRequestWithContext(ctx, req.Method, u.String(), body)
+	if err != nil {
+		logger.Info("Failed to create request", "error", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if req.Method == http.MethodPost {
+		graphiteReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if contentType := req.Headers["Content-Type"]; len(contentType) > 0 {
+			graphiteReq.Header.Set("Content-Type", contentType[0])
+		}
+	}
+	httpresource.ForwardHeaders(req, graphiteReq)
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+
+	span.SetAttributes("resource", resource, attribute.Key("resource").String(resource))
+	span.SetAttributes("datasource_id", dsInfo.Id, attribute.Key("datasource_id").Int64(dsInfo.Id))
+	span.SetAttributes("org_id", req.PluginContext.OrgID, attribute.Key("org_id").Int64(req.PluginContext.OrgID))
+
+	s.tracer.Inject(ctx, graphiteReq.Header, span)
+
+	res, err := httpresource.Do(logger, dsInfo.HTTPClient, graphiteReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes("graphite.response.code", res.Status, attribute.Key("graphite.response.code").Int(res.Status))
+	return res, nil
+}
+
+// toVersionResponse converts the response of the version endpoint of Graphite to a JSON object with the version. The
+// version is empty if Graphite does not have a version endpoint, which was added in Graphite 1.1, and answers with
+// 404 Not Found.
+func toVersionResponse(res *backend.CallResourceResponse) *backend.CallResourceResponse {
+	version := ""
+	if res.Status == http.StatusOK {
+		version = strings.Trim(strings.TrimSpace(string(res.Body)), `"`)
+	}
+
+	body, _ := json.Marshal(versionResponse{Version: version})
+	return &backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.response = resp
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	var requests []*http.Request
+	var requestBodies []string
+	versionStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r)
+		requestBodies = append(requestBodies, string(body))
+
+		switch r.URL.Path {
+		case "/graphite/version":
+			w.WriteHeader(versionStatus)
+			_, _ = w.Write([]byte("1.1.10\n"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`["a", "b"]`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	setup := func() *Service {
+		requests = nil
+		requestBodies = nil
+		return &Service{
+			im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+				return datasourceInfo{
+					HTTPClient:    server.Client(),
+					URL:           server.URL + "/graphite",
+					Id:            settings.ID,
+					resourceCache: cache.New(time.Minute, time.Minute),
+				}, nil
+			}),
+			tracer: tracing.InitializeTracerForTest(),
+		}
+	}
+
+	callResource := func(t *testing.T, s *Service, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+		t.Helper()
+		req.PluginContext = backend.PluginContext{
+			OrgID:                      1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1},
+		}
+		sender := &fakeSender{}
+		require.NoError(t, s.CallResource(context.Background(), req, sender))
+		require.NotNil(t, sender.response)
+		return sender.response
+	}
+
+	t.Run("should forward the metrics find request with the auth headers", func(t *testing.T) {
+		s := setup()
+		res := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "metrics/find",
+			URL:    "metrics/find?query=apps.*&from=-1h",
+			Headers: map[string][]string{
+				"Authorization": {"Bearer token"},
+				"X-ID-Token":    {"id-token"},
+				"X-Other":       {"other"},
+			},
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, `["a", "b"]`, string(res.Body))
+		require.Equal(t, []string{"application/json"}, res.Headers["Content-Type"])
+		require.Len(t, requests, 1)
+		require.Equal(t, "/graphite/metrics/find", requests[0].URL.Path)
+		require.Equal(t, "apps.*", requests[0].URL.Query().Get("query"))
+		require.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+		require.Equal(t, "id-token", requests[0].Header.Get("X-ID-Token"))
+		require.Empty(t, requests[0].Header.Get("X-Other"))
+	})
+
+	t.Run("should forward the body of a POST request", func(t *testing.T) {
+		s := setup()
+		res := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodPost,
+			Path:   "metrics/find",
+			URL:    "metrics/find",
+			Body:   []byte("query=apps.*"),
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, http.MethodPost, requests[0].Method)
+		require.Equal(t, "application/x-www-form-urlencoded", requests[0].Header.Get("Content-Type"))
+		require.Equal(t, "query=apps.*", requestBodies[0])
+	})
+
+	t.Run("should forward the tags autocomplete requests", func(t *testing.T) {
+		s := setup()
+		for _, path := range []string{"tags/autoComplete/tags", "tags/autoComplete/values"} {
+			res := callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: path, URL: path + "?expr=name%3Dapps"})
+			require.Equal(t, http.StatusOK, res.Status)
+		}
+		require.Len(t, requests, 2)
+		require.Equal(t, "/graphite/tags/autoComplete/values", requests[1].URL.Path)
+		require.Equal(t, "name=apps", requests[1].URL.Query().Get("expr"))
+	})
+
+	t.Run("should return and cache the version", func(t *testing.T) {
+		s := setup()
+		for i := 0; i < 2; i++ {
+			res := callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "version", URL: "version"})
+			require.Equal(t, http.StatusOK, res.Status)
+			require.JSONEq(t, `{"version": "1.1.10"}`, string(res.Body))
+		}
+		require.Len(t, requests, 1)
+	})
+
+	t.Run("should return an empty version if Graphite has no version endpoint", func(t *testing.T) {
+		versionStatus = http.StatusNotFound
+		t.Cleanup(func() { versionStatus = http.StatusOK })
+		s := setup()
+		for i := 0; i < 2; i++ {
+			res := callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "version", URL: "version"})
+			require.Equal(t, http.StatusOK, res.Status)
+			require.JSONEq(t, `{"version": ""}`, string(res.Body))
+		}
+		require.Len(t, requests, 2)
+	})
+
+	t.Run("should return the errors of the version endpoint without caching them", func(t *testing.T) {
+		versionStatus = http.StatusInternalServerError
+		t.Cleanup(func() { versionStatus = http.StatusOK })
+		s := setup()
+		res := callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "version", URL: "version"})
+		require.Equal(t, http.StatusInternalServerError, res.Status)
+
+		versionStatus = http.StatusOK
+		res = callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "version", URL: "version"})
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `{"version": "1.1.10"}`, string(res.Body))
+		require.Len(t, requests, 2)
+	})
+
+	t.Run("should reject unknown resources and methods", func(t *testing.T) {
+		s := setup()
+		res := callResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "render", URL: "render"})
+		require.Equal(t, http.StatusNotFound, res.Status)
+		res = callResource(t, s, &backend.CallResourceRequest{Method: http.MethodPost, Path: "functions", URL: "functions"})
+		require.Equal(t, http.StatusMethodNotAllowed, res.Status)
+		require.Empty(t, requests)
+	})
+}
@@ -0,0 +1,68 @@
+// Package httpresource contains the helpers of the datasources whose resources are served by forwarding the requests
+// to the HTTP API of the datasource.
+package httpresource
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// forwardedHeaders are the headers of the resource request forwarded to the datasource, to support forwarded OAuth
+// identity and cookies.
+var forwardedHeaders = []string{"Authorization", "Cookie", "X-ID-Token"}
+
+// ForwardHeaders sets the forwarded headers of the resource request on the request to the datasource.
+func ForwardHeaders(req *backend.CallResourceRequest, dsReq *http.Request) {
+	for _, name := range forwardedHeaders {
+		if values := req.Headers[name]; len(values) > 0 {
+			dsReq.Header.Set(name, values[0])
+		}
+	}
+}
+
+// Do sends the request to the datasource and returns its response as the response of the resource request, with its
+// status, its content type and its body.
+func Do(logger log.Logger, client *http.Client, dsReq *http.Request) (*backend.CallResourceResponse, error) {
+	res, err := client.Do(dsReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string][]string{}
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		headers["Content-Type"] = []string{contentType}
+	}
+	return &backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: headers,
+		Body:    resBody,
+	}, nil
+}
+
+// SendError sends a JSON response with the status and the message of the error.
+func SendError(sender backend.CallResourceResponseSender, status int, message string) error {
+	body, err := json.Marshal(map[string]string{"message": message})
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
@@ -0,0 +1,60 @@
+package httpresource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.response = resp
+	return nil
+}
+
+func TestDo(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Other", "other")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "bad request"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dsReq, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	ForwardHeaders(&backend.CallResourceRequest{Headers: map[string][]string{
+		"Authorization": {"Bearer token"},
+		"Cookie":        {"session=1"},
+		"X-Other":       {"other"},
+	}}, dsReq)
+
+	res, err := Do(log.NewNopLogger(), server.Client(), dsReq)
+	require.NoError(t, err)
+
+	require.Equal(t, "Bearer token", received.Header.Get("Authorization"))
+	require.Equal(t, "session=1", received.Header.Get("Cookie"))
+	require.Empty(t, received.Header.Get("X-Other"))
+	require.Equal(t, http.StatusBadRequest, res.Status)
+	require.Equal(t, map[string][]string{"Content-Type": {"application/json"}}, res.Headers)
+	require.JSONEq(t, `{"error": "bad request"}`, string(res.Body))
+}
+
+func TestSendError(t *testing.T) {
+	sender := &fakeSender{}
+	require.NoError(t, SendError(sender, http.StatusNotFound, "unknown resource: render"))
+
+	require.Equal(t, http.StatusNotFound, sender.response.Status)
+	require.Equal(t, []string{"application/json"}, sender.response.Headers["Content-Type"])
+	require.JSONEq(t, `{"message": "unknown resource: render"}`, string(sender.response.Body))
+}
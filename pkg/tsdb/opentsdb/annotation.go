@@ -0,0 +1,86 @@
+package opentsdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// annotationQueryType is the query type of the queries of the annotations of a metric.
+const annotationQueryType = "annotation"
+
+// queryAnnotations returns the annotations of the metric of the target of an annotation query, or the global
+// annotations if the query is global, as a frame with the time, the end time and the text of the annotations.
+func (s *Service) queryAnnotations(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery) backend.DataResponse {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	target := model.Get("target").MustString()
+	if target == "" {
+		return backend.DataResponse{Error: errors.New("invalid annotation query, missing metric")}
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start: query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:   query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+		Queries: []map[string]interface{}{
+			{"aggregator": "sum", "metric": target},
+		},
+		GlobalAnnotations: true,
+	}
+
+	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	responseData, err := s.readResponse(logger, res)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	var annotations []OpenTsdbAnnotation
+	if len(responseData) > 0 {
+		annotations = responseData[0].Annotations
+		if model.Get("isGlobal").MustBool() {
+			annotations = responseData[0].GlobalAnnotations
+		}
+	}
+
+	return backend.DataResponse{Frames: data.Frames{annotationsToFrame(query.RefID, annotations)}}
+}
+
+func annotationsToFrame(refID string, annotations []OpenTsdbAnnotation) *data.Frame {
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]*time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+
+	for _, annotation := range annotations {
+		times = append(times, time.Unix(annotation.StartTime, 0).UTC())
+		var timeEnd *time.Time
+		if annotation.EndTime > 0 {
+			t := time.Unix(annotation.EndTime, 0).UTC()
+			timeEnd = &t
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, annotation.Description)
+	}
+
+	return data.NewFrame(refID,
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts))
+}
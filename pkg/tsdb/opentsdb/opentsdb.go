@@ -38,8 +38,17 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is the version of OpenTSDB set in the settings of the datasource: 1 for <=2.1, 2 for 2.2, 3 for
+	// 2.3 and 4 for 2.4.
+	TSDBVersion int
+	LookupLimit int
 }
 
+const (
+	defaultTSDBVersion = 1
+	defaultLookupLimit = 1000
+)
+
 type DsAccess string
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -54,9 +63,16 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData, err := simplejson.NewJson(settings.JSONData)
+		if err != nil {
+			jsonData = simplejson.New()
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:  client,
+			URL:         settings.URL,
+			TSDBVersion: jsonData.Get("tsdbVersion").MustInt(defaultTSDBVersion),
+			LookupLimit: jsonData.Get("lookupLimit").MustInt(defaultLookupLimit),
 		}
 
 		return model, nil
@@ -68,26 +84,39 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
-
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
+	result := backend.NewQueryDataResponse()
 	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
+		if query.QueryType == annotationQueryType {
+			result.Responses[query.RefID] = s.queryAnnotations(ctx, logger, dsInfo, query)
+			continue
+		}
+
+		metric := s.buildMetric(query, dsInfo.TSDBVersion)
+		if metric == nil || metric["metric"] == "" {
+			continue
+		}
+		if len(tsdbQuery.Queries) == 0 {
+			tsdbQuery.Start = query.TimeRange.From.UnixNano() / int64(time.Millisecond)
+			tsdbQuery.End = query.TimeRange.To.UnixNano() / int64(time.Millisecond)
+		}
 		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
 	}
 
+	// No valid metric queries, return the annotations only to save a round trip.
+	if len(tsdbQuery.Queries) == 0 {
+		return result, nil
+	}
+
 	// TODO: Don't use global variable
 	if setting.Env == setting.Dev {
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
@@ -98,11 +127,15 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	result, err := s.parseResponse(logger, res)
+	metricResult, err := s.parseResponse(logger, res)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
 	}
 
+	for refID, response := range metricResult.Responses {
+		result.Responses[refID] = response
+	}
+
 	return result, nil
 }
 
@@ -132,25 +165,8 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			logger.Warn("Failed to close response body", "err", err)
-		}
-	}()
-
-	if res.StatusCode/100 != 2 {
-		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
-	}
-
-	var responseData []OpenTsdbResponse
-	err = json.Unmarshal(body, &responseData)
+	responseData, err := s.readResponse(logger, res)
 	if err != nil {
-		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
 		return nil, err
 	}
 
@@ -180,7 +196,36 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
+// readResponse reads the results of a request to the query endpoint of OpenTSDB.
+func (s *Service) readResponse(logger log.Logger, res *http.Response) ([]OpenTsdbResponse, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	var responseData []OpenTsdbResponse
+	err = json.Unmarshal(body, &responseData)
+	if err != nil {
+		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
+		return nil, err
+	}
+
+	return responseData, nil
+}
+
+// buildMetric builds the sub query of a query. Filters are only supported since OpenTSDB 2.2, and are ignored for older
+// versions.
+func (s *Service) buildMetric(query backend.DataQuery, tsdbVersion int) map[string]interface{} {
 	metric := make(map[string]interface{})
 
 	model, err := simplejson.NewJson(query.JSON)
@@ -213,38 +258,85 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 		rateOptions := make(map[string]interface{})
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := parseRateOption(model.Get("counterMax"))
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := parseRateOption(model.Get("counterResetValue"))
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		if !counterMaxCheck && resetValue == 0 {
 			rateOptions["dropResets"] = true
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
+	// Setting filters, which replace the tags
+	if tsdbVersion >= 2 {
+		if filters := parseFilters(model.Get("filters")); len(filters) > 0 {
+			metric["filters"] = filters
+		}
+	}
+
 	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
+	if _, ok := metric["filters"]; !ok {
+		tags, tagsCheck := model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
 	}
 
-	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
 
+// parseRateOption parses a rate option, which is a number or, as set by the query editor, a string. Blank options are
+// not set.
+func parseRateOption(option *simplejson.Json) (float64, bool) {
+	if value, err := option.Float64(); err == nil {
+		return value, true
+	}
+
+	str := strings.TrimSpace(option.MustString())
+	if str == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// parseFilters parses the filters of a query, skipping the filters without tag key or filter expression.
+func parseFilters(model *simplejson.Json) []OpenTsdbFilter {
+	raw, err := model.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+
+	var filters []OpenTsdbFilter
+	if err := json.Unmarshal(raw, &filters); err != nil {
+		return nil
+	}
+
+	valid := make([]OpenTsdbFilter, 0, len(filters))
+	for _, filter := range filters {
+		if filter.Tagk == "" || filter.Filter == "" {
+			continue
+		}
+		valid = append(valid, filter)
+	}
+	return valid
+}
+
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(pluginCtx)
 	if err != nil {
@@ -2,14 +2,18 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -87,7 +91,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -109,7 +113,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -130,7 +134,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -156,7 +160,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -187,7 +191,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -222,7 +226,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 2)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -241,4 +245,146 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with string rate options", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "45",
+						"counterResetValue": ""
+					}`,
+			),
+		}
+
+		metricRateOptions := service.buildMetric(query, 2)["rateOptions"].(map[string]interface{})
+		require.Len(t, metricRateOptions, 2)
+		require.Equal(t, float64(45), metricRateOptions["counterMax"])
+	})
+
+	t.Run("Build metric should drop resets for every version of OpenTSDB", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"shouldComputeRate": true,
+						"isCounter": true
+					}`,
+			),
+		}
+
+		require.True(t, service.buildMetric(query, 2)["rateOptions"].(map[string]interface{})["dropResets"].(bool))
+		require.True(t, service.buildMetric(query, 1)["rateOptions"].(map[string]interface{})["dropResets"].(bool))
+	})
+
+	t.Run("Build metric with filters", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"tags": {
+							"env": "prod"
+						},
+						"filters": [
+							{ "type": "wildcard", "tagk": "host", "filter": "server-*", "groupBy": true },
+							{ "type": "literal_or", "tagk": "", "filter": "" }
+						]
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, 2)
+		require.Equal(t, []OpenTsdbFilter{{Type: "wildcard", Tagk: "host", Filter: "server-*", GroupBy: true}}, metric["filters"])
+		require.Nil(t, metric["tags"])
+		require.True(t, metric["explicitTags"].(bool))
+
+		metric = service.buildMetric(query, 1)
+		require.Nil(t, metric["filters"])
+		require.Equal(t, map[string]interface{}{"env": "prod"}, metric["tags"])
+	})
+}
+
+func TestQueryAnnotations(t *testing.T) {
+	var requestBodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requestBodies = append(requestBodies, string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{
+			"metric": "deploys",
+			"dps": {},
+			"annotations": [{ "description": "deploy", "startTime": 1405544146, "endTime": 1405544206 }],
+			"globalAnnotations": [{ "description": "outage", "startTime": 1405544146 }]
+		}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: server.Client(), URL: server.URL, TSDBVersion: 2, LookupLimit: 1000}, nil
+		}),
+	}
+
+	queryAnnotations := func(t *testing.T, model string) backend.DataResponse {
+		t.Helper()
+		requestBodies = nil
+		result, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "Anno",
+					QueryType: annotationQueryType,
+					JSON:      json.RawMessage(model),
+					TimeRange: backend.TimeRange{From: time.Unix(1405544000, 0), To: time.Unix(1405545000, 0)},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return result.Responses["Anno"]
+	}
+
+	t.Run("should return the annotations of the metric", func(t *testing.T) {
+		res := queryAnnotations(t, `{ "target": "deploys" }`)
+		require.NoError(t, res.Error)
+		require.Len(t, requestBodies, 1)
+		require.JSONEq(t, `{
+			"start": 1405544000000,
+			"end": 1405545000000,
+			"queries": [{ "aggregator": "sum", "metric": "deploys" }],
+			"globalAnnotations": true
+		}`, requestBodies[0])
+
+		endTime := time.Date(2014, 7, 16, 20, 56, 46, 0, time.UTC)
+		testFrame := data.NewFrame("Anno",
+			data.NewField("time", nil, []time.Time{time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC)}),
+			data.NewField("timeEnd", nil, []*time.Time{&endTime}),
+			data.NewField("text", nil, []string{"deploy"}),
+		)
+		if diff := cmp.Diff(testFrame, res.Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("should return the global annotations", func(t *testing.T) {
+		res := queryAnnotations(t, `{ "target": "deploys", "isGlobal": true }`)
+		require.NoError(t, res.Error)
+		require.Equal(t, "outage", res.Frames[0].Fields[2].At(0))
+		require.Nil(t, res.Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("should fail without metric", func(t *testing.T) {
+		res := queryAnnotations(t, `{ "target": "" }`)
+		require.Error(t, res.Error)
+		require.Empty(t, requestBodies)
+	})
 }
@@ -0,0 +1,76 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/httpresource"
+)
+
+// resourceLimitParams are the resources of the OpenTSDB API served by the datasource, with the parameter limiting the
+// number of results, which defaults to the lookup limit of the datasource.
+var resourceLimitParams = map[string]string{
+	"api/suggest":       "max",
+	"api/search/lookup": "limit",
+}
+
+// CallResource serves the suggest and the lookup endpoints of the OpenTSDB API, used to suggest metrics, tag keys and
+// tag values.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	resource := strings.Trim(req.Path, "/")
+	limitParam, ok := resourceLimitParams[resource]
+	if !ok {
+		return httpresource.SendError(sender, http.StatusNotFound, fmt.Sprintf("unknown resource: %s", resource))
+	}
+	if req.Method != http.MethodGet {
+		return httpresource.SendError(sender, http.StatusMethodNotAllowed, fmt.Sprintf("invalid resource method: %s", req.Method))
+	}
+
+	res, err := s.doResourceRequest(ctx, dsInfo, req, resource, limitParam)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(res)
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, req *backend.CallResourceRequest,
+	resource string, limitParam string) (*backend.CallResourceResponse, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, resource)
+
+	params := url.Values{}
+	if reqURL, err := url.Parse(req.URL); err == nil {
+		params = reqURL.Query()
+	}
+	if params.Get(limitParam) == "" {
+		params.Set(limitParam, strconv.Itoa(dsInfo.LookupLimit))
+	}
+	u.RawQuery = params.Encode()
+
+	tsdbReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		logger.Info("Failed to create request", "error", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpresource.ForwardHeaders(req, tsdbReq)
+
+	return httpresource.Do(logger, dsInfo.HTTPClient, tsdbReq)
+}
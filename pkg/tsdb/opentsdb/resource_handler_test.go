@@ -0,0 +1,95 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.response = resp
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["cpu.average.percent"]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: server.Client(), URL: server.URL + "/tsdb", TSDBVersion: 2, LookupLimit: 500}, nil
+		}),
+	}
+
+	callResource := func(t *testing.T, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+		t.Helper()
+		requests = nil
+		req.PluginContext = backend.PluginContext{
+			OrgID:                      1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1},
+		}
+		sender := &fakeSender{}
+		require.NoError(t, service.CallResource(context.Background(), req, sender))
+		require.NotNil(t, sender.response)
+		return sender.response
+	}
+
+	t.Run("should forward the suggest request with the lookup limit and the auth headers", func(t *testing.T) {
+		res := callResource(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=metrics&q=cpu",
+			Headers: map[string][]string{
+				"Authorization": {"Bearer token"},
+				"X-Other":       {"other"},
+			},
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, `["cpu.average.percent"]`, string(res.Body))
+		require.Equal(t, []string{"application/json"}, res.Headers["Content-Type"])
+		require.Len(t, requests, 1)
+		require.Equal(t, "/tsdb/api/suggest", requests[0].URL.Path)
+		require.Equal(t, "metrics", requests[0].URL.Query().Get("type"))
+		require.Equal(t, "cpu", requests[0].URL.Query().Get("q"))
+		require.Equal(t, "500", requests[0].URL.Query().Get("max"))
+		require.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+		require.Empty(t, requests[0].Header.Get("X-Other"))
+	})
+
+	t.Run("should forward the lookup request with its limit", func(t *testing.T) {
+		res := callResource(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/search/lookup",
+			URL:    "api/search/lookup?m=cpu%7Bhost%3D%2A%7D&limit=1000",
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, "/tsdb/api/search/lookup", requests[0].URL.Path)
+		require.Equal(t, "cpu{host=*}", requests[0].URL.Query().Get("m"))
+		require.Equal(t, "1000", requests[0].URL.Query().Get("limit"))
+	})
+
+	t.Run("should reject unknown resources and methods", func(t *testing.T) {
+		res := callResource(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "api/query", URL: "api/query"})
+		require.Equal(t, http.StatusNotFound, res.Status)
+		res = callResource(t, &backend.CallResourceRequest{Method: http.MethodPost, Path: "api/suggest", URL: "api/suggest"})
+		require.Equal(t, http.StatusMethodNotAllowed, res.Status)
+		require.Empty(t, requests)
+	})
+}